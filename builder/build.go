@@ -67,7 +67,7 @@ func getTemplate(lang string) (string, *stack.LanguageTemplate, error) {
 
 // BuildImage construct Docker image from function parameters
 // TODO: refactor signature to a struct to simplify the length of the method header
-func BuildImage(image string, handler string, functionName string, language string, nocache bool, squash bool, shrinkwrap bool, buildArgMap map[string]string, buildOptions []string, tagFormat schema.BuildFormat, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string, buildSecrets map[string]string, remoteBuilder, payloadSecretPath, builderPublicKeyPath string, forcePull bool) error {
+func BuildImage(ctx context.Context, image string, handler string, functionName string, language string, nocache bool, squash bool, shrinkwrap bool, buildArgMap map[string]string, buildOptions []string, tagFormat schema.BuildFormat, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string, buildSecrets map[string]string, remoteBuilder, payloadSecretPath, builderPublicKeyPath string, forcePull bool) error {
 
 	_, langTemplate, err := getTemplate(language)
 	if err != nil {
@@ -138,12 +138,17 @@ func BuildImage(image string, handler string, functionName string, language stri
 			return fmt.Errorf("failed to create tar file for %s, error: %w", functionName, err)
 		}
 
+		cacheKey, err := remoteBuildCacheKey(path.Join("build", functionName), buildConfig)
+		if err != nil {
+			return err
+		}
+
 		u, _ := url.Parse(remoteBuilder)
 		builderURL := &url.URL{
 			Scheme: u.Scheme,
 			Host:   u.Host,
 		}
-		if err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, builderPublicKeyPath, buildSecrets, quietBuild, functionName, imageName); err != nil {
+		if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, builderPublicKeyPath, buildSecrets, quietBuild, functionName, imageName, cacheKey, nil); err != nil {
 			return fmt.Errorf("failed to invoke builder: %w", err)
 		}
 
@@ -176,7 +181,7 @@ func BuildImage(image string, handler string, functionName string, language stri
 			Env:         envs,
 		}
 
-		res, err := task.Execute(context.TODO())
+		res, err := task.Execute(ctx)
 
 		if err != nil {
 			return err
@@ -325,6 +330,13 @@ type dockerBuild struct {
 	ForcePull bool
 
 	BuildSecrets map[string]string
+
+	// SBOM and Provenance attach attestations to images built with buildx
+	SBOM       bool
+	Provenance bool
+
+	// MetadataFile is written by buildx with the digest of the image
+	MetadataFile string
 }
 
 // pathInScope returns the absolute path to `path` and ensures that it is located within the
@@ -0,0 +1,241 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BuildCacheFile is where the content hash of each successfully built
+// function is recorded, relative to the working directory.
+const BuildCacheFile = "build/.build-cache.json"
+
+const (
+	handlerInput    = "handler"
+	templateInput   = "template"
+	configInput     = "config"
+	copyInputPrefix = "copy:"
+)
+
+// BuildCache records the inputs of the last successful build for each function
+// so that unchanged functions can be skipped.
+type BuildCache struct {
+	Functions map[string]BuildCacheEntry `json:"functions"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// BuildCacheEntry is the record of a single function's last successful build.
+type BuildCacheEntry struct {
+	// Hash combines every value in Inputs
+	Hash string `json:"hash"`
+
+	// Inputs is a hash per input to the build, i.e. the handler, the template,
+	// each copy path and the build configuration.
+	Inputs map[string]string `json:"inputs"`
+
+	Image string    `json:"image"`
+	Built time.Time `json:"built"`
+}
+
+// LoadBuildCache reads the build cache from path. A missing file results in
+// an empty cache.
+func LoadBuildCache(path string) (*BuildCache, error) {
+	cache := &BuildCache{
+		Functions: make(map[string]BuildCacheEntry),
+		path:      path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("unable to read build cache %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("unable to parse build cache %s: %w", path, err)
+	}
+
+	if cache.Functions == nil {
+		cache.Functions = make(map[string]BuildCacheEntry)
+	}
+
+	return cache, nil
+}
+
+// Save writes the build cache back to the path it was loaded from.
+func (c *BuildCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("unable to create directory for build cache: %w", err)
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// Changed compares inputs against the last successful build of the function
+// and returns whether it needs to be rebuilt, along with the reasons.
+func (c *BuildCache) Changed(name string, inputs map[string]string) (bool, []string) {
+	c.mu.Lock()
+	entry, ok := c.Functions[name]
+	c.mu.Unlock()
+
+	if !ok {
+		return true, []string{"no previous build recorded"}
+	}
+
+	var reasons []string
+	for _, key := range sortedInputKeys(inputs, entry.Inputs) {
+		current, hasCurrent := inputs[key]
+		previous, hasPrevious := entry.Inputs[key]
+
+		switch {
+		case hasCurrent && !hasPrevious:
+			reasons = append(reasons, describeInput(key)+" added")
+		case !hasCurrent && hasPrevious:
+			reasons = append(reasons, describeInput(key)+" removed")
+		case current != previous:
+			reasons = append(reasons, describeInput(key)+" changed")
+		}
+	}
+
+	return len(reasons) > 0, reasons
+}
+
+// Get returns the last successful build of the function, if there was one.
+func (c *BuildCache) Get(name string) (BuildCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.Functions[name]
+	return entry, ok
+}
+
+// Record stores the inputs of a successful build for the function.
+func (c *BuildCache) Record(name, image string, inputs map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Functions[name] = BuildCacheEntry{
+		Hash:   combineInputs(inputs),
+		Inputs: inputs,
+		Image:  image,
+		Built:  time.Now().UTC(),
+	}
+}
+
+// FunctionInputs hashes everything that goes into a function's build context:
+// the handler folder, the language template, each of the copy paths and the
+// build configuration such as build-args and build-options.
+func FunctionInputs(handler, language string, copyExtraPaths []string, config map[string]string) (map[string]string, error) {
+	inputs := make(map[string]string)
+
+	handlerHash, err := hashInputFolder(handler)
+	if err != nil {
+		return nil, fmt.Errorf("unable to hash handler %q: %w", handler, err)
+	}
+	inputs[handlerInput] = handlerHash
+
+	templatePath := filepath.Join("template", language)
+	if _, err := os.Stat(templatePath); err == nil {
+		templateHash, err := hashInputFolder(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash template %q: %w", templatePath, err)
+		}
+		inputs[templateInput] = templateHash
+	}
+
+	for _, copyPath := range copyExtraPaths {
+		copyHash, err := hashInputFolder(copyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash copy path %q: %w", copyPath, err)
+		}
+		inputs[copyInputPrefix+filepath.Clean(copyPath)] = copyHash
+	}
+
+	inputs[configInput] = hashConfig(config)
+
+	return inputs, nil
+}
+
+// hashInputFolder hashes each file under path along with its relative path
+// and mode, as the remote builder does for its cache key.
+func hashInputFolder(path string) (string, error) {
+	h := sha256.New()
+	if err := writeFileDigests(h, path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashConfig(config map[string]string) string {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, config[k])
+	}
+
+	hash := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+func combineInputs(inputs map[string]string) string {
+	return hashConfig(inputs)
+}
+
+func describeInput(key string) string {
+	switch {
+	case key == handlerInput:
+		return "handler"
+	case key == templateInput:
+		return "template"
+	case key == configInput:
+		return "build configuration"
+	case strings.HasPrefix(key, copyInputPrefix):
+		return "copy path " + strings.TrimPrefix(key, copyInputPrefix)
+	default:
+		return key
+	}
+}
+
+func sortedInputKeys(maps ...map[string]string) []string {
+	set := make(map[string]bool)
+	for _, m := range maps {
+		for k := range m {
+			set[k] = true
+		}
+	}
+
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
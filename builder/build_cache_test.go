@@ -0,0 +1,175 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_BuildCache_ChangedWithoutPreviousBuild(t *testing.T) {
+	cache, err := LoadBuildCache(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	changed, reasons := cache.Changed("api", map[string]string{handlerInput: "abc"})
+	if !changed {
+		t.Fatal("want a function without a previous build to be changed")
+	}
+
+	want := []string{"no previous build recorded"}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("want reasons %v, got %v", want, reasons)
+	}
+}
+
+func Test_BuildCache_RecordAndReload(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "build", "cache.json")
+
+	cache, err := LoadBuildCache(cachePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	inputs := map[string]string{
+		handlerInput:               "1",
+		templateInput:              "2",
+		configInput:                "3",
+		copyInputPrefix + "common": "4",
+	}
+	cache.Record("api", "ttl.sh/api:latest", inputs)
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("unexpected error saving: %s", err)
+	}
+
+	reloaded, err := LoadBuildCache(cachePath)
+	if err != nil {
+		t.Fatalf("unexpected error reloading: %s", err)
+	}
+
+	if changed, reasons := reloaded.Changed("api", inputs); changed {
+		t.Errorf("want unchanged inputs to be skipped, got reasons: %v", reasons)
+	}
+
+	updated := map[string]string{
+		handlerInput:               "1",
+		templateInput:              "20",
+		configInput:                "3",
+		copyInputPrefix + "shared": "5",
+	}
+
+	changed, reasons := reloaded.Changed("api", updated)
+	if !changed {
+		t.Fatal("want changed inputs to need a rebuild")
+	}
+
+	want := []string{"copy path common removed", "copy path shared added", "template changed"}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("want reasons %v, got %v", want, reasons)
+	}
+}
+
+func Test_FunctionInputs_DetectsHandlerAndCopyChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	handlerDir := filepath.Join(tmpDir, "api")
+	commonDir := filepath.Join(tmpDir, "common")
+	for _, dir := range []string{handlerDir, commonDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile(filepath.Join(handlerDir, "handler.go"), "package function")
+	writeFile(filepath.Join(commonDir, "util.go"), "package common")
+
+	config := map[string]string{"image": "ttl.sh/api:latest"}
+
+	before, err := FunctionInputs(handlerDir, "golang-middleware", []string{commonDir}, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	writeFile(filepath.Join(commonDir, "util.go"), "package common\n\nconst Version = 2")
+
+	after, err := FunctionInputs(handlerDir, "golang-middleware", []string{commonDir}, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if before[handlerInput] != after[handlerInput] {
+		t.Errorf("want handler hash to be stable")
+	}
+
+	key := copyInputPrefix + filepath.Clean(commonDir)
+	if before[key] == after[key] {
+		t.Errorf("want copy path hash to change after editing a file")
+	}
+
+	config["build_arg.GO111MODULE"] = "on"
+	withArg, err := FunctionInputs(handlerDir, "golang-middleware", []string{commonDir}, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if withArg[configInput] == after[configInput] {
+		t.Errorf("want config hash to change after adding a build-arg")
+	}
+}
+
+func Test_FunctionInputs_DetectsRenameAndModeChanges(t *testing.T) {
+	handlerDir := filepath.Join(t.TempDir(), "api")
+	if err := os.MkdirAll(handlerDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := filepath.Join(handlerDir, "handler.go")
+	if err := os.WriteFile(handler, []byte("package function"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := map[string]string{"image": "ttl.sh/api:latest"}
+
+	before, err := FunctionInputs(handlerDir, "golang-middleware", nil, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	renamed := filepath.Join(handlerDir, "main.go")
+	if err := os.Rename(handler, renamed); err != nil {
+		t.Fatal(err)
+	}
+
+	afterRename, err := FunctionInputs(handlerDir, "golang-middleware", nil, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if before[handlerInput] == afterRename[handlerInput] {
+		t.Errorf("want handler hash to change after renaming a file")
+	}
+
+	if err := os.Chmod(renamed, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	afterChmod, err := FunctionInputs(handlerDir, "golang-middleware", nil, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if afterRename[handlerInput] == afterChmod[handlerInput] {
+		t.Errorf("want handler hash to change after making a file executable")
+	}
+}
@@ -197,6 +197,54 @@ func Test_getDockerBuildxCommand_NoBuildSecrets(t *testing.T) {
 	}
 }
 
+func Test_getDockerBuildxCommand_Attestations(t *testing.T) {
+	dockerBuildVal := dockerBuild{
+		Image:        "imagename:latest",
+		BuildArgMap:  make(map[string]string),
+		Platforms:    "linux/amd64",
+		SBOM:         true,
+		Provenance:   true,
+		MetadataFile: "/tmp/metadata.json",
+	}
+
+	_, values := getDockerBuildxCommand(dockerBuildVal)
+
+	joined := strings.Join(values, " ")
+	for _, want := range []string{"--sbom=true", "--provenance=mode=max", "--metadata-file /tmp/metadata.json"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("want %s in %s, but didn't find it", want, joined)
+		}
+	}
+
+	dockerBuildVal.SBOM = false
+	dockerBuildVal.Provenance = false
+	_, values = getDockerBuildxCommand(dockerBuildVal)
+
+	joined = strings.Join(values, " ")
+	if strings.Contains(joined, "--sbom") || strings.Contains(joined, "--provenance") {
+		t.Errorf("did not expect attestations in %s", joined)
+	}
+}
+
+func Test_readBuildxDigest(t *testing.T) {
+	metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(metadataFile, []byte(`{"containerimage.digest":"sha256:abc","image.name":"ttl.sh/fn:latest"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := readBuildxDigest(metadataFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if digest != "sha256:abc" {
+		t.Fatalf("want digest sha256:abc, got %q", digest)
+	}
+
+	if digest, err := readBuildxDigest(filepath.Join(t.TempDir(), "missing.json")); err != nil || digest != "" {
+		t.Fatalf("want no digest and no error for a missing file, got %q %v", digest, err)
+	}
+}
+
 func Test_buildFlagSlice(t *testing.T) {
 
 	var buildFlagOpts = []struct {
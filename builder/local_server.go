@@ -0,0 +1,462 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	v2execute "github.com/alexellis/go-execute/v2"
+	"github.com/alexellis/hmac/v2"
+	sdkbuilder "github.com/openfaas/go-sdk/builder"
+	"github.com/openfaas/go-sdk/seal"
+)
+
+// localServerMaxTar is the largest build request accepted by LocalServer.
+const localServerMaxTar = 1 << 30
+
+// LocalServer is a stand-in for the Function Builder API, so that
+// --remote-builder, the payload secret and the exchange of the public key
+// for build secrets can be tested without a pro-builder.
+type LocalServer struct {
+	// PayloadSecret is used to validate the HMAC signature of each build.
+	PayloadSecret []byte
+
+	// PublicKey and PrivateKey are the keypair from "faas-cli secret keygen"
+	// used to unseal build secrets. Build secrets are rejected without them.
+	PublicKey  []byte
+	PrivateKey []byte
+
+	// Simulate streams the steps of a build without running docker buildx.
+	Simulate bool
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// ServeHTTP implements the /build, /publickey and /healthz endpoints.
+func (s *LocalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/build":
+		s.handleBuild(w, r)
+	case "/publickey", "/public-key":
+		s.handlePublicKey(w, r)
+	case "/healthz":
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *LocalServer) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(s.PublicKey) == 0 {
+		http.Error(w, "no key configured for build secrets", http.StatusNotFound)
+		return
+	}
+
+	keyID, err := seal.DeriveKeyID(s.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(remoteBuilderPublicKeyResponse{
+		KeyID:     keyID,
+		Algorithm: naclBoxAlgorithm,
+		PublicKey: strings.TrimSpace(string(s.PublicKey)),
+	})
+}
+
+func (s *LocalServer) handleBuild(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, localServerMaxTar))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read build context: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	signature := r.Header.Get("X-Build-Signature")
+	if len(signature) == 0 {
+		http.Error(w, "X-Build-Signature is required", http.StatusUnauthorized)
+		return
+	}
+	if err := hmac.Validate(body, signature, string(s.PayloadSecret)); err != nil {
+		log.Printf("[Builder] rejected build with invalid signature: %s", err)
+		http.Error(w, "invalid X-Build-Signature, check the payload secret", http.StatusUnauthorized)
+		return
+	}
+
+	workDir, err := os.MkdirTemp(os.TempDir(), "faas-cli-builder-*")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	contextDir := filepath.Join(workDir, "context")
+	config, sealed, files, err := extractBuildTar(body, contextDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(config.Image) == 0 {
+		http.Error(w, "the build config must include an image", http.StatusBadRequest)
+		return
+	}
+
+	var secrets map[string][]byte
+	if sealed != nil {
+		if len(s.PrivateKey) == 0 {
+			http.Error(w, "build secrets were sent, but no key is configured to unseal them", http.StatusBadRequest)
+			return
+		}
+
+		secrets, err = seal.Unseal(s.PrivateKey, sealed)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to unseal build secrets: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	log.Printf("[Builder] building %s from %d files with %d build secrets", config.Image, files, len(secrets))
+
+	stream := strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+	out := newBuildResultWriter(w, stream)
+
+	cacheKey := r.Header.Get(RemoteBuildCacheKeyHeader)
+	if len(cacheKey) > 0 && s.cached(cacheKey) == config.Image {
+		w.Header().Set(remoteBuildCacheHeader, "hit")
+		out.finish(sdkbuilder.BuildResult{
+			Log:    []string{"Build context unchanged, reusing " + config.Image},
+			Image:  config.Image,
+			Status: sdkbuilder.BuildSuccess,
+		})
+		return
+	}
+
+	var buildErr error
+	if s.Simulate {
+		buildErr = simulateBuild(out, config, files, secrets)
+	} else {
+		buildErr = s.runBuildx(r, out, workDir, contextDir, config, secrets)
+	}
+
+	if buildErr != nil {
+		log.Printf("[Builder] failed to build %s: %s", config.Image, buildErr)
+		out.finish(sdkbuilder.BuildResult{
+			Image:  config.Image,
+			Status: sdkbuilder.BuildFailed,
+			Error:  buildErr.Error(),
+		})
+		return
+	}
+
+	if len(cacheKey) > 0 {
+		s.record(cacheKey, config.Image)
+	}
+
+	log.Printf("[Builder] built %s", config.Image)
+	out.finish(sdkbuilder.BuildResult{
+		Image:  config.Image,
+		Status: sdkbuilder.BuildSuccess,
+	})
+}
+
+func (s *LocalServer) cached(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache[key]
+}
+
+func (s *LocalServer) record(key, image string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache == nil {
+		s.cache = make(map[string]string)
+	}
+	s.cache[key] = image
+}
+
+// runBuildx builds the context with the same docker buildx command as
+// "faas-cli publish", with each build secret written to a file.
+func (s *LocalServer) runBuildx(r *http.Request, out *buildResultWriter, workDir, contextDir string, config sdkbuilder.BuildConfig, secrets map[string][]byte) error {
+	secretPaths := make(map[string]string, len(secrets))
+	if len(secrets) > 0 {
+		secretsDir := filepath.Join(workDir, "secrets")
+		if err := os.MkdirAll(secretsDir, 0700); err != nil {
+			return err
+		}
+		for name, value := range secrets {
+			p := filepath.Join(secretsDir, filepath.Base(name))
+			if err := os.WriteFile(p, value, 0600); err != nil {
+				return err
+			}
+			secretPaths[name] = p
+		}
+	}
+
+	command, args := getDockerBuildxCommand(dockerBuild{
+		Image:        config.Image,
+		BuildArgMap:  config.BuildArgs,
+		Platforms:    strings.Join(config.Platforms, ","),
+		BuildSecrets: secretPaths,
+	})
+
+	buildArgs := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg == "--platform=":
+			continue
+		case arg == "--output=type=registry,push=true" && config.SkipPush:
+			arg = "--output=type=image,push=false"
+		}
+		buildArgs = append(buildArgs, arg)
+	}
+
+	lines := &buildLogWriter{out: out}
+	task := v2execute.ExecTask{
+		Cwd:                contextDir,
+		Command:            command,
+		Args:               buildArgs,
+		DisableStdioBuffer: true,
+		StdOutWriter:       lines,
+		StdErrWriter:       lines,
+	}
+
+	res, err := task.Execute(r.Context())
+	lines.Flush()
+	if err != nil {
+		return err
+	}
+
+	if res.ExitCode != 0 {
+		return fmt.Errorf("docker buildx exited with code: %d", res.ExitCode)
+	}
+	return nil
+}
+
+// simulateBuild reports what would have been built, so that the exchange
+// with the builder can be tested without docker.
+func simulateBuild(out *buildResultWriter, config sdkbuilder.BuildConfig, files int, secrets map[string][]byte) error {
+	steps := []string{
+		fmt.Sprintf("Received build context with %d files", files),
+	}
+
+	if len(config.BuildArgs) > 0 {
+		keys := make([]string, 0, len(config.BuildArgs))
+		for k := range config.BuildArgs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		steps = append(steps, "Build args: "+strings.Join(keys, ", "))
+	}
+
+	if len(secrets) > 0 {
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		steps = append(steps, "Unsealed build secrets: "+strings.Join(names, ", "))
+	}
+
+	platforms := "the default platform"
+	if len(config.Platforms) > 0 {
+		platforms = strings.Join(config.Platforms, ",")
+	}
+	steps = append(steps, fmt.Sprintf("Simulated build of %s for %s", config.Image, platforms))
+
+	for _, step := range steps {
+		out.progress(step)
+	}
+	return nil
+}
+
+// extractBuildTar unpacks the build context from a tar made by
+// sdkbuilder.MakeTar into contextDir, and returns the build config, the
+// sealed build secrets if there were any, and the number of files.
+func extractBuildTar(data []byte, contextDir string) (sdkbuilder.BuildConfig, []byte, int, error) {
+	var config sdkbuilder.BuildConfig
+	var sealed []byte
+	var files int
+	foundConfig := false
+
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		return config, nil, 0, err
+	}
+
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return config, nil, 0, fmt.Errorf("unable to read build tar: %w", err)
+		}
+
+		switch hdr.Name {
+		case sdkbuilder.BuilderConfigFileName:
+			if err := json.NewDecoder(tr).Decode(&config); err != nil {
+				return config, nil, 0, fmt.Errorf("unable to parse %s: %w", hdr.Name, err)
+			}
+			foundConfig = true
+			continue
+		case sdkbuilder.BuildSecretsFileName:
+			if sealed, err = io.ReadAll(tr); err != nil {
+				return config, nil, 0, err
+			}
+			continue
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		rel, ok := strings.CutPrefix(name, "context")
+		if !ok || (len(rel) > 0 && rel[0] != filepath.Separator) {
+			continue
+		}
+
+		target := filepath.Join(contextDir, rel)
+		if target != contextDir && !strings.HasPrefix(target, contextDir+string(filepath.Separator)) {
+			return config, nil, 0, fmt.Errorf("invalid path in build tar: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return config, nil, 0, err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return config, nil, 0, err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm()|0600)
+			if err != nil {
+				return config, nil, 0, err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return config, nil, 0, err
+			}
+			if err := f.Close(); err != nil {
+				return config, nil, 0, err
+			}
+			files++
+		}
+	}
+
+	if !foundConfig {
+		return config, nil, 0, fmt.Errorf("build tar is missing %s", sdkbuilder.BuilderConfigFileName)
+	}
+
+	return config, sealed, files, nil
+}
+
+// buildResultWriter sends results as a stream of JSON lines, or collects
+// the logs for a single result when the client didn't ask for a stream.
+type buildResultWriter struct {
+	w      http.ResponseWriter
+	stream bool
+
+	mu   sync.Mutex
+	logs []string
+}
+
+func newBuildResultWriter(w http.ResponseWriter, stream bool) *buildResultWriter {
+	return &buildResultWriter{w: w, stream: stream}
+}
+
+func (b *buildResultWriter) progress(lines ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.stream {
+		b.logs = append(b.logs, lines...)
+		return
+	}
+
+	b.write(sdkbuilder.BuildResult{Log: lines, Status: sdkbuilder.BuildInProgress})
+}
+
+func (b *buildResultWriter) finish(result sdkbuilder.BuildResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.stream {
+		result.Log = append(b.logs, result.Log...)
+		b.w.Header().Set("Content-Type", "application/json")
+		if result.Status == sdkbuilder.BuildFailed {
+			b.w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(b.w).Encode(result)
+		return
+	}
+
+	b.write(result)
+}
+
+func (b *buildResultWriter) write(result sdkbuilder.BuildResult) {
+	b.w.Header().Set("Content-Type", "application/x-ndjson")
+	json.NewEncoder(b.w).Encode(result)
+	if f, ok := b.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// buildLogWriter turns the output of docker buildx into one log line per
+// result.
+type buildLogWriter struct {
+	out *buildResultWriter
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (l *buildLogWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.out.progress(strings.TrimRight(string(l.buf[:i]), "\r"))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush sends any output which did not end with a newline.
+func (l *buildLogWriter) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.buf) > 0 {
+		l.out.progress(string(l.buf))
+		l.buf = nil
+	}
+}
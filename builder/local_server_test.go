@@ -0,0 +1,174 @@
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdkbuilder "github.com/openfaas/go-sdk/builder"
+	"github.com/openfaas/go-sdk/seal"
+)
+
+func makeLocalServerTar(t *testing.T) string {
+	t.Helper()
+
+	contextDir := filepath.Join(t.TempDir(), "fn")
+	if err := os.MkdirAll(filepath.Join(contextDir, "function"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll returned error: %v", err)
+	}
+	for name, data := range map[string]string{
+		"Dockerfile":          "FROM scratch\n",
+		"function/handler.go": "package function\n",
+	} {
+		if err := os.WriteFile(filepath.Join(contextDir, name), []byte(data), 0o600); err != nil {
+			t.Fatalf("os.WriteFile returned error: %v", err)
+		}
+	}
+
+	tarPath := filepath.Join(t.TempDir(), "req.tar")
+	if err := sdkbuilder.MakeTar(tarPath, contextDir, &sdkbuilder.BuildConfig{
+		Image:     "ttl.sh/fn:latest",
+		BuildArgs: map[string]string{"GO111MODULE": "on"},
+		Platforms: []string{"linux/amd64"},
+	}); err != nil {
+		t.Fatalf("MakeTar returned error: %v", err)
+	}
+	return tarPath
+}
+
+func TestLocalServerSimulatedBuild(t *testing.T) {
+	pub, priv, err := seal.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("seal.GenerateKeyPair: %v", err)
+	}
+
+	server := httptest.NewServer(&LocalServer{
+		PayloadSecret: []byte("payload-secret"),
+		PublicKey:     pub,
+		PrivateKey:    priv,
+		Simulate:      true,
+	})
+	defer server.Close()
+
+	builderURL, _ := url.Parse(server.URL)
+
+	payloadSecretPath := filepath.Join(t.TempDir(), "payload-secret")
+	if err := os.WriteFile(payloadSecretPath, []byte("payload-secret\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	tarPath := makeLocalServerTar(t)
+
+	var out bytes.Buffer
+	progress := NewBuildProgress(&out)
+
+	// The public key is fetched from /publickey to seal the build secret
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", map[string]string{
+		"npmrc": writeTempSecret(t, "token"),
+	}, false, "fn", "ttl.sh/fn:latest", "sha256:abc", progress); err != nil {
+		t.Fatalf("runRemoteBuild returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Received build context with 2 files",
+		"Build args: GO111MODULE",
+		"Unsealed build secrets: npmrc",
+		"Simulated build of ttl.sh/fn:latest for linux/amd64",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in output:\n%s", want, out.String())
+		}
+	}
+
+	// A second build with the same cache key reuses the image
+	out.Reset()
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", nil, false, "fn", "ttl.sh/fn:latest", "sha256:abc", progress); err != nil {
+		t.Fatalf("runRemoteBuild returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "build context unchanged") {
+		t.Errorf("want a cache hit for the same key, got:\n%s", out.String())
+	}
+}
+
+func TestLocalServerRejectsInvalidSignature(t *testing.T) {
+	server := httptest.NewServer(&LocalServer{
+		PayloadSecret: []byte("payload-secret"),
+		Simulate:      true,
+	})
+	defer server.Close()
+
+	builderURL, _ := url.Parse(server.URL)
+
+	payloadSecretPath := filepath.Join(t.TempDir(), "payload-secret")
+	if err := os.WriteFile(payloadSecretPath, []byte("wrong-secret"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	_, err := runRemoteBuild(builderURL, makeLocalServerTar(t), payloadSecretPath, "", nil, true, "fn", "ttl.sh/fn:latest", "", NewBuildProgress(&out))
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("want a 401 for the wrong payload secret, got: %v", err)
+	}
+}
+
+func TestLocalServerBuildSecretsNeedKey(t *testing.T) {
+	pub, _, err := seal.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("seal.GenerateKeyPair: %v", err)
+	}
+
+	server := httptest.NewServer(&LocalServer{
+		PayloadSecret: []byte("payload-secret"),
+		Simulate:      true,
+	})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/publickey")
+	if err != nil {
+		t.Fatalf("http.Get returned error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("want 404 for /publickey without a key, got %d", res.StatusCode)
+	}
+
+	builderURL, _ := url.Parse(server.URL)
+
+	payloadSecretPath := filepath.Join(t.TempDir(), "payload-secret")
+	if err := os.WriteFile(payloadSecretPath, []byte("payload-secret"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	_, err = runRemoteBuild(builderURL, makeLocalServerTar(t), payloadSecretPath, string(pub), map[string]string{
+		"npmrc": writeTempSecret(t, "token"),
+	}, true, "fn", "ttl.sh/fn:latest", "", NewBuildProgress(&out))
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("want a 400 for build secrets without a key, got: %v", err)
+	}
+}
+
+func TestExtractBuildTarRejectsTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	data := []byte("x")
+	if err := tw.WriteHeader(&tar.Header{Name: "context/../../escape", Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar.WriteHeader: %v", err)
+	}
+	tw.Write(data)
+	tw.Close()
+
+	dir := t.TempDir()
+	if _, _, _, err := extractBuildTar(buf.Bytes(), filepath.Join(dir, "context")); err == nil {
+		t.Fatalf("want an error for a path outside of the context")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "escape")); err == nil {
+		t.Fatalf("want no file written outside of the context")
+	}
+}
@@ -5,6 +5,7 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -12,29 +13,33 @@ import (
 	"strings"
 
 	v2execute "github.com/alexellis/go-execute/v2"
+	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/openfaas/faas-cli/schema"
 	sdkbuilder "github.com/openfaas/go-sdk/builder"
 	"github.com/openfaas/go-sdk/stack"
 )
 
-// PublishImage will publish images as multi-arch
+// PublishImage will publish images as multi-arch, with SBOM and provenance
+// attestations when requested. Remote builds are reported through progress
+// so that they can be shown together when several functions are published
+// in parallel.
 // TODO: refactor signature to a struct to simplify the length of the method header
 func PublishImage(image string, handler string, functionName string, language string, nocache bool, squash bool, shrinkwrap bool, buildArgMap map[string]string,
-	buildOptions []string, tagMode schema.BuildFormat, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string, buildSecrets map[string]string, platforms string, extraTags []string, remoteBuilder, payloadSecretPath, builderPublicKeyPath string, forcePull bool) error {
+	buildOptions []string, tagMode schema.BuildFormat, buildLabelMap map[string]string, quietBuild bool, copyExtraPaths []string, buildSecrets map[string]string, platforms string, extraTags []string, remoteBuilder, payloadSecretPath, builderPublicKeyPath string, forcePull, sbom, provenance bool, progress *BuildProgress) (PublishedImage, error) {
 
 	if stack.IsValidTemplate(language) {
 		pathToTemplateYAML := fmt.Sprintf("./template/%s/template.yml", language)
 		if _, err := os.Stat(pathToTemplateYAML); err != nil && os.IsNotExist(err) {
-			return err
+			return PublishedImage{}, err
 		}
 
 		langTemplate, err := stack.ParseYAMLForLanguageTemplate(pathToTemplateYAML)
 		if err != nil {
-			return fmt.Errorf("error reading language template: %s", err.Error())
+			return PublishedImage{}, fmt.Errorf("error reading language template: %s", err.Error())
 		}
 
 		if err := ensureHandlerPath(handler); err != nil {
-			return fmt.Errorf("building %s, %s is an invalid path", functionName, handler)
+			return PublishedImage{}, fmt.Errorf("building %s, %s is an invalid path", functionName, handler)
 		}
 
 		opts := []sdkbuilder.BuildContextOption{}
@@ -44,24 +49,24 @@ func PublishImage(image string, handler string, functionName string, language st
 
 		buildContext, err := sdkbuilder.CreateBuildContext(functionName, handler, language, copyExtraPaths, opts...)
 		if err != nil {
-			return err
+			return PublishedImage{}, err
 		}
 
 		if shrinkwrap {
 			fmt.Printf("%s shrink-wrapped to %s\n", functionName, buildContext)
-			return nil
+			return PublishedImage{}, nil
 		}
 
 		branch, version, err := GetImageTagValues(tagMode, handler)
 		if err != nil {
-			return err
+			return PublishedImage{}, err
 		}
 
 		imageName := schema.BuildImageName(tagMode, image, version, branch)
 
 		buildOptPackages, err := getBuildOptionPackages(buildOptions, language, langTemplate.BuildOptions)
 		if err != nil {
-			return err
+			return PublishedImage{}, err
 		}
 		buildArgMap = appendAdditionalPackages(buildArgMap, buildOptPackages)
 
@@ -69,18 +74,23 @@ func PublishImage(image string, handler string, functionName string, language st
 
 		buildSecrets, err = resolveSecretPaths(buildSecrets)
 		if err != nil {
-			return err
+			return PublishedImage{}, err
 		}
 
+		var digest string
 		if remoteBuilder != "" {
 
 			if forcePull {
-				return fmt.Errorf("--pull is not supported with --remote-builder")
+				return PublishedImage{}, fmt.Errorf("--pull is not supported with --remote-builder")
+			}
+
+			if sbom || provenance {
+				return PublishedImage{}, fmt.Errorf("--sbom and --provenance are not supported with --remote-builder")
 			}
 
 			tempDir, err := os.MkdirTemp(os.TempDir(), "openfaas-build-*")
 			if err != nil {
-				return fmt.Errorf("failed to create temporary directory: %w", err)
+				return PublishedImage{}, fmt.Errorf("failed to create temporary directory: %w", err)
 			}
 			defer os.RemoveAll(tempDir)
 
@@ -95,7 +105,12 @@ func PublishImage(image string, handler string, functionName string, language st
 
 			// Prepare a tar archive that contains the build config and build context.
 			if err := sdkbuilder.MakeTar(tarPath, path.Join("build", functionName), &buildConfig); err != nil {
-				return fmt.Errorf("failed to create tar file for %s, error: %w", functionName, err)
+				return PublishedImage{}, fmt.Errorf("failed to create tar file for %s, error: %w", functionName, err)
+			}
+
+			cacheKey, err := remoteBuildCacheKey(path.Join("build", functionName), buildConfig)
+			if err != nil {
+				return PublishedImage{}, err
 			}
 
 			u, _ := url.Parse(remoteBuilder)
@@ -103,11 +118,26 @@ func PublishImage(image string, handler string, functionName string, language st
 				Scheme: u.Scheme,
 				Host:   u.Host,
 			}
-			if err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, builderPublicKeyPath, buildSecrets, quietBuild, functionName, imageName); err != nil {
-				return fmt.Errorf("failed to invoke builder: %w", err)
+			builtImage, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, builderPublicKeyPath, buildSecrets, quietBuild, functionName, imageName, cacheKey, progress)
+			if err != nil {
+				return PublishedImage{}, fmt.Errorf("failed to invoke builder: %w", err)
+			}
+
+			// The image has been pushed, so a digest which can't be found
+			// is left out of the publish manifest rather than failing
+			if digest, err = remoteBuildDigest(builtImage, imageName); err != nil {
+				fmt.Printf("Unable to record the digest of %s: %s\n", imageName, err)
 			}
 
 		} else {
+			metadataDir, err := os.MkdirTemp(os.TempDir(), "openfaas-publish-*")
+			if err != nil {
+				return PublishedImage{}, fmt.Errorf("failed to create temporary directory: %w", err)
+			}
+			defer os.RemoveAll(metadataDir)
+
+			metadataFile := path.Join(metadataDir, "metadata.json")
+
 			dockerBuildVal := dockerBuild{
 				Image:         imageName,
 				NoCache:       nocache,
@@ -120,6 +150,9 @@ func PublishImage(image string, handler string, functionName string, language st
 				ExtraTags:     extraTags,
 				ForcePull:     forcePull,
 				BuildSecrets:  buildSecrets,
+				SBOM:          sbom,
+				Provenance:    provenance,
+				MetadataFile:  metadataFile,
 			}
 
 			command, args := getDockerBuildxCommand(dockerBuildVal)
@@ -135,21 +168,67 @@ func PublishImage(image string, handler string, functionName string, language st
 			res, err := task.Execute(context.TODO())
 
 			if err != nil {
-				return err
+				return PublishedImage{}, err
 			}
 
 			if res.ExitCode != 0 {
-				return fmt.Errorf("[%s] received non-zero exit code from build, error: %s", functionName, res.Stderr)
+				return PublishedImage{}, fmt.Errorf("[%s] received non-zero exit code from build, error: %s", functionName, res.Stderr)
 			}
 
 			fmt.Printf("Image: %s built.\n", imageName)
+
+			digest, err = readBuildxDigest(metadataFile)
+			if err != nil {
+				return PublishedImage{}, err
+			}
 		}
 
-	} else {
-		return fmt.Errorf("language template: %s not supported, build a custom Dockerfile", language)
+		return PublishedImage{Name: imageName, Digest: digest}, nil
 	}
 
-	return nil
+	return PublishedImage{}, fmt.Errorf("language template: %s not supported, build a custom Dockerfile", language)
+}
+
+// PublishedImage is the image pushed by PublishImage, along with its digest
+// when it is known.
+type PublishedImage struct {
+	Name   string
+	Digest string
+}
+
+// remoteBuildDigest returns the digest of an image pushed by the remote
+// builder, from the image it reported when that has a digest, otherwise
+// from the registry.
+func remoteBuildDigest(builtImage, imageName string, options ...crane.Option) (string, error) {
+	if i := strings.LastIndex(builtImage, "@"); i > -1 {
+		return builtImage[i+1:], nil
+	}
+
+	digest, err := crane.Digest(imageName, options...)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the digest of %s: %w", imageName, err)
+	}
+	return digest, nil
+}
+
+// readBuildxDigest reads the digest of the pushed image from the file
+// written by buildx with --metadata-file.
+func readBuildxDigest(metadataFile string) (string, error) {
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to read buildx metadata: %w", err)
+	}
+
+	metadata := map[string]interface{}{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return "", fmt.Errorf("unable to parse buildx metadata: %w", err)
+	}
+
+	digest, _ := metadata["containerimage.digest"].(string)
+	return digest, nil
 }
 
 func getDockerBuildxCommand(build dockerBuild) (string, []string) {
@@ -161,6 +240,16 @@ func getDockerBuildxCommand(build dockerBuild) (string, []string) {
 
 	args := []string{"buildx", "build", "--progress=plain", "--platform=" + build.Platforms, pushOnly}
 
+	if build.SBOM {
+		args = append(args, "--sbom=true")
+	}
+	if build.Provenance {
+		args = append(args, "--provenance=mode=max")
+	}
+	if len(build.MetadataFile) > 0 {
+		args = append(args, "--metadata-file", build.MetadataFile)
+	}
+
 	args = append(args, flagSlice...)
 
 	args = append(args, "--tag", build.Image, ".")
@@ -0,0 +1,119 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PublishManifestFile is where "faas-cli publish" records what was published
+// for each function, relative to the working directory.
+const PublishManifestFile = "build/publish-manifest.json"
+
+// PublishManifest records the supply-chain metadata of each published
+// function, so that "faas-cli deploy --verify-manifest" can check what it
+// deploys is what was published.
+type PublishManifest struct {
+	Functions map[string]PublishedFunction `json:"functions"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// PublishedFunction is the record of a single function's last publish.
+type PublishedFunction struct {
+	Image string `json:"image"`
+
+	// Digest of the pushed image or image index, when it is known.
+	Digest string `json:"digest,omitempty"`
+
+	Platforms []string `json:"platforms,omitempty"`
+
+	Template PublishedTemplate `json:"template"`
+
+	// GitSHA is the commit of the function's source.
+	GitSHA string `json:"git_sha,omitempty"`
+
+	// SBOM and Provenance are true when buildx attached the attestations
+	// to the image.
+	SBOM       bool `json:"sbom"`
+	Provenance bool `json:"provenance"`
+
+	Published time.Time `json:"published"`
+}
+
+// PublishedTemplate identifies the template a function was built from, as
+// recorded in the template's meta.json when it was pulled.
+type PublishedTemplate struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository,omitempty"`
+	RefName    string `json:"ref_name,omitempty"`
+	Sha        string `json:"sha,omitempty"`
+}
+
+// LoadPublishManifest reads the manifest from path. A missing file results
+// in an empty manifest.
+func LoadPublishManifest(path string) (*PublishManifest, error) {
+	manifest := &PublishManifest{
+		Functions: make(map[string]PublishedFunction),
+		path:      path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, fmt.Errorf("unable to read publish manifest %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse publish manifest %s: %w", path, err)
+	}
+
+	if manifest.Functions == nil {
+		manifest.Functions = make(map[string]PublishedFunction)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest back to the path it was loaded from.
+func (m *PublishManifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("unable to create directory for publish manifest: %w", err)
+	}
+
+	return os.WriteFile(m.path, data, 0644)
+}
+
+// Get returns the last publish of the function, if there was one.
+func (m *PublishManifest) Get(name string) (PublishedFunction, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	published, ok := m.Functions[name]
+	return published, ok
+}
+
+// Record stores the publish of a function, replacing any earlier one.
+func (m *PublishManifest) Record(name string, published PublishedFunction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Functions[name] = published
+}
@@ -0,0 +1,57 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_PublishManifest_RecordAndReload(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "build", "publish-manifest.json")
+
+	manifest, err := LoadPublishManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := manifest.Get("api"); ok {
+		t.Fatal("want an empty manifest when the file is missing")
+	}
+
+	published := PublishedFunction{
+		Image:     "ttl.sh/api:0.1.0",
+		Digest:    "sha256:abc",
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+		Template: PublishedTemplate{
+			Name:       "golang-middleware",
+			Repository: "https://github.com/openfaas/golang-http-template",
+			Sha:        "4b6e6d1",
+		},
+		GitSHA:     "0123456789abcdef0123456789abcdef01234567",
+		SBOM:       true,
+		Provenance: true,
+		Published:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	manifest.Record("api", published)
+
+	if err := manifest.Save(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	reloaded, err := LoadPublishManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, ok := reloaded.Get("api")
+	if !ok {
+		t.Fatal("want api in the reloaded manifest")
+	}
+	if !reflect.DeepEqual(got, published) {
+		t.Errorf("want %+v, got %+v", published, got)
+	}
+}
@@ -2,6 +2,8 @@ package builder
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +11,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	sdkbuilder "github.com/openfaas/go-sdk/builder"
 )
@@ -22,10 +27,31 @@ type remoteBuilderPublicKeyResponse struct {
 	PublicKey string `json:"public_key"`
 }
 
-func runRemoteBuild(builderURL *url.URL, tarPath, payloadSecretPath, builderPublicKeyPath string, buildSecrets map[string]string, quietBuild bool, functionName, imageName string) error {
+// RemoteBuildCacheKeyHeader carries a hash of the build context and the
+// build configuration, so that the builder can reuse the image it built for
+// an unchanged context instead of building it again.
+const RemoteBuildCacheKeyHeader = "X-Build-Cache-Key"
+
+// remoteBuildCacheHeader is set to "hit" by a builder which reused an image
+// for the cache key.
+const remoteBuildCacheHeader = "X-Build-Cache"
+
+// remoteBuildAttempts is how many times the tar is uploaded to the builder
+// when the upload fails with a transient error.
+var remoteBuildAttempts = 3
+
+// remoteBuildBackoff is multiplied by the attempt number to give the wait
+// before uploading the tar again.
+var remoteBuildBackoff = 2 * time.Second
+
+// runRemoteBuild uploads the tar to the builder and reports the build
+// through progress, which is shared when functions are built in parallel.
+// When progress is nil, the build is reported on its own. The image which the
+// builder reports it pushed is returned.
+func runRemoteBuild(builderURL *url.URL, tarPath, payloadSecretPath, builderPublicKeyPath string, buildSecrets map[string]string, quietBuild bool, functionName, imageName, cacheKey string, progress *BuildProgress) (string, error) {
 	payloadSecret, err := os.ReadFile(payloadSecretPath)
 	if err != nil {
-		return fmt.Errorf("failed to read payload secret: %w", err)
+		return "", fmt.Errorf("failed to read payload secret: %w", err)
 	}
 	payloadSecret = bytes.TrimSpace(payloadSecret)
 
@@ -36,29 +62,188 @@ func runRemoteBuild(builderURL *url.URL, tarPath, payloadSecretPath, builderPubl
 	if len(buildSecrets) > 0 {
 		publicKey, err := resolveRemoteBuilderPublicKey(builderURL, builderPublicKeyPath)
 		if err != nil {
-			return err
+			return "", err
 		}
 		opts = append(opts, sdkbuilder.WithBuildSecretsKey([]byte(publicKey.PublicKey)))
 	}
 
-	b := sdkbuilder.NewFunctionBuilder(builderURL, http.DefaultClient, opts...)
+	if progress == nil {
+		progress = NewBuildProgress(os.Stdout)
+	}
+	progress.Start(functionName, imageName)
+
+	transport := &remoteBuildTransport{
+		cacheKey: cacheKey,
+		attempts: remoteBuildAttempts,
+		backoff:  remoteBuildBackoff,
+		onRetry: func(attempt int, err error) {
+			progress.Retry(functionName, attempt, err)
+		},
+	}
+
+	b := sdkbuilder.NewFunctionBuilder(builderURL, &http.Client{Transport: transport}, opts...)
 
 	var stream *sdkbuilder.BuildResultStream
 	if len(buildSecrets) > 0 {
-		resolvedSecrets, err := readBuildSecrets(buildSecrets)
+		var resolvedSecrets map[string]string
+		resolvedSecrets, err = readBuildSecrets(buildSecrets)
 		if err != nil {
-			return err
+			return "", err
 		}
 		stream, err = b.BuildWithSecretsStream(tarPath, resolvedSecrets)
 	} else {
 		stream, err = b.BuildWithStream(tarPath)
 	}
 	if err != nil {
-		return err
+		progress.Fail(functionName, err)
+		return "", err
 	}
 	defer stream.Close()
 
-	return consumeBuildStream(stream, quietBuild, functionName, imageName)
+	if transport.cacheHit() {
+		progress.Cached(functionName)
+	}
+
+	builtImage, err := consumeBuildStream(stream, quietBuild, functionName, imageName, progress)
+	if err != nil {
+		progress.Fail(functionName, err)
+		return "", err
+	}
+	return builtImage, nil
+}
+
+// remoteBuildTransport sends the cache key with the tar, and uploads the tar
+// again when the builder can't be reached or is temporarily unavailable.
+// The Function Builder API has no partial uploads, so each attempt sends the
+// whole tar, which the SDK keeps in memory.
+type remoteBuildTransport struct {
+	base     http.RoundTripper
+	cacheKey string
+	attempts int
+	backoff  time.Duration
+	onRetry  func(attempt int, err error)
+
+	mu  sync.Mutex
+	hit bool
+}
+
+func (t *remoteBuildTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for attempt := 1; ; attempt++ {
+		r := req.Clone(req.Context())
+		if attempt > 1 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		if len(t.cacheKey) > 0 {
+			r.Header.Set(RemoteBuildCacheKeyHeader, t.cacheKey)
+		}
+
+		res, err := base.RoundTrip(r)
+
+		retryErr := transientBuildError(req, res, err)
+		if retryErr == nil || attempt >= t.attempts || req.GetBody == nil {
+			if res != nil && strings.EqualFold(res.Header.Get(remoteBuildCacheHeader), "hit") {
+				t.mu.Lock()
+				t.hit = true
+				t.mu.Unlock()
+			}
+			return res, err
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if t.onRetry != nil {
+			t.onRetry(attempt, retryErr)
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(t.backoff * time.Duration(attempt)):
+		}
+	}
+}
+
+func (t *remoteBuildTransport) cacheHit() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hit
+}
+
+// transientBuildError returns the reason to retry an upload, or nil when the
+// builder accepted or rejected it.
+func transientBuildError(req *http.Request, res *http.Response, err error) error {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("builder responded with status code %d", res.StatusCode)
+	}
+	return nil
+}
+
+// remoteBuildCacheKey hashes the path and contents of each file in the build
+// context along with the build configuration, so any change to either gives
+// a new key.
+func remoteBuildCacheKey(contextPath string, buildConfig sdkbuilder.BuildConfig) (string, error) {
+	h := sha256.New()
+
+	if err := writeFileDigests(h, contextPath); err != nil {
+		return "", fmt.Errorf("unable to hash build context %q: %w", contextPath, err)
+	}
+
+	config, err := json.Marshal(buildConfig)
+	if err != nil {
+		return "", err
+	}
+	h.Write(config)
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFileDigests writes the relative path, SHA256 and permissions of each
+// regular file under root to w, so that renaming a file or changing its mode
+// changes the result as well as editing it.
+func writeFileDigests(w io.Writer, root string) error {
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(w, "%s %s %o\n", filepath.ToSlash(rel), hex.EncodeToString(sum[:]), info.Mode().Perm())
+		return nil
+	})
 }
 
 // readBuildSecrets resolves build secret values by reading file contents.
@@ -180,23 +365,23 @@ func parseRemoteBuilderPublicKey(data []byte) (*remoteBuilderPublicKeyResponse,
 	}, nil
 }
 
-func consumeBuildStream(stream *sdkbuilder.BuildResultStream, quietBuild bool, functionName, imageName string) error {
+// consumeBuildStream reports each result of the build, and returns the image
+// of the successful one.
+func consumeBuildStream(stream *sdkbuilder.BuildResultStream, quietBuild bool, functionName, imageName string, progress *BuildProgress) (string, error) {
+	builtImage := ""
 	for result, err := range stream.Results() {
 		if err != nil {
-			return err
-		}
-		if !quietBuild {
-			for _, logMsg := range result.Log {
-				fmt.Printf("%s\n", logMsg)
-			}
+			return "", err
 		}
+		progress.Update(functionName, result, quietBuild)
 
 		switch result.Status {
 		case sdkbuilder.BuildSuccess:
 			log.Printf("%s success building and pushing image: %s", functionName, result.Image)
+			builtImage = result.Image
 		case sdkbuilder.BuildFailed:
-			return fmt.Errorf("%s failure while building or pushing image %s: %s", functionName, imageName, result.Error)
+			return "", fmt.Errorf("%s failure while building or pushing image %s: %s", functionName, imageName, result.Error)
 		}
 	}
-	return nil
+	return builtImage, nil
 }
@@ -7,14 +7,22 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alexellis/hmac/v2"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	sdkbuilder "github.com/openfaas/go-sdk/builder"
 	"github.com/openfaas/go-sdk/seal"
 )
 
@@ -108,9 +116,9 @@ func TestRunRemoteBuildWithSecrets(t *testing.T) {
 		t.Fatalf("url.Parse returned error: %v", err)
 	}
 
-	if err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", map[string]string{
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", map[string]string{
 		"pip_token": writeTempSecret(t, "s3cr3t"),
-	}, true, "fn", "ttl.sh/test:latest"); err != nil {
+	}, true, "fn", "ttl.sh/test:latest", "", nil); err != nil {
 		t.Fatalf("runRemoteBuild returned error: %v", err)
 	}
 }
@@ -159,9 +167,9 @@ func TestRunRemoteBuildWithPinnedPublicKey(t *testing.T) {
 		t.Fatalf("url.Parse returned error: %v", err)
 	}
 
-	if err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, publicKeyPath, map[string]string{
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, publicKeyPath, map[string]string{
 		"pip_token": writeTempSecret(t, "s3cr3t"),
-	}, true, "fn", "ttl.sh/test:latest"); err != nil {
+	}, true, "fn", "ttl.sh/test:latest", "", nil); err != nil {
 		t.Fatalf("runRemoteBuild returned error: %v", err)
 	}
 }
@@ -197,9 +205,9 @@ func TestRunRemoteBuildWithLiteralPublicKey(t *testing.T) {
 		t.Fatalf("url.Parse returned error: %v", err)
 	}
 
-	if err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, string(pub), map[string]string{
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, string(pub), map[string]string{
 		"pip_token": writeTempSecret(t, "s3cr3t"),
-	}, true, "fn", "ttl.sh/test:latest"); err != nil {
+	}, true, "fn", "ttl.sh/test:latest", "", nil); err != nil {
 		t.Fatalf("runRemoteBuild returned error: %v", err)
 	}
 }
@@ -275,3 +283,167 @@ func TestReadBuildSecrets_FileNotFound(t *testing.T) {
 		t.Fatal("expected error for missing file, got nil")
 	}
 }
+
+func TestRunRemoteBuildRetriesTransientFailures(t *testing.T) {
+	defer func(backoff time.Duration) { remoteBuildBackoff = backoff }(remoteBuildBackoff)
+	remoteBuildBackoff = time.Millisecond
+
+	tarPath := filepath.Join(t.TempDir(), "req.tar")
+	tarData := createTestTar(t)
+	if err := os.WriteFile(tarPath, tarData, 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	payloadSecretPath := filepath.Join(t.TempDir(), "payload-secret")
+	if err := os.WriteFile(payloadSecretPath, []byte("payload-secret"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !bytes.Equal(body, tarData) {
+			t.Errorf("want the whole tar to be uploaded on each attempt, got %d bytes", len(body))
+		}
+		if got := r.Header.Get(RemoteBuildCacheKeyHeader); got != "sha256:abc" {
+			t.Errorf("want cache key header, got %q", got)
+		}
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Build-Cache", "hit")
+		io.WriteString(w, `{"status":"success","image":"ttl.sh/test:latest"}`+"\n")
+	}))
+	defer server.Close()
+
+	builderURL, _ := url.Parse(server.URL)
+
+	var out bytes.Buffer
+	progress := NewBuildProgress(&out)
+
+	builtImage, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", nil, false, "fn", "ttl.sh/test:latest", "sha256:abc", progress)
+	if err != nil {
+		t.Fatalf("runRemoteBuild returned error: %v", err)
+	}
+	if builtImage != "ttl.sh/test:latest" {
+		t.Fatalf("want the image reported by the builder, got %q", builtImage)
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("want 2 uploads, got %d", got)
+	}
+
+	progress.Summary()
+	for _, want := range []string{"fn | upload failed, retrying (1)", "fn | build context unchanged", "success (cached) (1 retries)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunRemoteBuildGivesUpAfterAttempts(t *testing.T) {
+	defer func(backoff time.Duration) { remoteBuildBackoff = backoff }(remoteBuildBackoff)
+	remoteBuildBackoff = time.Millisecond
+
+	tarPath := filepath.Join(t.TempDir(), "req.tar")
+	if err := os.WriteFile(tarPath, createTestTar(t), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	payloadSecretPath := filepath.Join(t.TempDir(), "payload-secret")
+	if err := os.WriteFile(payloadSecretPath, []byte("payload-secret"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	builderURL, _ := url.Parse(server.URL)
+
+	var out bytes.Buffer
+	progress := NewBuildProgress(&out)
+
+	if _, err := runRemoteBuild(builderURL, tarPath, payloadSecretPath, "", nil, true, "fn", "ttl.sh/test:latest", "", progress); err == nil {
+		t.Fatalf("want an error after all attempts failed")
+	}
+
+	if got := atomic.LoadInt32(&calls); int(got) != remoteBuildAttempts {
+		t.Fatalf("want %d uploads, got %d", remoteBuildAttempts, got)
+	}
+
+	progress.Summary()
+	if !strings.Contains(out.String(), "failed") {
+		t.Errorf("want the failure in the summary:\n%s", out.String())
+	}
+}
+
+func TestRemoteBuildCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "handler.go"), []byte("package function"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile returned error: %v", err)
+	}
+
+	config := sdkbuilder.BuildConfig{Image: "ttl.sh/fn:latest"}
+
+	first, err := remoteBuildCacheKey(dir, config)
+	if err != nil {
+		t.Fatalf("remoteBuildCacheKey returned error: %v", err)
+	}
+
+	again, _ := remoteBuildCacheKey(dir, config)
+	if first != again {
+		t.Fatalf("want the same key for an unchanged context, got %s and %s", first, again)
+	}
+
+	config.BuildArgs = map[string]string{"GO111MODULE": "on"}
+	withArgs, _ := remoteBuildCacheKey(dir, config)
+	if withArgs == first {
+		t.Fatalf("want a new key when the build args change")
+	}
+
+	if err := os.Rename(filepath.Join(dir, "handler.go"), filepath.Join(dir, "main.go")); err != nil {
+		t.Fatalf("os.Rename returned error: %v", err)
+	}
+	renamed, _ := remoteBuildCacheKey(dir, config)
+	if renamed == withArgs {
+		t.Fatalf("want a new key when a file is renamed")
+	}
+}
+
+func TestRemoteBuildDigest(t *testing.T) {
+	reg := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer reg.Close()
+	image := strings.TrimPrefix(reg.URL, "http://") + "/fn:latest"
+
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	if err := crane.Push(img, image); err != nil {
+		t.Fatalf("crane.Push: %v", err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if digest, err := remoteBuildDigest(image, image); err != nil || digest != want.String() {
+		t.Fatalf("want the digest from the registry %s, got %s %v", want, digest, err)
+	}
+
+	if digest, err := remoteBuildDigest(image+"@sha256:abc", image); err != nil || digest != "sha256:abc" {
+		t.Fatalf("want the digest reported by the builder, got %s %v", digest, err)
+	}
+
+	if _, err := remoteBuildDigest("", strings.TrimPrefix(reg.URL, "http://")+"/missing:latest"); err == nil {
+		t.Fatalf("want an error for an image which was not pushed")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package builder
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	sdkbuilder "github.com/openfaas/go-sdk/builder"
+)
+
+// BuildProgress reports the builds of several functions by the remote
+// builder in one view. Log lines are prefixed with the function name so
+// that concurrent builds can be told apart, and a summary is printed once
+// all of them are complete.
+type BuildProgress struct {
+	out io.Writer
+
+	mu     sync.Mutex
+	builds map[string]*remoteBuildState
+	width  int
+}
+
+type remoteBuildState struct {
+	status   string
+	image    string
+	err      string
+	cached   bool
+	attempts int
+	started  time.Time
+	finished time.Time
+}
+
+// NewBuildProgress creates a BuildProgress which writes to out.
+func NewBuildProgress(out io.Writer) *BuildProgress {
+	return &BuildProgress{
+		out:    out,
+		builds: make(map[string]*remoteBuildState),
+	}
+}
+
+// Start records that the build context of a function is being uploaded.
+func (p *BuildProgress) Start(functionName, imageName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.builds[functionName] = &remoteBuildState{
+		status:  "uploading",
+		image:   imageName,
+		started: time.Now(),
+	}
+	if len(functionName) > p.width {
+		p.width = len(functionName)
+	}
+}
+
+// Retry records a failed upload which is about to be attempted again.
+func (p *BuildProgress) Retry(functionName string, attempt int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if state, ok := p.builds[functionName]; ok {
+		state.attempts = attempt
+	}
+	p.printf(functionName, "upload failed, retrying (%d): %s", attempt, err)
+}
+
+// Cached records that the builder already had an image for the build
+// context, so no build was needed.
+func (p *BuildProgress) Cached(functionName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if state, ok := p.builds[functionName]; ok {
+		state.cached = true
+	}
+	p.printf(functionName, "build context unchanged, reusing the previous image")
+}
+
+// Update records a result from the build stream, printing its log lines
+// unless quiet is set.
+func (p *BuildProgress) Update(functionName string, result sdkbuilder.BuildResult, quiet bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.builds[functionName]
+	if !ok {
+		state = &remoteBuildState{started: time.Now()}
+		p.builds[functionName] = state
+	}
+
+	if !quiet {
+		for _, line := range result.Log {
+			p.printf(functionName, "%s", line)
+		}
+	}
+
+	if len(result.Status) > 0 {
+		state.status = result.Status
+	}
+	if len(result.Image) > 0 {
+		state.image = result.Image
+	}
+	if len(result.Error) > 0 {
+		state.err = result.Error
+	}
+	if result.Status == sdkbuilder.BuildSuccess || result.Status == sdkbuilder.BuildFailed {
+		state.finished = time.Now()
+	}
+}
+
+// Fail records an error which ended a build before the builder reported
+// a result, such as an upload which could not be completed.
+func (p *BuildProgress) Fail(functionName string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.builds[functionName]
+	if !ok {
+		return
+	}
+	if state.status != sdkbuilder.BuildFailed {
+		state.status = sdkbuilder.BuildFailed
+		state.err = err.Error()
+	}
+	if state.finished.IsZero() {
+		state.finished = time.Now()
+	}
+}
+
+// Summary prints the status, duration and image of each function.
+func (p *BuildProgress) Summary() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.builds) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(p.builds))
+	for name := range p.builds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "FUNCTION\tSTATUS\tDURATION\tIMAGE")
+	for _, name := range names {
+		state := p.builds[name]
+
+		status := state.status
+		if state.cached {
+			status += " (cached)"
+		}
+		if state.attempts > 0 {
+			status += fmt.Sprintf(" (%d retries)", state.attempts)
+		}
+
+		duration := "-"
+		if !state.finished.IsZero() {
+			duration = fmt.Sprintf("%1.2fs", state.finished.Sub(state.started).Seconds())
+		}
+
+		image := state.image
+		if state.status == sdkbuilder.BuildFailed && len(state.err) > 0 {
+			image = state.err
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, status, duration, strings.TrimSpace(image))
+	}
+	w.Flush()
+}
+
+// printf writes one line prefixed by the function name, the lock must be
+// held by the caller.
+func (p *BuildProgress) printf(functionName, format string, a ...interface{}) {
+	fmt.Fprintf(p.out, "%-*s | %s\n", p.width, functionName, fmt.Sprintf(format, a...))
+}
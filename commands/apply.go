@@ -0,0 +1,504 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/schema"
+	types "github.com/openfaas/faas-provider/types"
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+)
+
+// stackOwnerLabel is set on every function deployed by "faas-cli apply" so
+// that functions removed from stack.yaml can be found and pruned later.
+const stackOwnerLabel = "com.openfaas.stack"
+
+var (
+	applyDryRun          bool
+	applyPrune           bool
+	applyStackName       string
+	applyVerifySignature bool
+	applySignatureKey    string
+)
+
+func init() {
+	applyCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	applyCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	applyCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yaml file")
+	applyCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	applyCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function(s)")
+	applyCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'digest', 'sha', 'branch', or 'describe', or 'latest'")
+	applyCmd.Flags().DurationVar(&timeoutOverride, "timeout", commandTimeout, "Timeout for any HTTP calls made to the OpenFaaS API.")
+
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Print the changes which would be made, without making them")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "Remove functions owned by this stack which are no longer in stack.yaml")
+	applyCmd.Flags().StringVar(&applyStackName, "stack-name", "", "Name used to label functions owned by this stack, defaults to the name of the folder containing stack.yaml")
+	applyCmd.Flags().BoolVar(&applyVerifySignature, "verify-signature", false, "Refuse to apply unless each image has a cosign signature made by --signature-key")
+	applyCmd.Flags().StringVar(&applySignatureKey, "signature-key", "", "Path to the PEM public key used to verify image signatures, overrides public_key in the stack.yaml policy")
+
+	faasCmd.AddCommand(applyCmd)
+}
+
+var applyCmd = &cobra.Command{
+	Use:   `apply -f YAML_FILE [--prune] [--dry-run] [--stack-name NAME]`,
+	Short: "Apply stack.yaml to the gateway, creating, updating and pruning functions",
+	Long: `Compares stack.yaml with the functions deployed on the gateway, then creates
+functions which are missing and updates functions which have drifted.
+
+Every function deployed by apply is labelled with ` + stackOwnerLabel + `. With --prune,
+any function carrying the label for this stack which is no longer in stack.yaml
+is removed, including from namespaces which are no longer used by stack.yaml, and
+an empty stack.yaml removes all of them. Functions deployed by other means are
+never removed.
+
+Secrets referenced by each function are checked before any change is made.
+When --verify-signature or the stack.yaml policy asks for signatures to be
+verified, every image to be created or updated is verified first, then the
+digest which was verified is deployed.`,
+	Example: `  faas-cli apply
+  faas-cli apply --dry-run
+  faas-cli apply --prune
+  faas-cli apply -f stack.yaml -f stack.prod.yaml --prune --stack-name shop`,
+	PreRunE: preRunApply,
+	RunE:    runApply,
+}
+
+func preRunApply(cmd *cobra.Command, args []string) error {
+	if len(yamlFile) == 0 {
+		return fmt.Errorf("no YAML file specified - use -f to provide a stack.yaml")
+	}
+
+	if applyPrune && (len(regex) > 0 || len(filter) > 0) {
+		return fmt.Errorf("--prune cannot be combined with --filter or --regex, as filtered out functions would be removed")
+	}
+
+	return nil
+}
+
+// applyAction describes the change to be made to a single function.
+type applyAction struct {
+	Name      string
+	Namespace string
+	Function  stack.Function
+	Rows      []diffRow
+}
+
+// applyPlan is the set of changes needed to bring a namespace in line with stack.yaml.
+type applyPlan struct {
+	Create    []applyAction
+	Update    []applyAction
+	Unchanged []applyAction
+	Delete    []applyAction
+
+	// Orphans are owned by the stack but no longer in stack.yaml,
+	// they are only moved to Delete when pruning.
+	Orphans []applyAction
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	services, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
+	if err != nil {
+		return err
+	}
+
+	// An empty stack is only useful to prune everything it owns
+	if services == nil || (len(services.Functions) == 0 && !applyPrune) {
+		return fmt.Errorf("no functions found in %s", yamlFile)
+	}
+
+	stackName, err := getApplyStackName(applyStackName, yamlFile)
+	if err != nil {
+		return err
+	}
+
+	gatewayAddress := getGatewayURL(gateway, defaultGateway, services.Provider.GatewayURL, os.Getenv(openFaaSURLEnvironment))
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
+	if err != nil {
+		return err
+	}
+	transport := GetDefaultCLITransport(tlsInsecure, &timeoutOverride)
+	proxyClient, err := proxy.NewClient(cliAuth, gatewayAddress, transport, &timeoutOverride)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	flags := DeployFlags{
+		update:          true,
+		verifySignature: applyVerifySignature,
+		signatureKey:    applySignatureKey,
+	}
+
+	policy, err := readSignaturePolicy(yamlFile)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := newImageVerifier(ctx, flags, policy)
+	if err != nil {
+		return err
+	}
+
+	deployed, err := listStackFunctions(ctx, proxyClient, services.Functions, stackName)
+	if err != nil {
+		return err
+	}
+
+	plan, err := computeApplyPlan(services.Functions, deployed, stackName, tagFormat, applyPrune, newImageResolver(ctx))
+	if err != nil {
+		return err
+	}
+
+	if err := checkApplySecrets(ctx, proxyClient, plan); err != nil {
+		return err
+	}
+
+	changes := append(plan.Create, plan.Update...)
+	deploySpecs := make(map[string]*proxy.DeployFunctionSpec, len(changes))
+	for _, action := range changes {
+		function := action.Function
+		function.Name = action.Name
+
+		deploySpec, err := buildDeploySpec(function, flags, tagFormat)
+		if err != nil {
+			return err
+		}
+
+		if deploySpec.Labels == nil {
+			deploySpec.Labels = map[string]string{}
+		}
+		deploySpec.Labels[stackOwnerLabel] = stackName
+		deploySpecs[action.Name] = deploySpec
+	}
+
+	// No change is made unless every image is verified, then the digest
+	// which was verified is deployed
+	if verifier != nil && len(deploySpecs) > 0 {
+		images := make(map[string]string, len(deploySpecs))
+		for name, deploySpec := range deploySpecs {
+			images[name] = deploySpec.Image
+		}
+		verified, err := verifyImages(verifier, images)
+		if err != nil {
+			return err
+		}
+		for name, deploySpec := range deploySpecs {
+			deploySpec.Image = verified[name]
+		}
+	}
+
+	printApplyPlan(stackName, plan)
+
+	if applyDryRun {
+		fmt.Println("Dry run, no changes were made.")
+		return nil
+	}
+
+	failedStatusCodes := make(map[string]int)
+
+	for _, action := range changes {
+		deploySpec := deploySpecs[action.Name]
+
+		if msg := checkTLSInsecure(gatewayAddress, deploySpec.TLSInsecure); len(msg) > 0 {
+			fmt.Println(msg)
+		}
+
+		fmt.Printf("Deploying: %s.\n", action.Name)
+		statusCode := proxyClient.DeployFunction(ctx, deploySpec)
+		if badStatusCode(statusCode) {
+			failedStatusCodes[action.Name] = statusCode
+		} else {
+			recordDeployment(proxyClient.GatewayURL.String(), deploySpec)
+		}
+	}
+
+	if err := deployFailed(failedStatusCodes); err != nil {
+		return err
+	}
+
+	for _, action := range plan.Delete {
+		fmt.Printf("Removing: %s.\n", diffKey(action.Name, action.Namespace))
+		if err := proxyClient.DeleteFunction(ctx, action.Name, action.Namespace); err != nil {
+			return fmt.Errorf("unable to remove %s: %w", action.Name, err)
+		}
+	}
+
+	fmt.Printf("Applied stack %q: %d created, %d updated, %d deleted, %d unchanged.\n",
+		stackName, len(plan.Create), len(plan.Update), len(plan.Delete), len(plan.Unchanged))
+
+	return nil
+}
+
+// getApplyStackName returns the name given by the flag, or the name of the
+// folder which holds the stack file.
+func getApplyStackName(flagValue, yamlFile string) (string, error) {
+	if len(flagValue) > 0 {
+		return flagValue, nil
+	}
+
+	if strings.Contains(yamlFile, "://") {
+		return "", fmt.Errorf("give a --stack-name when the stack file is fetched from a URL")
+	}
+
+	abs, err := filepath.Abs(yamlFile)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(filepath.Dir(abs)), nil
+}
+
+// applyNamespaces returns each namespace targeted by the functions in stack.yaml.
+func applyNamespaces(functions map[string]stack.Function) []string {
+	set := make(map[string]bool)
+	for _, fn := range functions {
+		set[getNamespace(functionNamespace, fn.Namespace)] = true
+	}
+	return sortedKeys(set)
+}
+
+// listStackFunctions lists the functions deployed in each namespace targeted by
+// stack.yaml, and in any other namespace holding a function owned by the stack,
+// so that functions are found for pruning after their namespace was dropped
+// from stack.yaml.
+func listStackFunctions(ctx context.Context, proxyClient *proxy.Client, functions map[string]stack.Function, stackName string) (map[string][]types.FunctionStatus, error) {
+	deployed := make(map[string][]types.FunctionStatus)
+
+	// The default namespace is listed as "", so the name reported for
+	// each function is recorded to avoid listing it twice
+	listed := make(map[string]bool)
+	for _, namespace := range applyNamespaces(functions) {
+		statuses, err := proxyClient.ListFunctions(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		deployed[namespace] = statuses
+
+		listed[namespace] = true
+		for _, status := range statuses {
+			listed[status.Namespace] = true
+		}
+	}
+
+	namespaces, err := proxyClient.ListNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A provider without namespaces only has the default namespace
+	if len(namespaces) == 0 {
+		namespaces = []string{getNamespace(functionNamespace, "")}
+	}
+
+	for _, namespace := range namespaces {
+		if listed[namespace] {
+			continue
+		}
+
+		statuses, err := proxyClient.ListFunctions(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, status := range statuses {
+			if mapFromPtr(status.Labels)[stackOwnerLabel] == stackName {
+				deployed[namespace] = statuses
+				break
+			}
+		}
+	}
+
+	return deployed, nil
+}
+
+// computeApplyPlan compares the functions in stack.yaml with those deployed in each
+// namespace and works out what must be created, updated and removed. A function
+// deployed with a digest is only updated when its tag points to a different image,
+// resolve may be nil when no function is deployed with a digest.
+func computeApplyPlan(functions map[string]stack.Function, deployed map[string][]types.FunctionStatus, stackName string, tagMode schema.BuildFormat, prune bool, resolve imageResolver) (applyPlan, error) {
+	plan := applyPlan{}
+
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inStack := make(map[string]bool)
+
+	for _, name := range names {
+		fn := functions[name]
+		namespace := getNamespace(functionNamespace, fn.Namespace)
+		inStack[diffKey(name, namespace)] = true
+
+		action := applyAction{
+			Name:      name,
+			Namespace: namespace,
+			Function:  fn,
+		}
+
+		status, found := findDeployedFunction(deployed[namespace], name)
+		if !found {
+			plan.Create = append(plan.Create, action)
+			continue
+		}
+
+		yamlF, err := stackFuncDiff(fn, tagMode)
+		if err != nil {
+			return plan, err
+		}
+
+		// The owner label is added at deploy time, so expect to see it.
+		labels := map[string]string{stackOwnerLabel: stackName}
+		for k, v := range yamlF.Labels {
+			labels[k] = v
+		}
+		yamlF.Labels = labels
+
+		deployedF := deployedFuncDiff(status)
+		if resolve != nil {
+			if _, err := diffImageDigests(&yamlF, &deployedF, resolve); err != nil {
+				return plan, fmt.Errorf("unable to compare the image of %s: %w", name, err)
+			}
+		}
+
+		rows, changed := buildSideBySide(yamlF, true, deployedF, true, diffEnvLocal)
+		if changed {
+			action.Rows = rows
+			plan.Update = append(plan.Update, action)
+		} else {
+			plan.Unchanged = append(plan.Unchanged, action)
+		}
+	}
+
+	namespaces := make([]string, 0, len(deployed))
+	for namespace := range deployed {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		statuses := deployed[namespace]
+		sort.Slice(statuses, func(i, j int) bool {
+			return statuses[i].Name < statuses[j].Name
+		})
+
+		for _, status := range statuses {
+			if inStack[diffKey(status.Name, namespace)] {
+				continue
+			}
+			if mapFromPtr(status.Labels)[stackOwnerLabel] != stackName {
+				continue
+			}
+
+			action := applyAction{
+				Name:      status.Name,
+				Namespace: namespace,
+			}
+
+			if prune {
+				plan.Delete = append(plan.Delete, action)
+			} else {
+				plan.Orphans = append(plan.Orphans, action)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func findDeployedFunction(functions []types.FunctionStatus, name string) (types.FunctionStatus, bool) {
+	for _, fn := range functions {
+		if fn.Name == name {
+			return fn, true
+		}
+	}
+	return types.FunctionStatus{}, false
+}
+
+// checkApplySecrets ensures that every secret used by a function which is
+// about to be created or updated exists in its namespace.
+func checkApplySecrets(ctx context.Context, client *proxy.Client, plan applyPlan) error {
+	required := make(map[string]map[string]bool)
+	for _, action := range append(plan.Create, plan.Update...) {
+		if len(action.Function.Secrets) == 0 {
+			continue
+		}
+		if required[action.Namespace] == nil {
+			required[action.Namespace] = make(map[string]bool)
+		}
+		for _, secret := range action.Function.Secrets {
+			required[action.Namespace][secret] = true
+		}
+	}
+
+	var missing []string
+	for namespace, secrets := range required {
+		existing, err := client.GetSecretList(ctx, namespace)
+		if err != nil {
+			return fmt.Errorf("unable to list secrets in namespace %q: %w", namespace, err)
+		}
+
+		found := make(map[string]bool)
+		for _, secret := range existing {
+			found[secret.Name] = true
+		}
+
+		for _, secret := range sortedKeys(secrets) {
+			if !found[secret] {
+				missing = append(missing, diffKey(secret, namespace))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("no changes were made, the following secrets are missing: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func printApplyPlan(stackName string, plan applyPlan) {
+	fmt.Printf("Plan for stack %q:\n", stackName)
+
+	for _, action := range plan.Create {
+		fmt.Printf("  + create     %s\n", diffKey(action.Name, action.Namespace))
+	}
+
+	for _, action := range plan.Update {
+		fields := make([]string, 0, len(action.Rows))
+		for _, row := range action.Rows {
+			if row.left.field != "" {
+				fields = append(fields, row.left.field)
+			} else {
+				fields = append(fields, row.right.field)
+			}
+		}
+		fmt.Printf("  ~ update     %s (%s)\n", diffKey(action.Name, action.Namespace), strings.Join(fields, ", "))
+	}
+
+	for _, action := range plan.Delete {
+		fmt.Printf("  - delete     %s\n", diffKey(action.Name, action.Namespace))
+	}
+
+	for _, action := range plan.Unchanged {
+		fmt.Printf("  = unchanged  %s\n", diffKey(action.Name, action.Namespace))
+	}
+
+	for _, action := range plan.Orphans {
+		fmt.Printf("  ! orphaned   %s (not in stack.yaml, use --prune to remove)\n", diffKey(action.Name, action.Namespace))
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,386 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/schema"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func Test_computeApplyPlan(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+	functionNamespace = ""
+
+	functions := map[string]stack.Function{
+		"new-fn": {
+			Image: "ttl.sh/test/new-fn:latest",
+		},
+		"same-fn": {
+			Image: "ttl.sh/test/same-fn:latest",
+		},
+		"changed-fn": {
+			Image: "ttl.sh/test/changed-fn:0.2.0",
+		},
+	}
+
+	owned := map[string]string{stackOwnerLabel: "shop"}
+	otherStack := map[string]string{stackOwnerLabel: "billing"}
+
+	deployed := map[string][]types.FunctionStatus{
+		"": {
+			{Name: "same-fn", Image: "ttl.sh/test/same-fn:latest", Labels: &owned},
+			{Name: "changed-fn", Image: "ttl.sh/test/changed-fn:0.1.0", Labels: &owned},
+			{Name: "removed-fn", Image: "ttl.sh/test/removed-fn:latest", Labels: &owned},
+			{Name: "other-stack-fn", Image: "ttl.sh/test/other:latest", Labels: &otherStack},
+			{Name: "manual-fn", Image: "ttl.sh/test/manual:latest"},
+		},
+	}
+
+	plan, err := computeApplyPlan(functions, deployed, "shop", schema.DefaultFormat, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertActionNames(t, "create", plan.Create, []string{"new-fn"})
+	assertActionNames(t, "update", plan.Update, []string{"changed-fn"})
+	assertActionNames(t, "unchanged", plan.Unchanged, []string{"same-fn"})
+	assertActionNames(t, "delete", plan.Delete, nil)
+	assertActionNames(t, "orphans", plan.Orphans, []string{"removed-fn"})
+
+	pruned, err := computeApplyPlan(functions, deployed, "shop", schema.DefaultFormat, true, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertActionNames(t, "delete", pruned.Delete, []string{"removed-fn"})
+	assertActionNames(t, "orphans", pruned.Orphans, nil)
+}
+
+func assertActionNames(t *testing.T, set string, actions []applyAction, want []string) {
+	t.Helper()
+
+	var got []string
+	for _, action := range actions {
+		got = append(got, action.Name)
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("%s: want %v, got %v", set, want, got)
+	}
+}
+
+func Test_getApplyStackName(t *testing.T) {
+	name, err := getApplyStackName("", filepath.Join("projects", "shop", "stack.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if name != "shop" {
+		t.Errorf("want stack name from folder: shop, got %q", name)
+	}
+
+	name, err = getApplyStackName("billing", "stack.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if name != "billing" {
+		t.Errorf("want stack name from flag: billing, got %q", name)
+	}
+
+	if _, err := getApplyStackName("", "https://example.com/stack.yaml"); err == nil {
+		t.Errorf("want error for a remote stack file without --stack-name")
+	}
+}
+
+func Test_apply_MissingSecretMakesNoChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	yamlContent := `version: 1.0
+provider:
+  name: openfaas
+functions:
+  myfunc:
+    image: ttl.sh/test/myfunc:latest
+    secrets:
+      - api-key
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/namespaces",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []string{},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/secrets",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.Secret{{Name: "db-password"}},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer resetForTest()
+
+	var executeErr error
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"apply",
+			"-f", yamlPath,
+			"--gateway=" + s.URL,
+		})
+		executeErr = faasCmd.Execute()
+	})
+
+	if executeErr == nil {
+		t.Fatal("want an error when a secret is missing")
+	}
+
+	if !strings.Contains(executeErr.Error(), "api-key") {
+		t.Errorf("want missing secret in error, got: %s", executeErr)
+	}
+}
+
+func Test_apply_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	yamlContent := `version: 1.0
+provider:
+  name: openfaas
+functions:
+  myfunc:
+    image: ttl.sh/test/myfunc:latest
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	owned := map[string]string{stackOwnerLabel: "shop"}
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "oldfunc", Namespace: "openfaas-fn", Image: "ttl.sh/test/oldfunc:latest", Labels: &owned},
+			},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/namespaces",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []string{"openfaas-fn"},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		applyDryRun = false
+		applyPrune = false
+		applyStackName = ""
+	}()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"apply",
+			"-f", yamlPath,
+			"--gateway=" + s.URL,
+			"--stack-name=shop",
+			"--prune",
+			"--dry-run",
+		})
+		executeErr = faasCmd.Execute()
+	})
+
+	if executeErr != nil {
+		t.Fatalf("unexpected error: %s", executeErr)
+	}
+
+	for _, want := range []string{"+ create     myfunc", "- delete     oldfunc", "Dry run"} {
+		if !strings.Contains(stdOut, want) {
+			t.Errorf("want %q in output, got:\n%s", want, stdOut)
+		}
+	}
+}
+
+func Test_apply_PruneDroppedNamespace(t *testing.T) {
+	t.Setenv("OPENFAAS_CONFIG", t.TempDir())
+
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	yamlContent := `version: 1.0
+provider:
+  name: openfaas
+functions:
+  api:
+    image: ttl.sh/test/api:latest
+    namespace: staging
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	owned := map[string]string{stackOwnerLabel: "shop"}
+	other := map[string]string{stackOwnerLabel: "billing"}
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=staging",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []types.FunctionStatus{},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/namespaces",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []string{"dev", "staging", "tools"},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=dev",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "worker", Namespace: "dev", Image: "ttl.sh/test/worker:latest", Labels: &owned},
+				{Name: "invoices", Namespace: "dev", Image: "ttl.sh/test/invoices:latest", Labels: &other},
+			},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=tools",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "figlet", Namespace: "tools", Image: "ghcr.io/openfaas/figlet:latest"},
+			},
+		},
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodDelete,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		applyPrune = false
+		applyStackName = ""
+	}()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"apply",
+			"-f", yamlPath,
+			"--gateway=" + s.URL,
+			"--stack-name=shop",
+			"--prune",
+		})
+		executeErr = faasCmd.Execute()
+	})
+
+	if executeErr != nil {
+		t.Fatalf("unexpected error: %s", executeErr)
+	}
+
+	for _, want := range []string{"+ create     api.staging", "- delete     worker.dev", "1 created, 0 updated, 1 deleted"} {
+		if !strings.Contains(stdOut, want) {
+			t.Errorf("want %q in output, got:\n%s", want, stdOut)
+		}
+	}
+	if strings.Contains(stdOut, "invoices") || strings.Contains(stdOut, "figlet") {
+		t.Errorf("want functions owned by other stacks left alone, got:\n%s", stdOut)
+	}
+}
+
+func Test_apply_PruneEmptyStack(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	yamlContent := `version: 1.0
+provider:
+  name: openfaas
+functions: {}
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	owned := map[string]string{stackOwnerLabel: "shop"}
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/namespaces",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []string{"openfaas-fn"},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=openfaas-fn",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody: []types.FunctionStatus{
+				{Name: "oldfunc", Namespace: "openfaas-fn", Image: "ttl.sh/test/oldfunc:latest", Labels: &owned},
+			},
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		applyDryRun = false
+		applyPrune = false
+		applyStackName = ""
+	}()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"apply",
+			"-f", yamlPath,
+			"--gateway=" + s.URL,
+			"--stack-name=shop",
+			"--prune",
+			"--dry-run",
+		})
+		executeErr = faasCmd.Execute()
+	})
+
+	if executeErr != nil {
+		t.Fatalf("unexpected error: %s", executeErr)
+	}
+
+	if want := "- delete     oldfunc.openfaas-fn"; !strings.Contains(stdOut, want) {
+		t.Errorf("want %q in output, got:\n%s", want, stdOut)
+	}
+}
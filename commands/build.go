@@ -4,12 +4,15 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/morikuni/aec"
@@ -39,6 +42,8 @@ var (
 	quietBuild       bool
 	disableStackPull bool
 	forcePull        bool
+	changedOnly      bool
+	buildExplain     bool
 )
 
 func init() {
@@ -62,6 +67,8 @@ func init() {
 	buildCmd.Flags().BoolVar(&quietBuild, "quiet", false, "Perform a quiet build, without showing output from Docker")
 	buildCmd.Flags().BoolVar(&disableStackPull, "disable-stack-pull", false, "Disables the template configuration in the stack.yaml")
 	buildCmd.Flags().BoolVar(&forcePull, "pull", false, "Force a re-pull of base images in template during build, useful for publishing images")
+	buildCmd.Flags().BoolVar(&changedOnly, "changed-only", false, "Skip functions whose handler, template, copy paths and build configuration are unchanged since the last successful build")
+	buildCmd.Flags().BoolVar(&buildExplain, "explain", false, "Explain why each function would or would not be rebuilt, without building")
 
 	buildCmd.Flags().BoolVar(&pullDebug, "debug", false, "Enable debug output when pulling templates")
 	buildCmd.Flags().BoolVar(&overwrite, "overwrite", true, "Overwrite existing templates from the template repository")
@@ -87,6 +94,8 @@ var buildCmd = &cobra.Command{
                  [--build-option VALUE]
                  [--copy-extra PATH]
                  [--tag <digest|sha|branch|describe>]
+                 [--changed-only]
+                 [--explain]
 				 [--forcePull]`,
 	Short: "Builds OpenFaaS function containers",
 	Long: `Builds OpenFaaS function containers either via the supplied YAML config using
@@ -98,6 +107,8 @@ via flags.`,
   faas-cli build --build-option dev
   faas-cli build --tag sha
   faas-cli build --parallel 4
+  faas-cli build --changed-only
+  faas-cli build --explain
   faas-cli build --filter "*gif*"
   faas-cli build --regex "fn[0-9]_.*"
   faas-cli build --image=my_image --lang=python --handler=/path/to/fn/ \
@@ -161,9 +172,16 @@ func parseBuildArgs(args []string) (map[string]string, error) {
 
 func runBuild(cmd *cobra.Command, args []string) error {
 
+	// The context is cancelled by --watch when a newer change arrives
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		stackRegex, stackFilter := stackFilters(cmd.Context())
+		parsedServices, err := parseYAMLFile(yamlFile, stackRegex, stackFilter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -219,7 +237,7 @@ func runBuild(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("please provide the deployed --name of your function")
 		}
 
-		if err := builder.BuildImage(image,
+		if err := builder.BuildImage(ctx, image,
 			handler,
 			functionName,
 			language,
@@ -244,7 +262,17 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	errors := build(&services, parallel, shrinkwrap, quietBuild)
+	if buildExplain {
+		return explainBuild(&services)
+	}
+
+	errors := build(ctx, &services, parallel, shrinkwrap, quietBuild)
+
+	// Functions which were not built must not be pushed or deployed
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if len(errors) > 0 {
 		errorSummary := "Errors received during build:\n"
 		for _, err := range errors {
@@ -256,10 +284,16 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool) []error {
+func build(ctx context.Context, services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool) []error {
 	startOuter := time.Now()
 
 	errors := []error{}
+	var errorsMu sync.Mutex
+
+	cache, err := builder.LoadBuildCache(builder.BuildCacheFile)
+	if err != nil {
+		return []error{err}
+	}
 
 	wg := sync.WaitGroup{}
 
@@ -269,16 +303,38 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 	for i := 0; i < queueDepth; i++ {
 		go func(index int) {
 			for function := range workChannel {
+				if ctx.Err() != nil {
+					fmt.Printf(aec.YellowF.Apply("[%d] > Skipping %s, build cancelled.\n"), index, function.Name)
+					continue
+				}
+
 				start := time.Now()
 
-				fmt.Printf(aec.YellowF.Apply("[%d] > Building %s.\n"), index, function.Name)
 				if len(function.Language) == 0 {
+					fmt.Printf(aec.YellowF.Apply("[%d] > Building %s.\n"), index, function.Name)
 					fmt.Println("Please provide a valid language for your function.")
 				} else {
 					combinedBuildOptions := combineBuildOpts(function.BuildOptions, buildOptions)
 					combinedBuildArgMap := util.MergeMap(function.BuildArgs, buildArgMap)
 					combinedExtraPaths := util.MergeSlice(services.StackConfiguration.CopyExtraPaths, copyExtra)
-					err := builder.BuildImage(function.Image,
+
+					inputs, err := functionBuildInputs(function, combinedBuildArgMap, combinedBuildOptions, combinedExtraPaths)
+					if err != nil {
+						errorsMu.Lock()
+						errors = append(errors, err)
+						errorsMu.Unlock()
+						continue
+					}
+
+					if changedOnly {
+						if changed, _ := cache.Changed(function.Name, inputs); !changed {
+							fmt.Printf(aec.YellowF.Apply("[%d] > Skipping %s, unchanged since last build.\n"), index, function.Name)
+							continue
+						}
+					}
+
+					fmt.Printf(aec.YellowF.Apply("[%d] > Building %s.\n"), index, function.Name)
+					err = builder.BuildImage(ctx, function.Image,
 						function.Handler,
 						function.Name,
 						function.Language,
@@ -298,8 +354,16 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 						forcePull,
 					)
 
-					if err != nil {
+					// A build stopped by --watch for a newer change has not failed
+					if err != nil && ctx.Err() != nil {
+						fmt.Printf(aec.YellowF.Apply("[%d] > Build of %s cancelled.\n"), index, function.Name)
+						continue
+					} else if err != nil {
+						errorsMu.Lock()
 						errors = append(errors, err)
+						errorsMu.Unlock()
+					} else if !shrinkwrap {
+						cache.Record(function.Name, function.Image, inputs)
 					}
 				}
 
@@ -326,11 +390,86 @@ func build(services *stack.Services, queueDepth int, shrinkwrap, quietBuild bool
 
 	wg.Wait()
 
+	if err := cache.Save(); err != nil {
+		errors = append(errors, fmt.Errorf("unable to save build cache: %w", err))
+	}
+
 	duration := time.Since(startOuter)
 	fmt.Printf("\n%s\n", aec.Apply(fmt.Sprintf("Total build time: %1.2fs", duration.Seconds()), aec.YellowF))
 	return errors
 }
 
+// explainBuild prints whether each function would be rebuilt and why,
+// without building anything.
+func explainBuild(services *stack.Services) error {
+	cache, err := builder.LoadBuildCache(builder.BuildCacheFile)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(services.Functions))
+	for name := range services.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.TabIndent)
+	fmt.Fprintln(w, "FUNCTION\tREBUILD\tREASON")
+
+	for _, name := range names {
+		function := services.Functions[name]
+		function.Name = name
+
+		if function.SkipBuild {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, "no", "skip_build is set")
+			continue
+		}
+
+		combinedBuildOptions := combineBuildOpts(function.BuildOptions, buildOptions)
+		combinedBuildArgMap := util.MergeMap(function.BuildArgs, buildArgMap)
+		combinedExtraPaths := util.MergeSlice(services.StackConfiguration.CopyExtraPaths, copyExtra)
+
+		inputs, err := functionBuildInputs(function, combinedBuildArgMap, combinedBuildOptions, combinedExtraPaths)
+		if err != nil {
+			return err
+		}
+
+		changed, reasons := cache.Changed(name, inputs)
+		if !changed {
+			entry, _ := cache.Get(name)
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, "no", fmt.Sprintf("unchanged since %s", entry.Built.Local().Format(time.RFC3339)))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, "yes", strings.Join(reasons, ", "))
+	}
+
+	return w.Flush()
+}
+
+// functionBuildInputs hashes the files and configuration that go into a
+// function's build, for comparison with the last successful build.
+func functionBuildInputs(function stack.Function, buildArgMap map[string]string, buildOptions, extraPaths []string) (map[string]string, error) {
+	config := map[string]string{
+		"image":         function.Image,
+		"lang":          function.Language,
+		"tag":           tagFormat.String(),
+		"build_options": strings.Join(buildOptions, ","),
+		"squash":        fmt.Sprintf("%t", squash),
+	}
+	for k, v := range buildArgMap {
+		config["build_arg."+k] = v
+	}
+	for k, v := range buildLabelMap {
+		config["build_label."+k] = v
+	}
+	for k, v := range function.BuildSecrets {
+		config["build_secret."+k] = v
+	}
+
+	return builder.FunctionInputs(function.Handler, function.Language, extraPaths, config)
+}
+
 // pullTemplates pulls templates from specified git remote. templateURL may be a pinned repository.
 func pullTemplates(templateURL, templateName string) error {
 	cwd, err := os.Getwd()
@@ -345,7 +484,7 @@ func pullTemplates(templateURL, templateName string) error {
 			fmt.Printf("No templates found in current directory.\n")
 
 			templateURL, refName := versioncontrol.ParsePinnedRemote(templateURL)
-			if err := fetchTemplates(templateURL, refName, templateName, overwrite); err != nil {
+			if err := fetchTemplates(templateURL, refName, templateName, overwrite, lockIfExists); err != nil {
 				return err
 			}
 		} else {
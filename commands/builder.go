@@ -0,0 +1,18 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	faasCmd.AddCommand(builderCmd)
+}
+
+var builderCmd = &cobra.Command{
+	Use:   `builder`,
+	Short: "Function Builder API commands",
+	Long:  "Run a local stand-in for the Function Builder API used by --remote-builder",
+}
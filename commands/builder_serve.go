@@ -0,0 +1,142 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/spf13/cobra"
+)
+
+var (
+	builderServePort          int
+	builderServePayloadSecret string
+	builderServeKey           string
+	builderServeSimulate      bool
+)
+
+func init() {
+	builderServeCmd.Flags().IntVarP(&builderServePort, "port", "p", 8081, "Port to listen on")
+	builderServeCmd.Flags().StringVar(&builderServePayloadSecret, "payload-secret", "", "Path to the payload secret used to validate the signature of each build")
+	builderServeCmd.Flags().StringVar(&builderServeKey, "key", "", "Path to the private key from \"faas-cli secret keygen\" to unseal build secrets, the public key is read from the same path with .pub appended")
+	builderServeCmd.Flags().BoolVar(&builderServeSimulate, "simulate", false, "Stream the steps of each build without running docker buildx")
+
+	builderCmd.AddCommand(builderServeCmd)
+}
+
+var builderServeCmd = &cobra.Command{
+	Use:   `serve --payload-secret PATH [--port PORT] [--key PATH] [--simulate]`,
+	Short: "Serve a local stand-in for the Function Builder API",
+	Long: `Serve a local stand-in for the Function Builder API, so that --remote-builder
+can be tested without a pro-builder.
+
+Each build must be signed with the payload secret. Build secrets are unsealed
+with the keypair from "faas-cli secret keygen", whose public key is served at
+/publickey. Images are built and pushed with docker buildx, or with --simulate
+the build is only reported.`,
+	Example: `  faas-cli secret keygen
+  faas-cli builder serve --payload-secret ./payload.txt --key ./key --simulate
+
+  faas-cli publish --remote-builder http://127.0.0.1:8081 \
+    --payload-secret ./payload.txt`,
+	PreRunE: preRunBuilderServe,
+	RunE:    runBuilderServe,
+}
+
+func preRunBuilderServe(cmd *cobra.Command, args []string) error {
+	if len(builderServePayloadSecret) == 0 {
+		return fmt.Errorf("give a path to the payload secret with --payload-secret")
+	}
+
+	if builderServePort < 1 || builderServePort > 65535 {
+		return fmt.Errorf("--port must be between 1 and 65535")
+	}
+
+	return nil
+}
+
+func runBuilderServe(cmd *cobra.Command, args []string) error {
+	server, err := newLocalBuilderServer(builderServePayloadSecret, builderServeKey, builderServeSimulate)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", builderServePort))
+	if err != nil {
+		return fmt.Errorf("unable to listen on port %d: %w", builderServePort, err)
+	}
+
+	s := &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	mode := "docker buildx"
+	if server.Simulate {
+		mode = "simulated builds"
+	}
+	log.Printf("[Builder] listening on http://127.0.0.1:%d with %s", builderServePort, mode)
+	if len(server.PublicKey) > 0 {
+		log.Printf("[Builder] build secrets enabled, public key at /publickey")
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.Shutdown(ctx)
+	}()
+
+	if err := s.Serve(ln); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// newLocalBuilderServer reads the payload secret and the optional keypair
+// for build secrets.
+func newLocalBuilderServer(payloadSecretPath, keyPath string, simulate bool) (*builder.LocalServer, error) {
+	payloadSecret, err := os.ReadFile(payloadSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read payload secret: %w", err)
+	}
+	payloadSecret = bytes.TrimSpace(payloadSecret)
+	if len(payloadSecret) == 0 {
+		return nil, fmt.Errorf("payload secret %s is empty", payloadSecretPath)
+	}
+
+	server := &builder.LocalServer{
+		PayloadSecret: payloadSecret,
+		Simulate:      simulate,
+	}
+
+	if len(keyPath) > 0 {
+		privateKey, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read private key: %w", err)
+		}
+
+		publicKey, err := os.ReadFile(keyPath + ".pub")
+		if err != nil {
+			return nil, fmt.Errorf("unable to read public key: %w", err)
+		}
+
+		server.PrivateKey = bytes.TrimSpace(privateKey)
+		server.PublicKey = bytes.TrimSpace(publicKey)
+	}
+
+	return server, nil
+}
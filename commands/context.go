@@ -0,0 +1,81 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// contextOverride selects a context for a single command via --context
+	contextOverride string
+
+	// activeContext is the context in use for the current command, if any
+	activeContext *config.Context
+
+	// namespaceFromContext is set when the --namespace flag was populated
+	// from the active context rather than given by the user
+	namespaceFromContext bool
+)
+
+func init() {
+	faasCmd.PersistentFlags().StringVar(&contextOverride, "context", "", "Name of the context to use instead of the current context")
+	faasCmd.PersistentPreRunE = applyContext
+
+	faasCmd.AddCommand(contextCmd)
+}
+
+var contextCmd = &cobra.Command{
+	Use:   `context`,
+	Short: "Manage named gateway contexts",
+	Long: `Manage named contexts, each holding a gateway URL, a default namespace and
+whether to skip TLS validation. The current context is used whenever
+--gateway, the stack file and OPENFAAS_URL do not give a gateway. Credentials
+for a context's gateway are stored with "faas-cli login" as usual.`,
+	Example: `  faas-cli context create prod --gateway https://openfaas.example.com --namespace prod-fn
+  faas-cli context use prod
+  faas-cli context list
+  faas-cli context current
+  faas-cli context delete prod`,
+}
+
+// applyContext runs before every command and applies the active context's
+// namespace and TLS setting to any of those flags not given by the user.
+func applyContext(cmd *cobra.Command, args []string) error {
+	activeContext = nil
+	namespaceFromContext = false
+
+	if cmd == contextCmd || cmd.Parent() == contextCmd {
+		return nil
+	}
+
+	context, err := config.ActiveContext(contextOverride)
+	if err != nil {
+		return err
+	}
+	if context == nil {
+		return nil
+	}
+	activeContext = context
+
+	if len(context.Namespace) > 0 {
+		if f := cmd.Flags().Lookup("namespace"); f != nil && !f.Changed && len(f.Value.String()) == 0 {
+			if err := f.Value.Set(context.Namespace); err != nil {
+				return err
+			}
+			namespaceFromContext = true
+		}
+	}
+
+	if context.TLSInsecure {
+		if f := cmd.Flags().Lookup("tls-no-verify"); f != nil && !f.Changed {
+			if err := f.Value.Set("true"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextGateway     string
+	contextNamespace   string
+	contextTLSInsecure bool
+	contextUse         bool
+)
+
+var contextCreateCmd = &cobra.Command{
+	Use:     `create NAME --gateway GATEWAY_URL [--namespace NAMESPACE] [--tls-no-verify] [--use]`,
+	Short:   "Create or update a context",
+	Long:    "Create a named context, or update it if it already exists",
+	Example: `  faas-cli context create prod --gateway https://openfaas.example.com --namespace prod-fn --use`,
+	Aliases: []string{"set"},
+	PreRunE: preCreateContext,
+	RunE:    createContext,
+}
+
+func init() {
+	contextCreateCmd.Flags().StringVarP(&contextGateway, "gateway", "g", "", "Gateway URL starting with http(s)://")
+	contextCreateCmd.Flags().StringVarP(&contextNamespace, "namespace", "n", "", "Default namespace for functions")
+	contextCreateCmd.Flags().BoolVar(&contextTLSInsecure, "tls-no-verify", false, "Disable TLS validation")
+	contextCreateCmd.Flags().BoolVar(&contextUse, "use", false, "Make this the current context")
+
+	contextCmd.AddCommand(contextCreateCmd)
+}
+
+func preCreateContext(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("context name required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("too many values for context name")
+	}
+
+	if len(contextGateway) == 0 {
+		return fmt.Errorf("--gateway is required")
+	}
+
+	return nil
+}
+
+func createContext(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	err := config.SetContext(config.Context{
+		Name:        name,
+		Gateway:     getGatewayURL(contextGateway, defaultGateway, "", ""),
+		Namespace:   contextNamespace,
+		TLSInsecure: contextTLSInsecure,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Context %s saved\n", name)
+
+	if contextUse {
+		if err := config.UseContext(name); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to context %s\n", name)
+	}
+
+	return nil
+}
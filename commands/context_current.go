@@ -0,0 +1,37 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var contextCurrentCmd = &cobra.Command{
+	Use:     `current`,
+	Short:   "Print the current context",
+	Long:    "Print the name of the context in use, taking --context and OPENFAAS_CONTEXT into account",
+	Example: `  faas-cli context current`,
+	RunE:    currentContext,
+}
+
+func init() {
+	contextCmd.AddCommand(contextCurrentCmd)
+}
+
+func currentContext(cmd *cobra.Command, args []string) error {
+	context, err := config.ActiveContext(contextOverride)
+	if err != nil {
+		return err
+	}
+
+	if context == nil {
+		return fmt.Errorf("no current context set")
+	}
+
+	fmt.Println(context.Name)
+	return nil
+}
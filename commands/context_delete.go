@@ -0,0 +1,46 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var contextDeleteCmd = &cobra.Command{
+	Use:     `delete NAME`,
+	Short:   "Delete a context",
+	Long:    "Delete a context, credentials stored for its gateway are kept",
+	Example: `  faas-cli context delete prod`,
+	Aliases: []string{"rm", "remove"},
+	PreRunE: preDeleteContext,
+	RunE:    deleteContext,
+}
+
+func init() {
+	contextCmd.AddCommand(contextDeleteCmd)
+}
+
+func preDeleteContext(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("context name required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("too many values for context name")
+	}
+
+	return nil
+}
+
+func deleteContext(cmd *cobra.Command, args []string) error {
+	if err := config.RemoveContext(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Context %s deleted\n", args[0])
+	return nil
+}
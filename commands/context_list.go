@@ -0,0 +1,50 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var contextListCmd = &cobra.Command{
+	Use:     `list`,
+	Short:   "List contexts",
+	Long:    "List contexts, the current context is marked with *",
+	Example: `  faas-cli context list`,
+	Aliases: []string{"ls"},
+	RunE:    listContexts,
+}
+
+func init() {
+	contextCmd.AddCommand(contextListCmd)
+}
+
+func listContexts(cmd *cobra.Command, args []string) error {
+	contexts, current, err := config.ListContexts()
+	if err != nil {
+		return err
+	}
+
+	if len(contexts) == 0 {
+		fmt.Println("No contexts found, create one with: faas-cli context create")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tGATEWAY\tNAMESPACE\tTLS-NO-VERIFY")
+	for _, c := range contexts {
+		marker := ""
+		if c.Name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", marker, c.Name, c.Gateway, c.Namespace, c.TLSInsecure)
+	}
+
+	return w.Flush()
+}
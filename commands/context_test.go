@@ -0,0 +1,116 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_getGatewayURL_activeContext(t *testing.T) {
+	defer resetForTest()
+
+	activeContext = &config.Context{Name: "prod", Gateway: "https://prod.example.com"}
+
+	cases := []struct {
+		name     string
+		flag     string
+		yaml     string
+		env      string
+		expected string
+	}{
+		{name: "context when nothing else is given", expected: "https://prod.example.com"},
+		{name: "flag over context", flag: "http://flag:8080", expected: "http://flag:8080"},
+		{name: "yaml over context", yaml: "http://yaml:8080", expected: "http://yaml:8080"},
+		{name: "env over context", env: "http://env:8080", expected: "http://env:8080"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getGatewayURL(tc.flag, defaultGateway, tc.yaml, tc.env)
+			if got != tc.expected {
+				t.Errorf("want %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_getNamespace_fromContext(t *testing.T) {
+	defer resetForTest()
+
+	namespaceFromContext = true
+
+	if got := getNamespace("prod-fn", "stack-fn"); got != "stack-fn" {
+		t.Errorf("want the stack namespace over the context, got %q", got)
+	}
+
+	if got := getNamespace("prod-fn", ""); got != "prod-fn" {
+		t.Errorf("want the context namespace, got %q", got)
+	}
+
+	namespaceFromContext = false
+	if got := getNamespace("flag-fn", "stack-fn"); got != "flag-fn" {
+		t.Errorf("want the flag over the stack namespace, got %q", got)
+	}
+}
+
+func Test_context_usedByCommands(t *testing.T) {
+	defer resetForTest()
+	defer func() {
+		functionNamespace = ""
+		gateway = defaultGateway
+	}()
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+	t.Setenv(config.ContextEnv, "")
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions?namespace=staging-fn",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       []map[string]string{},
+		},
+	})
+	defer s.Close()
+
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"context", "create", "staging", "--gateway=" + s.URL, "--namespace=staging-fn", "--use"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"context", "list"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(stdOut, "*") || !strings.Contains(stdOut, "staging-fn") {
+		t.Errorf("want staging to be the current context, got:\n%s", stdOut)
+	}
+
+	gateway = defaultGateway
+	functionNamespace = ""
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"list"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{"context", "current"})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if strings.TrimSpace(stdOut) != "staging" {
+		t.Errorf("want current context staging, got %q", stdOut)
+	}
+}
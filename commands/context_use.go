@@ -0,0 +1,45 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/spf13/cobra"
+)
+
+var contextUseCmd = &cobra.Command{
+	Use:     `use NAME`,
+	Short:   "Set the current context",
+	Long:    "Set the current context, used by commands which are not given a gateway",
+	Example: `  faas-cli context use prod`,
+	PreRunE: preUseContext,
+	RunE:    useContext,
+}
+
+func init() {
+	contextCmd.AddCommand(contextUseCmd)
+}
+
+func preUseContext(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("context name required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("too many values for context name")
+	}
+
+	return nil
+}
+
+func useContext(cmd *cobra.Command, args []string) error {
+	if err := config.UseContext(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to context %s\n", args[0])
+	return nil
+}
@@ -37,6 +37,10 @@ type DeployFlags struct {
 	secrets                []string
 	labelOpts              []string
 	annotationOpts         []string
+	verifySignature        bool
+	signatureKey           string
+	pinDigest              bool
+	verifyManifest         string
 }
 
 var deployFlags DeployFlags
@@ -82,6 +86,17 @@ func init() {
 	deployCmd.Flags().StringVar(&memoryRequest, "memory-request", "", "Supply the memory request for the function in Mi (when not using a YAML file)")
 	deployCmd.Flags().StringVar(&memoryLimit, "memory-limit", "", "Supply the memory limit for the function in Mi (when not using a YAML file)")
 
+	deployCmd.Flags().StringVar(&deployStrategy, "strategy", deployStrategyRolling, "Rollout strategy: rolling updates in place, canary deploys and checks <name>-canary before promoting")
+	deployCmd.Flags().IntVar(&canaryAttempts, "canary-attempts", 60, "Number of attempts to check the canary is ready")
+	deployCmd.Flags().DurationVar(&canaryInterval, "canary-interval", time.Second*1, "Interval between checks of the canary")
+	deployCmd.Flags().StringVar(&canarySmokePath, "smoke-path", "", "Path to invoke on the canary, a non-2xx response rolls back the deployment")
+
+	deployCmd.Flags().BoolVar(&deployFlags.verifySignature, "verify-signature", false, "Refuse to deploy unless each image has a cosign signature made by --signature-key")
+	deployCmd.Flags().StringVar(&deployFlags.signatureKey, "signature-key", "", "Path to the PEM public key used to verify image signatures, overrides public_key in the stack.yaml policy")
+	deployCmd.Flags().BoolVar(&deployFlags.pinDigest, "pin-digest", false, "Resolve each image to its digest in the registry and deploy image@sha256:...")
+	deployCmd.Flags().StringVar(&deployFlags.verifyManifest, "verify-manifest", "", "Refuse to deploy unless each image digest and template matches the manifest written by faas-cli publish")
+	deployCmd.Flags().Lookup("verify-manifest").NoOptDefVal = builder.PublishManifestFile
+
 	faasCmd.AddCommand(deployCmd)
 }
 
@@ -106,6 +121,9 @@ var deployCmd = &cobra.Command{
 				  [--secret "SECRET_NAME"]
 				  [--tag <sha|branch|describe>]
 				  [--readonly=false]
+				  [--strategy <rolling|canary>]
+				  [--verify-signature --signature-key cosign.pub]
+				  [--verify-manifest [PATH]]
 				  [--tls-no-verify]`,
 
 	Short: "Deploy OpenFaaS functions",
@@ -123,6 +141,10 @@ via flags. Note: --replace and --update are mutually exclusive.`,
   faas-cli deploy -f stack.yaml --tag sha
   faas-cli deploy -f stack.yaml --tag branch
   faas-cli deploy -f stack.yaml --tag describe
+  faas-cli deploy -f stack.yaml --strategy canary --smoke-path /healthz
+  faas-cli deploy -f stack.yaml --verify-signature --signature-key cosign.pub
+  faas-cli deploy -f stack.yaml --pin-digest
+  faas-cli deploy -f stack.yaml --verify-manifest
   faas-cli deploy --image=alexellis/faas-url-ping --name=url-ping
   faas-cli deploy --image=my_image --name=my_fn --handler=/path/to/fn/
                   --gateway=http://remote-site.com:8080 --lang=python
@@ -135,14 +157,22 @@ via flags. Note: --replace and --update are mutually exclusive.`,
 func preRunDeploy(cmd *cobra.Command, args []string) error {
 	language, _ = validateLanguageFlag(language)
 
+	if err := validDeployStrategy(deployStrategy); err != nil {
+		return err
+	}
+
+	if canaryAttempts < 1 {
+		return fmt.Errorf("--canary-attempts must be greater than 0")
+	}
+
 	return nil
 }
 
 func runDeploy(cmd *cobra.Command, args []string) error {
-	return runDeployCommand(args, image, fprocess, functionName, deployFlags, tagFormat)
+	return runDeployCommand(cmd.Context(), args, image, fprocess, functionName, deployFlags, tagFormat)
 }
 
-func runDeployCommand(args []string, image string, fprocess string, functionName string, deployFlags DeployFlags, tagMode schema.BuildFormat) error {
+func runDeployCommand(cmdCtx context.Context, args []string, image string, fprocess string, functionName string, deployFlags DeployFlags, tagMode schema.BuildFormat) error {
 	if deployFlags.update && deployFlags.replace {
 		fmt.Println(`Cannot specify --update and --replace at the same time. One of --update or --replace must be false.
   --replace    removes an existing deployment before re-creating it
@@ -152,7 +182,8 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		stackRegex, stackFilter := stackFilters(cmdCtx)
+		parsedServices, err := parseYAMLFile(yamlFile, stackRegex, stackFilter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -166,10 +197,23 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 	transport := GetDefaultCLITransport(tlsInsecure, &timeoutOverride)
 	ctx := context.Background()
 
+	var policy signaturePolicy
+	if len(services.Functions) > 0 {
+		var err error
+		if policy, err = readSignaturePolicy(yamlFile); err != nil {
+			return err
+		}
+	}
+
+	verifier, err := newImageVerifier(ctx, deployFlags, policy)
+	if err != nil {
+		return err
+	}
+
 	var failedStatusCodes = make(map[string]int)
 	if len(services.Functions) > 0 {
 
-		cliAuth, err := proxy.NewCLIAuth(token, services.Provider.GatewayURL)
+		cliAuth, err := proxy.NewCLIAuth(token, services.Provider.GatewayURL, tlsInsecure)
 		if err != nil {
 			return err
 		}
@@ -179,114 +223,79 @@ func runDeployCommand(args []string, image string, fprocess string, functionName
 			return err
 		}
 
+		deploySpecs := make(map[string]*proxy.DeployFunctionSpec, len(services.Functions))
 		for k, function := range services.Functions {
-
-			functionSecrets := deployFlags.secrets
-
 			function.Name = k
-			fmt.Printf("Deploying: %s.\n", function.Name)
 
-			var functionConstraints []string
-			if function.Constraints != nil {
-				functionConstraints = *function.Constraints
-			} else if len(deployFlags.constraints) > 0 {
-				functionConstraints = deployFlags.constraints
-			}
-
-			if len(function.Secrets) > 0 {
-				functionSecrets = util.MergeSlice(function.Secrets, functionSecrets)
+			deploySpec, err := buildDeploySpec(function, deployFlags, tagMode)
+			if err != nil {
+				return err
 			}
+			deploySpecs[k] = deploySpec
+		}
 
-			// Check if there is a functionNamespace flag passed, if so, override the namespace value
-			// defined in the stack.yaml
-			function.Namespace = getNamespace(functionNamespace, function.Namespace)
-
-			fileEnvironment, err := readFiles(function.EnvironmentFile)
+		// The digest recorded by faas-cli publish is deployed, once it is
+		// confirmed to be what the registry serves for each image
+		if len(deployFlags.verifyManifest) > 0 {
+			manifest, err := readPublishManifest(deployFlags.verifyManifest)
 			if err != nil {
 				return err
 			}
-
-			labelMap := map[string]string{}
-			if function.Labels != nil {
-				labelMap = *function.Labels
+			images := make(map[string]string, len(deploySpecs))
+			for k, deploySpec := range deploySpecs {
+				images[k] = deploySpec.Image
 			}
-
-			labelArgumentMap, labelErr := util.ParseMap(deployFlags.labelOpts, "label")
-			if labelErr != nil {
-				return fmt.Errorf("error parsing labels: %v", labelErr)
+			verified, err := verifyManifestImages(manifest, newImageResolver(ctx), services.Functions, images)
+			if err != nil {
+				return err
 			}
-
-			allLabels := util.MergeMap(labelMap, labelArgumentMap)
-
-			allEnvironment, envErr := compileEnvironment(deployFlags.envvarOpts, function.Environment, fileEnvironment)
-			if envErr != nil {
-				return envErr
+			for k, deploySpec := range deploySpecs {
+				deploySpec.Image = verified[k]
 			}
+		}
 
-			if readTemplate {
-				// Get FProcess to use from the ./template/template.yml, if a template is being used
-				if languageExistsNotDockerfile(function.Language) {
-					var fprocessErr error
-
-					function.FProcess, fprocessErr = deriveFprocess(function)
-					if fprocessErr != nil {
-						return fmt.Errorf(`template directory may be missing or invalid, please run "faas-cli template pull"
-Error: %s`, fprocessErr.Error())
-					}
-				}
+		// Images are pinned before they are verified, so that the
+		// digest which is verified is the one which gets deployed
+		if deployFlags.pinDigest {
+			images := make(map[string]string, len(deploySpecs))
+			for k, deploySpec := range deploySpecs {
+				images[k] = deploySpec.Image
 			}
-
-			functionResourceRequest := proxy.FunctionResourceRequest{
-				Limits:   function.Limits,
-				Requests: function.Requests,
+			pinned, err := pinImages(newImageResolver(ctx), images)
+			if err != nil {
+				return err
 			}
-
-			var annotations map[string]string
-			if function.Annotations != nil {
-				annotations = *function.Annotations
+			for k, deploySpec := range deploySpecs {
+				deploySpec.Image = pinned[k]
 			}
+		}
 
-			annotationArgs, annotationErr := util.ParseMap(deployFlags.annotationOpts, "annotation")
-			if annotationErr != nil {
-				return fmt.Errorf("error parsing annotations: %v", annotationErr)
+		// No function is deployed unless every image is verified, then
+		// the digest which was verified is deployed
+		if verifier != nil {
+			images := make(map[string]string, len(deploySpecs))
+			for k, deploySpec := range deploySpecs {
+				images[k] = deploySpec.Image
 			}
-
-			allAnnotations := util.MergeMap(annotations, annotationArgs)
-
-			branch, sha, err := builder.GetImageTagValues(tagMode, function.Handler)
+			verified, err := verifyImages(verifier, images)
 			if err != nil {
 				return err
 			}
-
-			function.Image = schema.BuildImageName(tagMode, function.Image, sha, branch)
-
-			if deployFlags.readOnlyRootFilesystem {
-				function.ReadOnlyRootFilesystem = deployFlags.readOnlyRootFilesystem
+			for k, deploySpec := range deploySpecs {
+				deploySpec.Image = verified[k]
 			}
+		}
 
-			deploySpec := &proxy.DeployFunctionSpec{
-				FProcess:                function.FProcess,
-				FunctionName:            function.Name,
-				Image:                   function.Image,
-				Language:                function.Language,
-				Replace:                 deployFlags.replace,
-				EnvVars:                 allEnvironment,
-				Constraints:             functionConstraints,
-				Update:                  deployFlags.update,
-				Secrets:                 functionSecrets,
-				Labels:                  allLabels,
-				Annotations:             allAnnotations,
-				FunctionResourceRequest: functionResourceRequest,
-				ReadOnlyRootFilesystem:  function.ReadOnlyRootFilesystem,
-				TLSInsecure:             tlsInsecure,
-				Token:                   token,
-				Namespace:               function.Namespace,
-			}
+		for k, deploySpec := range deploySpecs {
+			fmt.Printf("Deploying: %s.\n", k)
 
 			if msg := checkTLSInsecure(services.Provider.GatewayURL, deploySpec.TLSInsecure); len(msg) > 0 {
 				fmt.Println(msg)
 			}
-			statusCode := proxyClient.DeployFunction(ctx, deploySpec)
+			statusCode, err := deployWithStrategy(ctx, proxyClient, deploySpec)
+			if err != nil {
+				return err
+			}
 			if badStatusCode(statusCode) {
 				failedStatusCodes[k] = statusCode
 			}
@@ -296,8 +305,28 @@ Error: %s`, fprocessErr.Error())
 			return fmt.Errorf("to deploy a function give --yaml/-f or a --image and --name flag")
 		}
 
+		if len(deployFlags.verifyManifest) > 0 {
+			return fmt.Errorf("--verify-manifest requires a stack.yaml file given via --yaml/-f")
+		}
+
+		if deployFlags.pinDigest {
+			pinned, err := pinImages(newImageResolver(ctx), map[string]string{functionName: image})
+			if err != nil {
+				return err
+			}
+			image = pinned[functionName]
+		}
+
+		if verifier != nil {
+			verified, err := verifyImages(verifier, map[string]string{functionName: image})
+			if err != nil {
+				return err
+			}
+			image = verified[functionName]
+		}
+
 		gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
-		cliAuth, err := proxy.NewCLIAuth(token, gateway)
+		cliAuth, err := proxy.NewCLIAuth(token, gateway, tlsInsecure)
 		if err != nil {
 			return err
 		}
@@ -340,6 +369,111 @@ Error: %s`, fprocessErr.Error())
 	return nil
 }
 
+// buildDeploySpec creates the deployment request for a function from the
+// stack.yaml file, merging in any values given via flags.
+func buildDeploySpec(function stack.Function, deployFlags DeployFlags, tagMode schema.BuildFormat) (*proxy.DeployFunctionSpec, error) {
+	functionSecrets := deployFlags.secrets
+
+	var functionConstraints []string
+	if function.Constraints != nil {
+		functionConstraints = *function.Constraints
+	} else if len(deployFlags.constraints) > 0 {
+		functionConstraints = deployFlags.constraints
+	}
+
+	if len(function.Secrets) > 0 {
+		functionSecrets = util.MergeSlice(function.Secrets, functionSecrets)
+	}
+
+	// Check if there is a functionNamespace flag passed, if so, override the namespace value
+	// defined in the stack.yaml
+	function.Namespace = getNamespace(functionNamespace, function.Namespace)
+
+	fileEnvironment, err := readFiles(function.EnvironmentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	labelMap := map[string]string{}
+	if function.Labels != nil {
+		labelMap = *function.Labels
+	}
+
+	labelArgumentMap, labelErr := util.ParseMap(deployFlags.labelOpts, "label")
+	if labelErr != nil {
+		return nil, fmt.Errorf("error parsing labels: %v", labelErr)
+	}
+
+	allLabels := util.MergeMap(labelMap, labelArgumentMap)
+
+	allEnvironment, envErr := compileEnvironment(deployFlags.envvarOpts, function.Environment, fileEnvironment)
+	if envErr != nil {
+		return nil, envErr
+	}
+
+	if readTemplate {
+		// Get FProcess to use from the ./template/template.yml, if a template is being used
+		if languageExistsNotDockerfile(function.Language) {
+			var fprocessErr error
+
+			function.FProcess, fprocessErr = deriveFprocess(function)
+			if fprocessErr != nil {
+				return nil, fmt.Errorf(`template directory may be missing or invalid, please run "faas-cli template pull"
+Error: %s`, fprocessErr.Error())
+			}
+		}
+	}
+
+	functionResourceRequest := proxy.FunctionResourceRequest{
+		Limits:   function.Limits,
+		Requests: function.Requests,
+	}
+
+	var annotations map[string]string
+	if function.Annotations != nil {
+		annotations = *function.Annotations
+	}
+
+	annotationArgs, annotationErr := util.ParseMap(deployFlags.annotationOpts, "annotation")
+	if annotationErr != nil {
+		return nil, fmt.Errorf("error parsing annotations: %v", annotationErr)
+	}
+
+	allAnnotations := util.MergeMap(annotations, annotationArgs)
+
+	branch, sha, err := builder.GetImageTagValues(tagMode, function.Handler)
+	if err != nil {
+		return nil, err
+	}
+
+	function.Image = schema.BuildImageName(tagMode, function.Image, sha, branch)
+
+	if deployFlags.readOnlyRootFilesystem {
+		function.ReadOnlyRootFilesystem = deployFlags.readOnlyRootFilesystem
+	}
+
+	deploySpec := &proxy.DeployFunctionSpec{
+		FProcess:                function.FProcess,
+		FunctionName:            function.Name,
+		Image:                   function.Image,
+		Language:                function.Language,
+		Replace:                 deployFlags.replace,
+		EnvVars:                 allEnvironment,
+		Constraints:             functionConstraints,
+		Update:                  deployFlags.update,
+		Secrets:                 functionSecrets,
+		Labels:                  allLabels,
+		Annotations:             allAnnotations,
+		FunctionResourceRequest: functionResourceRequest,
+		ReadOnlyRootFilesystem:  function.ReadOnlyRootFilesystem,
+		TLSInsecure:             tlsInsecure,
+		Token:                   token,
+		Namespace:               function.Namespace,
+	}
+
+	return deploySpec, nil
+}
+
 // deployImage deploys a function with the given image
 func deployImage(
 	ctx context.Context,
@@ -415,9 +549,7 @@ func deployImage(
 		fmt.Println(msg)
 	}
 
-	statusCode = client.DeployFunction(ctx, deploySpec)
-
-	return statusCode, nil
+	return deployWithStrategy(ctx, client, deploySpec)
 }
 
 func readFiles(files []string) (map[string]string, error) {
@@ -0,0 +1,163 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	gopath "path"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+const (
+	deployStrategyRolling = "rolling"
+	deployStrategyCanary  = "canary"
+
+	canarySuffix = "-canary"
+)
+
+var (
+	deployStrategy  string
+	canaryAttempts  int
+	canaryInterval  time.Duration
+	canarySmokePath string
+)
+
+func validDeployStrategy(strategy string) error {
+	switch strategy {
+	case "", deployStrategyRolling, deployStrategyCanary:
+		return nil
+	}
+	return fmt.Errorf("unknown --strategy %q, valid options are: %s, %s", strategy, deployStrategyRolling, deployStrategyCanary)
+}
+
+// deployWithStrategy deploys the function using the strategy given via
+// --strategy. The rolling strategy updates the function in place, the canary
+// strategy only updates it once a copy of the new version is ready.
+// Successful deployments are added to the function's history.
+func deployWithStrategy(ctx context.Context, client *proxy.Client, spec *proxy.DeployFunctionSpec) (int, error) {
+	var statusCode int
+	var err error
+
+	if deployStrategy == deployStrategyCanary {
+		statusCode, err = deployCanary(ctx, client, spec)
+	} else {
+		statusCode = client.DeployFunction(ctx, spec)
+	}
+
+	if err == nil && !badStatusCode(statusCode) {
+		recordDeployment(client.GatewayURL.String(), spec)
+	}
+
+	return statusCode, err
+}
+
+// deployCanary deploys spec as <name>-canary and waits for it to become
+// ready, then optionally invokes the smoke-test path. When the canary passes,
+// the primary function is updated and the canary is removed once the primary
+// is ready, otherwise the canary is removed and the primary is left untouched.
+// The canary is kept when the promoted primary fails, so that it can still
+// serve the new version.
+func deployCanary(ctx context.Context, client *proxy.Client, spec *proxy.DeployFunctionSpec) (int, error) {
+	canary := *spec
+	canary.FunctionName = spec.FunctionName + canarySuffix
+	canary.Replace = false
+	canary.Update = true
+
+	fmt.Printf("[canary] Deploying %s\n", canary.FunctionName)
+	statusCode := client.DeployFunction(ctx, &canary)
+	if badStatusCode(statusCode) {
+		rollbackCanary(ctx, client, &canary)
+		return statusCode, fmt.Errorf("canary %s failed to deploy, status code: %d", canary.FunctionName, statusCode)
+	}
+
+	fmt.Printf("[canary] Waiting for %s to become ready\n", canary.FunctionName)
+	if err := waitForFunction(ctx, client, canary.FunctionName, canary.Namespace, canaryAttempts, canaryInterval); err != nil {
+		rollbackCanary(ctx, client, &canary)
+		return statusCode, fmt.Errorf("canary %s failed: %w", canary.FunctionName, err)
+	}
+
+	if len(canarySmokePath) > 0 {
+		fmt.Printf("[canary] Running smoke test against %s\n", canary.FunctionName)
+		if err := smokeTestFunction(ctx, client.GatewayURL, canary.FunctionName, canary.Namespace, canarySmokePath, canary.TLSInsecure); err != nil {
+			rollbackCanary(ctx, client, &canary)
+			return statusCode, fmt.Errorf("canary %s failed smoke test: %w", canary.FunctionName, err)
+		}
+		fmt.Printf("[canary] Smoke test passed\n")
+	}
+
+	fmt.Printf("[canary] Promoting %s\n", spec.FunctionName)
+	statusCode = client.DeployFunction(ctx, spec)
+	if badStatusCode(statusCode) {
+		fmt.Printf("[canary] Promotion failed, keeping %s\n", canary.FunctionName)
+		return statusCode, fmt.Errorf("promoting %s failed, status code: %d, %s was kept", spec.FunctionName, statusCode, canary.FunctionName)
+	}
+
+	fmt.Printf("[canary] Waiting for %s to become ready\n", spec.FunctionName)
+	if err := waitForFunction(ctx, client, spec.FunctionName, spec.Namespace, canaryAttempts, canaryInterval); err != nil {
+		fmt.Printf("[canary] Promotion failed, keeping %s\n", canary.FunctionName)
+		return statusCode, fmt.Errorf("promoted %s failed: %w, %s was kept", spec.FunctionName, err, canary.FunctionName)
+	}
+
+	fmt.Printf("[canary] Removing %s\n", canary.FunctionName)
+	if err := client.DeleteFunction(ctx, canary.FunctionName, canary.Namespace); err != nil {
+		fmt.Printf("[canary] Unable to remove %s: %s\n", canary.FunctionName, err)
+	}
+
+	return statusCode, nil
+}
+
+func rollbackCanary(ctx context.Context, client *proxy.Client, canary *proxy.DeployFunctionSpec) {
+	fmt.Printf("[canary] Rolling back, removing %s\n", canary.FunctionName)
+	if err := client.DeleteFunction(ctx, canary.FunctionName, canary.Namespace); err != nil {
+		fmt.Printf("[canary] Unable to remove %s: %s\n", canary.FunctionName, err)
+	}
+}
+
+// smokeTestFunction invokes path on the function via the gateway and expects
+// a 2xx response.
+func smokeTestFunction(ctx context.Context, gatewayURL *url.URL, functionName, namespace, path string, tlsInsecure bool) error {
+	name := functionName
+	if len(namespace) > 0 {
+		name = functionName + "." + namespace
+	}
+
+	u, err := url.Parse(gatewayURL.String())
+	if err != nil {
+		return err
+	}
+	u.Path = gopath.Join(u.Path, "function", name, path)
+	if strings.HasSuffix(path, "/") && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	c := &http.Client{
+		Transport: GetDefaultCLITransport(tlsInsecure, &timeoutOverride),
+		Timeout:   timeoutOverride,
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("GET %s returned status code: %d", u.Path, res.StatusCode)
+	}
+
+	return nil
+}
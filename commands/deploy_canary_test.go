@@ -0,0 +1,231 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	types "github.com/openfaas/faas-provider/types"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_deploy_canaryPromotes(t *testing.T) {
+	defer resetForTest()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/test-function-canary?usage=1",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "test-function-canary", AvailableReplicas: 1},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/function/test-function-canary/healthz",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/test-function?usage=1",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "test-function", AvailableReplicas: 1},
+		},
+		{
+			Method:             http.MethodDelete,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusAccepted,
+		},
+	})
+	defer s.Close()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"--gateway=" + s.URL,
+			"--image=golang",
+			"--name=test-function",
+			"--strategy=canary",
+			"--smoke-path=/healthz",
+			"--canary-interval=1ms",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err != nil {
+		t.Fatalf("want no error, got: %s\n%s", err, stdOut)
+	}
+
+	for _, want := range []string{
+		"[canary] Deploying test-function-canary",
+		"[canary] Smoke test passed",
+		"[canary] Promoting test-function",
+		"[canary] Waiting for test-function to become ready",
+		"[canary] Removing test-function-canary",
+	} {
+		if !strings.Contains(stdOut, want) {
+			t.Errorf("want output to contain %q, got:\n%s", want, stdOut)
+		}
+	}
+}
+
+func Test_deploy_canaryRollsBack(t *testing.T) {
+	defer resetForTest()
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/function/test-function-canary?usage=1",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       types.FunctionStatus{Name: "test-function-canary", AvailableReplicas: 1},
+		},
+		{
+			Method:             http.MethodGet,
+			Uri:                "/function/test-function-canary/healthz",
+			ResponseStatusCode: http.StatusInternalServerError,
+		},
+		{
+			Method:             http.MethodDelete,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusAccepted,
+		},
+	})
+	defer s.Close()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"--gateway=" + s.URL,
+			"--image=golang",
+			"--name=test-function",
+			"--strategy=canary",
+			"--smoke-path=/healthz",
+			"--canary-interval=1ms",
+		})
+		err = faasCmd.Execute()
+	})
+
+	if err == nil {
+		t.Fatalf("want an error when the smoke test fails, got none\n%s", stdOut)
+	}
+
+	if !strings.Contains(err.Error(), "failed smoke test") {
+		t.Errorf("want smoke test error, got: %s", err)
+	}
+
+	if !strings.Contains(stdOut, "[canary] Rolling back, removing test-function-canary") {
+		t.Errorf("want rollback in output, got:\n%s", stdOut)
+	}
+
+	if strings.Contains(stdOut, "[canary] Promoting") {
+		t.Errorf("primary should not be promoted, got:\n%s", stdOut)
+	}
+}
+
+func Test_deploy_canaryKeptWhenPromotionFails(t *testing.T) {
+	canaryReady := test.Request{
+		Method:             http.MethodGet,
+		Uri:                "/system/function/test-function-canary?usage=1",
+		ResponseStatusCode: http.StatusOK,
+		ResponseBody:       types.FunctionStatus{Name: "test-function-canary", AvailableReplicas: 1},
+	}
+
+	cases := []struct {
+		name     string
+		requests []test.Request
+		wantErr  string
+	}{
+		{
+			name: "bad status code",
+			requests: []test.Request{
+				{Method: http.MethodPut, Uri: "/system/functions", ResponseStatusCode: http.StatusOK},
+				canaryReady,
+				{Method: http.MethodPut, Uri: "/system/functions", ResponseStatusCode: http.StatusInternalServerError},
+			},
+			wantErr: "promoting test-function failed, status code: 500, test-function-canary was kept",
+		},
+		{
+			name: "primary not ready",
+			requests: []test.Request{
+				{Method: http.MethodPut, Uri: "/system/functions", ResponseStatusCode: http.StatusOK},
+				canaryReady,
+				{Method: http.MethodPut, Uri: "/system/functions", ResponseStatusCode: http.StatusOK},
+				{
+					Method:             http.MethodGet,
+					Uri:                "/system/function/test-function?usage=1",
+					ResponseStatusCode: http.StatusOK,
+					ResponseBody:       types.FunctionStatus{Name: "test-function", AvailableReplicas: 0},
+				},
+			},
+			wantErr: "promoted test-function failed: function test-function not ready",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetForTest()
+			defer resetForTest()
+
+			s := test.MockHttpServer(t, tc.requests)
+			defer s.Close()
+
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs([]string{
+					"deploy",
+					"--gateway=" + s.URL,
+					"--image=golang",
+					"--name=test-function",
+					"--strategy=canary",
+					"--canary-attempts=1",
+					"--canary-interval=1ms",
+				})
+				err = faasCmd.Execute()
+			})
+
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want error containing %q, got: %v\n%s", tc.wantErr, err, stdOut)
+			}
+
+			if !strings.Contains(stdOut, "[canary] Promotion failed, keeping test-function-canary") {
+				t.Errorf("want the failed step in output, got:\n%s", stdOut)
+			}
+
+			if strings.Contains(stdOut, "[canary] Removing") {
+				t.Errorf("canary should be kept, got:\n%s", stdOut)
+			}
+		})
+	}
+}
+
+func Test_validDeployStrategy(t *testing.T) {
+	for _, strategy := range []string{"", deployStrategyRolling, deployStrategyCanary} {
+		if err := validDeployStrategy(strategy); err != nil {
+			t.Errorf("want %q to be valid, got: %s", strategy, err)
+		}
+	}
+
+	if err := validDeployStrategy("bluegreen"); err == nil {
+		t.Errorf("want an error for an unknown strategy")
+	}
+}
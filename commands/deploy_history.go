@@ -0,0 +1,170 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+const (
+	// deployHistoryDir is the folder within the config directory where a
+	// history file is kept for each deployed function.
+	deployHistoryDir = "history"
+
+	// maxDeployRevisions is the number of revisions kept per function.
+	maxDeployRevisions = 20
+
+	defaultHistoryNamespace = "default"
+)
+
+// deployHistory is the list of successful deployments of a function to a
+// gateway and namespace, with the oldest revision first.
+type deployHistory struct {
+	Gateway   string           `json:"gateway"`
+	Namespace string           `json:"namespace,omitempty"`
+	Function  string           `json:"function"`
+	Revisions []deployRevision `json:"revisions"`
+
+	path string
+}
+
+type deployRevision struct {
+	Revision int                      `json:"revision"`
+	Deployed time.Time                `json:"deployed"`
+	Spec     proxy.DeployFunctionSpec `json:"spec"`
+}
+
+// deployHistoryPath returns the history file for a function, i.e.
+// ~/.openfaas/history/<gateway>/<namespace>/<function>.json
+func deployHistoryPath(gatewayURL, namespace, functionName string) (string, error) {
+	dir, err := homedir.Expand(config.ConfigDir())
+	if err != nil {
+		return "", err
+	}
+
+	gatewayDir := gatewayURL
+	if u, err := url.Parse(gatewayURL); err == nil && len(u.Host) > 0 {
+		gatewayDir = u.Host + u.Path
+	}
+	gatewayDir = strings.NewReplacer(":", "_", "/", "_").Replace(strings.TrimRight(gatewayDir, "/"))
+
+	if len(namespace) == 0 {
+		namespace = defaultHistoryNamespace
+	}
+
+	return filepath.Join(dir, deployHistoryDir, gatewayDir, namespace, functionName+".json"), nil
+}
+
+// loadDeployHistory reads the history of a function, a missing file results
+// in an empty history.
+func loadDeployHistory(gatewayURL, namespace, functionName string) (*deployHistory, error) {
+	path, err := deployHistoryPath(gatewayURL, namespace, functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &deployHistory{
+		Gateway:   strings.TrimRight(gatewayURL, "/"),
+		Namespace: namespace,
+		Function:  functionName,
+		path:      path,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, fmt.Errorf("unable to read deployment history %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("unable to parse deployment history %s: %w", path, err)
+	}
+
+	return history, nil
+}
+
+func (h *deployHistory) save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), config.DefaultPermissions); err != nil {
+		return fmt.Errorf("unable to create directory for deployment history: %w", err)
+	}
+
+	return os.WriteFile(h.path, data, 0600)
+}
+
+// add appends the spec as a new revision. Credentials are not written to the
+// history, and only the latest maxDeployRevisions revisions are kept.
+func (h *deployHistory) add(spec proxy.DeployFunctionSpec) deployRevision {
+	spec.Token = ""
+	spec.RegistryAuth = ""
+	spec.Replace = false
+	spec.Update = true
+
+	next := 1
+	if n := len(h.Revisions); n > 0 {
+		next = h.Revisions[n-1].Revision + 1
+	}
+
+	revision := deployRevision{
+		Revision: next,
+		Deployed: time.Now().UTC(),
+		Spec:     spec,
+	}
+
+	h.Revisions = append(h.Revisions, revision)
+	if len(h.Revisions) > maxDeployRevisions {
+		h.Revisions = h.Revisions[len(h.Revisions)-maxDeployRevisions:]
+	}
+
+	return revision
+}
+
+// get returns the given revision, or when revision is 0, the one deployed
+// before the current revision.
+func (h *deployHistory) get(revision int) (deployRevision, error) {
+	if revision == 0 {
+		if len(h.Revisions) < 2 {
+			return deployRevision{}, fmt.Errorf("no previous revision recorded for %s", h.Function)
+		}
+		return h.Revisions[len(h.Revisions)-2], nil
+	}
+
+	for _, r := range h.Revisions {
+		if r.Revision == revision {
+			return r, nil
+		}
+	}
+
+	return deployRevision{}, fmt.Errorf("revision %d not found for %s", revision, h.Function)
+}
+
+// recordDeployment adds a successful deployment to the function's history,
+// failing to do so only results in a warning.
+func recordDeployment(gatewayURL string, spec *proxy.DeployFunctionSpec) {
+	history, err := loadDeployHistory(gatewayURL, spec.Namespace, spec.FunctionName)
+	if err == nil {
+		history.add(*spec)
+		err = history.save()
+	}
+
+	if err != nil {
+		fmt.Printf("Unable to record deployment history for %s: %s\n", spec.FunctionName, err)
+	}
+}
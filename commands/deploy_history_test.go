@@ -0,0 +1,179 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_deployHistoryPath(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, "/tmp/openfaas")
+
+	cases := []struct {
+		name      string
+		gateway   string
+		namespace string
+		want      string
+	}{
+		{
+			name:    "default namespace",
+			gateway: "http://127.0.0.1:8080",
+			want:    "/tmp/openfaas/history/127.0.0.1_8080/default/figlet.json",
+		},
+		{
+			name:      "namespace and path",
+			gateway:   "https://openfaas.example.com/gw/",
+			namespace: "staging-fn",
+			want:      "/tmp/openfaas/history/openfaas.example.com_gw/staging-fn/figlet.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := deployHistoryPath(tc.gateway, tc.namespace, "figlet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != filepath.FromSlash(tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_deployHistory_addAndGet(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+
+	for _, image := range []string{"figlet:1", "figlet:2", "figlet:3"} {
+		recordDeployment("http://127.0.0.1:8080", &proxy.DeployFunctionSpec{
+			FunctionName: "figlet",
+			Image:        image,
+			Token:        "secret",
+		})
+	}
+
+	history, err := loadDeployHistory("http://127.0.0.1:8080", "", "figlet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Revisions) != 3 {
+		t.Fatalf("want 3 revisions, got %d", len(history.Revisions))
+	}
+
+	for _, r := range history.Revisions {
+		if r.Spec.Token != "" {
+			t.Errorf("token should not be recorded in revision %d", r.Revision)
+		}
+	}
+
+	previous, err := history.get(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous.Revision != 2 || previous.Spec.Image != "figlet:2" {
+		t.Errorf("want revision 2 with figlet:2, got %d with %s", previous.Revision, previous.Spec.Image)
+	}
+
+	if _, err := history.get(7); err == nil {
+		t.Errorf("want an error for a missing revision")
+	}
+}
+
+func Test_deployHistory_keepsLatestRevisions(t *testing.T) {
+	history := &deployHistory{Function: "figlet"}
+	for i := 0; i < maxDeployRevisions+5; i++ {
+		history.add(proxy.DeployFunctionSpec{FunctionName: "figlet"})
+	}
+
+	if len(history.Revisions) != maxDeployRevisions {
+		t.Fatalf("want %d revisions, got %d", maxDeployRevisions, len(history.Revisions))
+	}
+
+	if got := history.Revisions[0].Revision; got != 6 {
+		t.Errorf("want oldest revision to be 6, got %d", got)
+	}
+}
+
+func Test_rollback(t *testing.T) {
+	defer resetForTest()
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+		{
+			Method:             http.MethodPut,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+		},
+	})
+	defer s.Close()
+
+	for _, image := range []string{"figlet:1", "figlet:2"} {
+		test.CaptureStdout(func() {
+			faasCmd.SetArgs([]string{
+				"deploy",
+				"--gateway=" + s.URL,
+				"--image=" + image,
+				"--name=figlet",
+			})
+			if err := faasCmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"rollback",
+			"figlet",
+			"--gateway=" + s.URL,
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("want no error, got: %s\n%s", err, stdOut)
+	}
+
+	if !strings.Contains(stdOut, "Rolling back figlet to revision 1 (figlet:1)") {
+		t.Errorf("unexpected output:\n%s", stdOut)
+	}
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"history",
+			"figlet",
+			"--gateway=" + s.URL,
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdOut), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want a header and 3 revisions, got:\n%s", stdOut)
+	}
+	if !strings.HasPrefix(lines[3], "3 ") || !strings.Contains(lines[3], "figlet:1 (current)") {
+		t.Errorf("want revision 3 to be the rolled back image, got: %q", lines[3])
+	}
+}
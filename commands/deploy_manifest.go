@@ -0,0 +1,78 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/openfaas/go-sdk/stack"
+)
+
+// readPublishManifest loads the manifest written by faas-cli publish, which
+// must exist for it to be verified against.
+func readPublishManifest(path string) (*builder.PublishManifest, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("unable to verify against the publish manifest: %w", err)
+	}
+
+	return builder.LoadPublishManifest(path)
+}
+
+// verifyManifestImages checks each function against what faas-cli publish
+// recorded for it: the image, its digest in the registry, and the name and
+// SHA of the template it was built from. The images are returned pinned to
+// the digest which was verified.
+func verifyManifestImages(manifest *builder.PublishManifest, resolve imageResolver, functions map[string]stack.Function, images map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(images))
+	for functionName := range images {
+		names = append(names, functionName)
+	}
+	sort.Strings(names)
+
+	verified := make(map[string]string, len(images))
+	for _, functionName := range names {
+		published, ok := manifest.Get(functionName)
+		if !ok {
+			return nil, fmt.Errorf("%s is not in the publish manifest, run faas-cli publish first", functionName)
+		}
+
+		image, _ := splitImageDigest(images[functionName])
+		if image != published.Image {
+			return nil, fmt.Errorf("%s: image %s does not match %s in the publish manifest", functionName, image, published.Image)
+		}
+
+		if len(published.Digest) == 0 {
+			return nil, fmt.Errorf("%s: no digest was recorded in the publish manifest", functionName)
+		}
+
+		pinned, err := resolve(images[functionName])
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify %s: %w", functionName, err)
+		}
+		if _, digest := splitImageDigest(pinned); digest != published.Digest {
+			return nil, fmt.Errorf("%s: digest %s of %s does not match %s in the publish manifest", functionName, digest, image, published.Digest)
+		}
+
+		language := functions[functionName].Language
+		if language != published.Template.Name {
+			return nil, fmt.Errorf("%s: template %s does not match %s in the publish manifest", functionName, language, published.Template.Name)
+		}
+
+		var templateSha string
+		if meta, err := readTemplateMeta(filepath.Join(TemplateDirectory, language)); err == nil {
+			templateSha = meta.Sha
+		}
+		if templateSha != published.Template.Sha {
+			return nil, fmt.Errorf("%s: template %s is at SHA %q, the publish manifest has %q", functionName, language, templateSha, published.Template.Sha)
+		}
+
+		verified[functionName] = image + "@" + published.Digest
+	}
+
+	return verified, nil
+}
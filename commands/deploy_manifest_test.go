@@ -0,0 +1,179 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/builder"
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func Test_verifyManifestImages(t *testing.T) {
+	host := newTestRegistry(t)
+	image := host + "/fn:0.1.0"
+	digest := pushSignedImage(t, image, nil, "").String()
+
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("template", "go"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("template", "go", "meta.json"), []byte(`{"repository":"https://github.com/openfaas/templates","sha":"abc123"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	functions := map[string]stack.Function{"fn": {Name: "fn", Language: "go", Image: image}}
+	images := map[string]string{"fn": image}
+	published := builder.PublishedFunction{
+		Image:    image,
+		Digest:   digest,
+		Template: builder.PublishedTemplate{Name: "go", Sha: "abc123"},
+	}
+
+	cases := []struct {
+		name    string
+		modify  func(p *builder.PublishedFunction)
+		wantErr string
+	}{
+		{name: "matches", modify: func(p *builder.PublishedFunction) {}},
+		{name: "image", modify: func(p *builder.PublishedFunction) { p.Image = host + "/fn:0.2.0" }, wantErr: "does not match " + host + "/fn:0.2.0"},
+		{name: "digest", modify: func(p *builder.PublishedFunction) { p.Digest = "sha256:" + strings.Repeat("0", 64) }, wantErr: "digest " + digest},
+		{name: "no digest", modify: func(p *builder.PublishedFunction) { p.Digest = "" }, wantErr: "no digest was recorded"},
+		{name: "template name", modify: func(p *builder.PublishedFunction) { p.Template.Name = "node" }, wantErr: "template go does not match node"},
+		{name: "template sha", modify: func(p *builder.PublishedFunction) { p.Template.Sha = "def456" }, wantErr: `is at SHA "abc123", the publish manifest has "def456"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest, err := builder.LoadPublishManifest(filepath.Join(t.TempDir(), "publish-manifest.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry := published
+			tc.modify(&entry)
+			manifest.Record("fn", entry)
+
+			verified, err := verifyManifestImages(manifest, newImageResolver(t.Context()), functions, images)
+			if len(tc.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyManifestImages: %v", err)
+			}
+			if want := image + "@" + digest; verified["fn"] != want {
+				t.Fatalf("want %s, got %s", want, verified["fn"])
+			}
+		})
+	}
+}
+
+func Test_verifyManifestImages_notPublished(t *testing.T) {
+	manifest, err := builder.LoadPublishManifest(filepath.Join(t.TempDir(), "publish-manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = verifyManifestImages(manifest, func(image string) (string, error) {
+		t.Fatalf("%s should not be resolved", image)
+		return "", nil
+	}, map[string]stack.Function{"fn": {Name: "fn"}}, map[string]string{"fn": "fn:latest"})
+	if err == nil || !strings.Contains(err.Error(), "fn is not in the publish manifest") {
+		t.Fatalf("want an error for a function which was not published, got %v", err)
+	}
+}
+
+func Test_deploy_verifyManifest(t *testing.T) {
+	host := newTestRegistry(t)
+	image := host + "/fn:0.1.0"
+	published := pushSignedImage(t, image, nil, "").String()
+
+	var deployed []string
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.FunctionDeployment
+		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+			deployed = append(deployed, req.Image)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	dir := t.TempDir()
+	stackPath := filepath.Join(dir, "stack.yaml")
+	stackYAML := `version: 1.0
+provider:
+  name: openfaas
+functions:
+  fn:
+    lang: dockerfile
+    image: ` + image + `
+`
+	if err := os.WriteFile(stackPath, []byte(stackYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath := filepath.Join(dir, "publish-manifest.json")
+	manifest, err := builder.LoadPublishManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest.Record("fn", builder.PublishedFunction{
+		Image:    image,
+		Digest:   published,
+		Template: builder.PublishedTemplate{Name: "dockerfile"},
+	})
+	if err := manifest.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		readTemplate = true
+	}()
+
+	deploy := func() error {
+		var err error
+		test.CaptureStdout(func() {
+			faasCmd.SetArgs([]string{
+				"deploy",
+				"-f", stackPath,
+				"--gateway=" + gw.URL,
+				"--read-template=false",
+				"--verify-manifest=" + manifestPath,
+			})
+			err = faasCmd.Execute()
+		})
+		return err
+	}
+
+	if err := deploy(); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	if want := image + "@" + published; len(deployed) != 1 || deployed[0] != want {
+		t.Fatalf("want %s deployed, got %v", want, deployed)
+	}
+
+	// The tag is pushed again after it was published
+	pushSignedImage(t, image, nil, "")
+	deployed = nil
+
+	err = deploy()
+	if err == nil || !strings.Contains(err.Error(), "does not match "+published) {
+		t.Fatalf("want a digest mismatch, got %v", err)
+	}
+	if len(deployed) > 0 {
+		t.Fatalf("want nothing deployed, got %v", deployed)
+	}
+}
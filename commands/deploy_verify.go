@@ -0,0 +1,298 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	ggcrname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// cosignSignatureAnnotation holds the base64 signature of each layer of
+	// a cosign signature image.
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// cosignSimpleSigningMediaType is the media type of the signed payload.
+	cosignSimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	cosignSignatureType = "cosign container image signature"
+)
+
+// signaturePolicy is read from the policy block of the stack.yaml
+// configuration:
+//
+//	configuration:
+//	  policy:
+//	    verify_signature: true
+//	    public_key: ./cosign.pub
+type signaturePolicy struct {
+	VerifySignature bool   `yaml:"verify_signature"`
+	PublicKey       string `yaml:"public_key"`
+}
+
+// cosignPayload is the simple signing payload signed by cosign.
+type cosignPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// imageVerifier checks cosign-compatible signatures of images against a
+// public key. Only the registry is contacted, so it works offline against a
+// local registry, and there is no transparency log lookup.
+type imageVerifier struct {
+	key     crypto.PublicKey
+	options []crane.Option
+}
+
+// readSignaturePolicy reads the policy block from the stack.yaml, an
+// empty policy is returned when there is no stack.yaml.
+func readSignaturePolicy(yamlFile string) (signaturePolicy, error) {
+	var config struct {
+		Configuration struct {
+			Policy signaturePolicy `yaml:"policy"`
+		} `yaml:"configuration"`
+	}
+
+	if len(yamlFile) == 0 {
+		return config.Configuration.Policy, nil
+	}
+
+	data, err := readMergedStack(yamlFile)
+	if err != nil {
+		return config.Configuration.Policy, err
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config.Configuration.Policy, fmt.Errorf("unable to read policy from %s: %w", yamlFile, err)
+	}
+
+	return config.Configuration.Policy, nil
+}
+
+// newImageVerifier returns a verifier when --verify-signature or the
+// stack.yaml policy asks for signatures to be checked, otherwise nil.
+func newImageVerifier(ctx context.Context, deployFlags DeployFlags, policy signaturePolicy) (*imageVerifier, error) {
+	if !deployFlags.verifySignature && !policy.VerifySignature {
+		return nil, nil
+	}
+
+	keyPath := deployFlags.signatureKey
+	if len(keyPath) == 0 {
+		keyPath = policy.PublicKey
+	}
+	if len(keyPath) == 0 {
+		return nil, fmt.Errorf("give a public key to verify signatures with --signature-key or public_key in the stack.yaml policy")
+	}
+
+	key, err := loadVerificationKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &imageVerifier{
+		key:     key,
+		options: []crane.Option{crane.WithContext(ctx)},
+	}, nil
+}
+
+// loadVerificationKey reads a PEM encoded ECDSA, RSA or Ed25519 public key,
+// such as cosign.pub from "cosign generate-key-pair".
+func loadVerificationKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key %s: %w", path, err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T in %s", key, path)
+	}
+}
+
+// Verify resolves the image to its digest and checks that a signature
+// stored by cosign under the sha256-<digest>.sig tag was made by the key
+// for that digest. The digest is returned when the image is verified.
+func (v *imageVerifier) Verify(image string) (string, error) {
+	ref, err := ggcrname.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", image, err)
+	}
+
+	digest, err := crane.Digest(ref.String(), v.options...)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", image, err)
+	}
+
+	sigTag := ref.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig")
+
+	sigImage, err := crane.Pull(sigTag.String(), v.options...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("no signature found for %s@%s", ref.Context(), digest)
+		}
+		return "", fmt.Errorf("unable to fetch signature for %s: %w", image, err)
+	}
+
+	manifest, err := sigImage.Manifest()
+	if err != nil {
+		return "", fmt.Errorf("unable to read signature for %s: %w", image, err)
+	}
+
+	var lastErr error
+	for _, desc := range manifest.Layers {
+		if string(desc.MediaType) != cosignSimpleSigningMediaType {
+			continue
+		}
+
+		layer, err := sigImage.LayerByDigest(desc.Digest)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		rc, err := layer.Compressed()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		payload, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if lastErr = verifyCosignPayload(v.key, payload, desc.Annotations[cosignSignatureAnnotation], digest); lastErr == nil {
+			return digest, nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no signatures in %s", sigTag)
+	}
+	return "", fmt.Errorf("signature verification failed for %s: %w", image, lastErr)
+}
+
+// verifyCosignPayload checks the signature over the payload, then that the
+// payload was for the digest being deployed.
+func verifyCosignPayload(key crypto.PublicKey, payload []byte, encodedSignature, digest string) error {
+	if len(encodedSignature) == 0 {
+		return fmt.Errorf("layer has no %s annotation", cosignSignatureAnnotation)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	if err := verifySignatureBytes(key, payload, signature); err != nil {
+		return err
+	}
+
+	var p cosignPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid signed payload: %w", err)
+	}
+
+	if p.Critical.Type != cosignSignatureType {
+		return fmt.Errorf("unexpected signature type: %q", p.Critical.Type)
+	}
+
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is for %s, not %s", p.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	return nil
+}
+
+func verifySignatureBytes(key crypto.PublicKey, payload, signature []byte) error {
+	hash := sha256.Sum256(payload)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(k, hash[:], signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature) == nil {
+			return nil
+		}
+		if rsa.VerifyPSS(k, crypto.SHA256, hash[:], signature, nil) == nil {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(k, payload, signature) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return fmt.Errorf("invalid signature for the public key")
+}
+
+// verifyImages checks the signature of every image before any function is
+// deployed. Each image is returned pinned to the digest which was verified,
+// so that a tag which is pushed again afterwards cannot be deployed.
+func verifyImages(v *imageVerifier, images map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(images))
+	for functionName := range images {
+		names = append(names, functionName)
+	}
+	sort.Strings(names)
+
+	verified := make(map[string]string, len(images))
+	for _, functionName := range names {
+		image := images[functionName]
+		digest, err := v.Verify(image)
+		if err != nil {
+			return nil, fmt.Errorf("refusing to deploy %s: %w", functionName, err)
+		}
+
+		base, _ := splitImageDigest(image)
+		verified[functionName] = base + "@" + digest
+		fmt.Printf("Verified signature of %s: %s\n", functionName, verified[functionName])
+	}
+	return verified, nil
+}
@@ -0,0 +1,323 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// newTestRegistry starts an in-memory registry for the test, which does not
+// log every request, and returns its host.
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// pushSignedImage pushes a random image to the registry and, when key is
+// set, a cosign signature of the payload for signedDigest, or the image's
+// own digest when signedDigest is empty.
+func pushSignedImage(t *testing.T, ref string, key *ecdsa.PrivateKey, signedDigest string) v1.Hash {
+	t.Helper()
+
+	img, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatalf("crane.Push: %v", err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("img.Digest: %v", err)
+	}
+
+	if key == nil {
+		return digest
+	}
+
+	if len(signedDigest) == 0 {
+		signedDigest = digest.String()
+	}
+
+	var p cosignPayload
+	p.Critical.Identity.DockerReference = strings.Split(ref, ":")[0]
+	p.Critical.Image.DockerManifestDigest = signedDigest
+	p.Critical.Type = cosignSignatureType
+	payload, _ := json.Marshal(p)
+
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("ecdsa.SignASN1: %v", err)
+	}
+
+	sigImg, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer: static.NewLayer(payload, types.MediaType(cosignSimpleSigningMediaType)),
+		Annotations: map[string]string{
+			cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	})
+	if err != nil {
+		t.Fatalf("mutate.Append: %v", err)
+	}
+
+	repo := ref[:strings.LastIndex(ref, ":")]
+	sigRef := repo + ":" + strings.Replace(digest.String(), ":", "-", 1) + ".sig"
+	if err := crane.Push(sigImg, sigRef); err != nil {
+		t.Fatalf("crane.Push signature: %v", err)
+	}
+
+	return digest
+}
+
+func writePublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey: %v", err)
+	}
+
+	p := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func Test_imageVerifier(t *testing.T) {
+	host := newTestRegistry(t)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	signed := pushSignedImage(t, host+"/signed:latest", key, "")
+	pushSignedImage(t, host+"/unsigned:latest", nil, "")
+	pushSignedImage(t, host+"/other-key:latest", otherKey, "")
+	pushSignedImage(t, host+"/wrong-digest:latest", key, signed.String())
+
+	verifier, err := newImageVerifier(context.Background(), DeployFlags{verifySignature: true, signatureKey: writePublicKey(t, key)}, signaturePolicy{})
+	if err != nil {
+		t.Fatalf("newImageVerifier: %v", err)
+	}
+
+	digest, err := verifier.Verify(host + "/signed:latest")
+	if err != nil {
+		t.Fatalf("want the signed image to be verified, got: %v", err)
+	}
+	if digest != signed.String() {
+		t.Fatalf("want digest %s, got %s", signed, digest)
+	}
+
+	cases := []struct {
+		image string
+		want  string
+	}{
+		{image: host + "/unsigned:latest", want: "no signature found"},
+		{image: host + "/other-key:latest", want: "invalid signature"},
+		{image: host + "/wrong-digest:latest", want: "signature is for " + signed.String()},
+		{image: host + "/missing:latest", want: "unable to resolve"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			_, err := verifier.Verify(tc.image)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("want an error containing %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func Test_newImageVerifier(t *testing.T) {
+	verifier, err := newImageVerifier(context.Background(), DeployFlags{}, signaturePolicy{})
+	if err != nil || verifier != nil {
+		t.Fatalf("want no verifier when verification is not enabled, got %v %v", verifier, err)
+	}
+
+	if _, err := newImageVerifier(context.Background(), DeployFlags{}, signaturePolicy{VerifySignature: true}); err == nil {
+		t.Fatalf("want an error when the policy has no public key")
+	}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	verifier, err = newImageVerifier(context.Background(), DeployFlags{}, signaturePolicy{VerifySignature: true, PublicKey: writePublicKey(t, key)})
+	if err != nil || verifier == nil {
+		t.Fatalf("want a verifier from the policy, got %v %v", verifier, err)
+	}
+}
+
+func Test_deploy_refusesUnsignedImage(t *testing.T) {
+	host := newTestRegistry(t)
+
+	pushSignedImage(t, host+"/unsigned:latest", nil, "")
+
+	var deploys int32
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&deploys, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keyPath := writePublicKey(t, key)
+
+	stackPath := filepath.Join(t.TempDir(), "stack.yaml")
+	stackYAML := `version: 1.0
+provider:
+  name: openfaas
+configuration:
+  policy:
+    verify_signature: true
+    public_key: ` + keyPath + `
+functions:
+  unsigned:
+    image: ` + host + `/unsigned:latest
+`
+	if err := os.WriteFile(stackPath, []byte(stackYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		readTemplate = true
+	}()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"-f", stackPath,
+		"--gateway=" + gw.URL,
+		"--read-template=false",
+	})
+	err := faasCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "refusing to deploy unsigned") {
+		t.Fatalf("want deploy to be refused, got: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&deploys); got != 0 {
+		t.Fatalf("want no calls to the gateway, got %d", got)
+	}
+}
+
+func Test_deploy_deploysVerifiedDigest(t *testing.T) {
+	host := newTestRegistry(t)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	digest := pushSignedImage(t, host+"/signed:latest", key, "")
+
+	var deployedImage atomic.Value
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var spec struct {
+			Image string `json:"image"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&spec); err == nil && len(spec.Image) > 0 {
+			deployedImage.Store(spec.Image)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{
+		"deploy",
+		"--image=" + host + "/signed:latest",
+		"--name=signed",
+		"--gateway=" + gw.URL,
+		"--verify-signature",
+		"--signature-key=" + writePublicKey(t, key),
+	})
+	if err := faasCmd.Execute(); err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+
+	want := host + "/signed:latest@" + digest.String()
+	if got, _ := deployedImage.Load().(string); got != want {
+		t.Fatalf("want the verified digest %s deployed, got %q", want, got)
+	}
+}
+
+func Test_apply_refusesUnsignedImage(t *testing.T) {
+	host := newTestRegistry(t)
+
+	pushSignedImage(t, host+"/unsigned:latest", nil, "")
+
+	var deploys int32
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte("[]"))
+			return
+		}
+		atomic.AddInt32(&deploys, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	stackPath := filepath.Join(t.TempDir(), "stack.yaml")
+	stackYAML := `version: 1.0
+provider:
+  name: openfaas
+configuration:
+  policy:
+    verify_signature: true
+    public_key: ` + writePublicKey(t, key) + `
+functions:
+  unsigned:
+    image: ` + host + `/unsigned:latest
+`
+	if err := os.WriteFile(stackPath, []byte(stackYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{
+		"apply",
+		"-f", stackPath,
+		"--gateway=" + gw.URL,
+	})
+	err := faasCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "refusing to deploy unsigned") {
+		t.Fatalf("want apply to be refused, got: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&deploys); got != 0 {
+		t.Fatalf("want no functions deployed, got %d", got)
+	}
+}
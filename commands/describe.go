@@ -61,7 +61,7 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 	functionName = args[0]
 
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -72,7 +72,7 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		}
 	}
 	gatewayAddress := getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
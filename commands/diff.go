@@ -17,6 +17,7 @@ import (
 
 type funcDiff struct {
 	Image                  string
+	ImageDigest            string
 	FProcess               string
 	Env                    map[string]string
 	Secrets                []string
@@ -39,7 +40,17 @@ const (
 	diffEnvAll    = "all"
 )
 
-var diffEnvMode = diffEnvLocal
+const (
+	diffOutputTable = "table"
+	diffOutputJSON  = "json"
+	diffOutputPatch = "patch"
+)
+
+var (
+	diffEnvMode  = diffEnvLocal
+	diffOutput   = diffOutputTable
+	diffExitCode = true
+)
 
 func init() {
 	diffCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
@@ -49,6 +60,8 @@ func init() {
 	diffCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace override for the diff")
 	diffCmd.Flags().Var(&tagFormat, "tag", "Override latest tag on function Docker image, accepts 'digest', 'sha', 'branch', or 'describe', or 'latest'")
 	diffCmd.Flags().StringVar(&diffEnvMode, "env", diffEnvLocal, "Environment diff mode: local, remote, or all")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", diffOutputTable, "Output format: table, json, or patch")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", true, "Exit with a non-zero status when differences are found")
 
 	faasCmd.AddCommand(diffCmd)
 }
@@ -59,10 +72,21 @@ var diffCmd = &cobra.Command{
 	Long: `Compares the function definitions in stack.yaml with what is actually
 deployed on the gateway and shows the differences.
 
-This command is read-only - it only makes a GET request to list functions.`,
+This command is read-only - it only makes a GET request to list functions.
+
+When a function was deployed with --pin-digest, the tag in stack.yaml is
+resolved in the registry. A tag which now points to a different image is
+reported as a change to image.digest, rather than to the function's
+configuration.
+
+The exit code is non-zero when differences are found, use --exit-code=false
+to exit with zero regardless.`,
 	Example: `  faas-cli diff
   faas-cli diff -f my-stack.yaml
-  faas-cli diff --gateway https://my-gateway.example.com`,
+  faas-cli diff --gateway https://my-gateway.example.com
+  faas-cli diff -o json
+  faas-cli diff -o patch > drift.patch
+  faas-cli diff -o json --exit-code=false`,
 	RunE: runDiff,
 }
 
@@ -73,8 +97,11 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	if !validDiffEnvMode(diffEnvMode) {
 		return fmt.Errorf("unknown env diff mode: %s", diffEnvMode)
 	}
+	if !validDiffOutput(diffOutput) {
+		return fmt.Errorf("unknown output format: %s, use table, json or patch", diffOutput)
+	}
 
-	parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+	parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 	if err != nil {
 		return err
 	}
@@ -84,7 +111,7 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 
 	gatewayAddress := getGatewayURL(gateway, defaultGateway, parsedServices.Provider.GatewayURL, os.Getenv(openFaaSURLEnvironment))
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
@@ -105,48 +132,16 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	for name, fn := range yamlFns {
 		key := diffKey(name, namespaceForDiffKey(functionNamespace, fn.Namespace))
 
-		imageName, err := buildDiffImageName(fn.Image, fn.Handler, tagFormat)
+		fnDiff, err := stackFuncDiff(fn, tagFormat)
 		if err != nil {
 			return err
 		}
-
-		env := fn.Environment
-		if env == nil {
-			env = make(map[string]string)
-		}
-		yamlMap[key] = funcDiff{
-			Image:                  imageName,
-			FProcess:               fn.FProcess,
-			Env:                    env,
-			Secrets:                fn.Secrets,
-			Constraints:            constraintsFromPtr(fn.Constraints),
-			Labels:                 mapFromPtr(fn.Labels),
-			Annotations:            mapFromPtr(fn.Annotations),
-			Limits:                 resourcesFromStack(fn.Limits),
-			Requests:               resourcesFromStack(fn.Requests),
-			ReadOnlyRootFilesystem: fn.ReadOnlyRootFilesystem,
-		}
+		yamlMap[key] = fnDiff
 	}
 
 	deployedMap := make(map[string]funcDiff)
 	for _, fn := range functions {
-		envMap := fn.EnvVars
-		if envMap == nil {
-			envMap = make(map[string]string)
-		}
-
-		fnDiff := funcDiff{
-			Image:                  fn.Image,
-			FProcess:               fn.EnvProcess,
-			Env:                    envMap,
-			Secrets:                fn.Secrets,
-			Constraints:            fn.Constraints,
-			Labels:                 mapFromPtr(fn.Labels),
-			Annotations:            mapFromPtr(fn.Annotations),
-			Limits:                 resourcesFromStatus(fn.Limits),
-			Requests:               resourcesFromStatus(fn.Requests),
-			ReadOnlyRootFilesystem: fn.ReadOnlyRootFilesystem,
-		}
+		fnDiff := deployedFuncDiff(fn)
 		deployedMap[diffKey(fn.Name, "")] = fnDiff
 		if fn.Namespace != "" {
 			deployedMap[diffKey(fn.Name, fn.Namespace)] = fnDiff
@@ -156,27 +151,156 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	keys := funcDiffKeys(yamlMap)
 	sort.Strings(keys)
 
-	hasDiff := false
+	resolve := newImageResolver(cmd.Context())
+
+	var results []diffResult
 	for _, key := range keys {
 		yamlF, yamlExists := yamlMap[key]
 		deployedF, deployedExists := deployedMap[key]
 
+		tagMoved := false
+		if yamlExists && deployedExists {
+			if tagMoved, err = diffImageDigests(&yamlF, &deployedF, resolve); err != nil {
+				return fmt.Errorf("unable to compare the image of %s: %w", key, err)
+			}
+		}
+
 		rows, changed := buildSideBySide(yamlF, yamlExists, deployedF, deployedExists, diffEnvMode)
 		if !changed {
 			continue
 		}
-		hasDiff = true
-		printDifftool(key, rows)
+
+		results = append(results, diffResult{
+			key:            key,
+			rows:           rows,
+			yamlF:          yamlF,
+			deployedF:      deployedF,
+			deployedExists: deployedExists,
+			tagMoved:       tagMoved,
+		})
+	}
+
+	switch diffOutput {
+	case diffOutputJSON:
+		if err := printDiffJSON(os.Stdout, results); err != nil {
+			return err
+		}
+	case diffOutputPatch:
+		printDiffPatch(os.Stdout, results, diffEnvMode)
+	default:
+		for _, result := range results {
+			printDifftool(result.key, result.rows)
+			if result.tagMoved {
+				fmt.Printf("  %s now points to %s, the deployed function is pinned to %s\n\n",
+					result.yamlF.Image, result.yamlF.ImageDigest, result.deployedF.ImageDigest)
+			}
+		}
+
+		if len(results) == 0 {
+			fmt.Println("YAML matches deployment, no differences found.")
+		}
 	}
 
-	if !hasDiff {
-		fmt.Println("YAML matches deployment, no differences found.")
+	if len(results) == 0 || !diffExitCode {
 		return nil
 	}
 
+	if diffOutput != diffOutputTable {
+		// Keep machine-readable output free of the error message
+		return &exitCodeError{code: 1}
+	}
+
 	return fmt.Errorf("differences found")
 }
 
+// stackFuncDiff converts a function from stack.yaml into the attributes
+// which are compared against a deployed function.
+func stackFuncDiff(fn stack.Function, tagMode schema.BuildFormat) (funcDiff, error) {
+	imageName, err := buildDiffImageName(fn.Image, fn.Handler, tagMode)
+	if err != nil {
+		return funcDiff{}, err
+	}
+
+	env := fn.Environment
+	if env == nil {
+		env = make(map[string]string)
+	}
+
+	return funcDiff{
+		Image:                  imageName,
+		FProcess:               fn.FProcess,
+		Env:                    env,
+		Secrets:                fn.Secrets,
+		Constraints:            constraintsFromPtr(fn.Constraints),
+		Labels:                 mapFromPtr(fn.Labels),
+		Annotations:            mapFromPtr(fn.Annotations),
+		Limits:                 resourcesFromStack(fn.Limits),
+		Requests:               resourcesFromStack(fn.Requests),
+		ReadOnlyRootFilesystem: fn.ReadOnlyRootFilesystem,
+	}, nil
+}
+
+// deployedFuncDiff converts a function returned by the gateway into the
+// attributes which are compared against stack.yaml.
+func deployedFuncDiff(fn types.FunctionStatus) funcDiff {
+	envMap := fn.EnvVars
+	if envMap == nil {
+		envMap = make(map[string]string)
+	}
+
+	return funcDiff{
+		Image:                  fn.Image,
+		FProcess:               fn.EnvProcess,
+		Env:                    envMap,
+		Secrets:                fn.Secrets,
+		Constraints:            fn.Constraints,
+		Labels:                 mapFromPtr(fn.Labels),
+		Annotations:            mapFromPtr(fn.Annotations),
+		Limits:                 resourcesFromStatus(fn.Limits),
+		Requests:               resourcesFromStatus(fn.Requests),
+		ReadOnlyRootFilesystem: fn.ReadOnlyRootFilesystem,
+	}
+}
+
+// diffImageDigests compares the digest of a function deployed with
+// --pin-digest against the digest its tag in stack.yaml resolves to now. The
+// tag and digest are compared as separate attributes, so a tag which moved
+// is reported as a change to image.digest only. tagMoved is true when the
+// tag points to a different image than the one deployed.
+func diffImageDigests(yamlF, deployedF *funcDiff, resolve imageResolver) (bool, error) {
+	deployedImage, deployedDigest := splitImageDigest(deployedF.Image)
+	if len(deployedDigest) == 0 {
+		return false, nil
+	}
+
+	// A digest given in stack.yaml is part of the configuration
+	if _, yamlDigest := splitImageDigest(yamlF.Image); len(yamlDigest) > 0 {
+		return false, nil
+	}
+
+	// Deployed as repository@sha256:... without the tag
+	if deployedImage != yamlF.Image && deployedImage == imageRepository(deployedImage) &&
+		deployedImage == imageRepository(yamlF.Image) {
+		deployedImage = yamlF.Image
+	}
+
+	if deployedImage != yamlF.Image {
+		return false, nil
+	}
+
+	pinned, err := resolve(yamlF.Image)
+	if err != nil {
+		return false, err
+	}
+	_, currentDigest := splitImageDigest(pinned)
+
+	yamlF.ImageDigest = currentDigest
+	deployedF.Image = deployedImage
+	deployedF.ImageDigest = deployedDigest
+
+	return currentDigest != deployedDigest, nil
+}
+
 func buildDiffImageName(image string, handler string, tagMode schema.BuildFormat) (string, error) {
 	branch, version, err := builder.GetImageTagValues(tagMode, handler)
 	if err != nil {
@@ -260,11 +384,18 @@ func validDiffEnvMode(envMode string) bool {
 	return envMode == diffEnvLocal || envMode == diffEnvRemote || envMode == diffEnvAll
 }
 
+func validDiffOutput(output string) bool {
+	return output == diffOutputTable || output == diffOutputJSON || output == diffOutputPatch
+}
+
 func sortedAttrMap(f funcDiff) map[string]string {
 	m := make(map[string]string)
 	if f.Image != "" {
 		m["image"] = f.Image
 	}
+	if f.ImageDigest != "" {
+		m["image.digest"] = f.ImageDigest
+	}
 	if f.FProcess != "" {
 		m["fprocess"] = f.FProcess
 	}
@@ -0,0 +1,159 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// diffResult holds the differences found for a single function.
+type diffResult struct {
+	key            string
+	rows           []diffRow
+	yamlF          funcDiff
+	deployedF      funcDiff
+	deployedExists bool
+	tagMoved       bool
+}
+
+// diffReport is written by "faas-cli diff -o json".
+type diffReport struct {
+	Drift     bool               `json:"drift"`
+	Functions []functionDiffJSON `json:"functions"`
+}
+
+// functionDiffJSON describes the drift of one function. Added attributes are
+// only in stack.yaml, removed attributes are only deployed, and changed
+// attributes have a different value in each. TagMoved is set when the tag
+// in stack.yaml points to a different image than the pinned digest which
+// is deployed.
+type functionDiffJSON struct {
+	Name     string                     `json:"name"`
+	Deployed bool                       `json:"deployed"`
+	TagMoved bool                       `json:"tag_moved,omitempty"`
+	Added    map[string]string          `json:"added,omitempty"`
+	Removed  map[string]string          `json:"removed,omitempty"`
+	Changed  map[string]changedAttrJSON `json:"changed,omitempty"`
+}
+
+type changedAttrJSON struct {
+	Stack    string `json:"stack"`
+	Deployed string `json:"deployed"`
+}
+
+func printDiffJSON(w io.Writer, results []diffResult) error {
+	report := diffReport{
+		Drift:     len(results) > 0,
+		Functions: []functionDiffJSON{},
+	}
+
+	for _, result := range results {
+		fn := functionDiffJSON{
+			Name:     result.key,
+			Deployed: result.deployedExists,
+			TagMoved: result.tagMoved,
+		}
+
+		if result.deployedExists {
+			for _, row := range result.rows {
+				switch {
+				case row.left.field != "" && row.right.field != "":
+					if fn.Changed == nil {
+						fn.Changed = make(map[string]changedAttrJSON)
+					}
+					fn.Changed[row.left.field] = changedAttrJSON{Stack: row.left.value, Deployed: row.right.value}
+				case row.left.field != "":
+					if fn.Added == nil {
+						fn.Added = make(map[string]string)
+					}
+					fn.Added[row.left.field] = row.left.value
+				case row.right.field != "":
+					if fn.Removed == nil {
+						fn.Removed = make(map[string]string)
+					}
+					fn.Removed[row.right.field] = row.right.value
+				}
+			}
+		} else {
+			fn.Added = sortedAttrMap(result.yamlF)
+		}
+
+		report.Functions = append(report.Functions, fn)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// printDiffPatch writes a unified diff per function, with stack.yaml as the
+// original and the deployed function as the modified version, matching the
+// -/+ markers used by the table output.
+func printDiffPatch(w io.Writer, results []diffResult, envMode string) {
+	for _, result := range results {
+		lines, oldCount, newCount := diffPatchLines(result, envMode)
+
+		fmt.Fprintf(w, "--- a/%s (stack.yaml)\n", result.key)
+		fmt.Fprintf(w, "+++ b/%s (deployed)\n", result.key)
+		fmt.Fprintf(w, "@@ %s %s @@\n", hunkRange("-", oldCount), hunkRange("+", newCount))
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+func diffPatchLines(result diffResult, envMode string) ([]string, int, int) {
+	yamlAttrs := sortedAttrMap(result.yamlF)
+
+	var depAttrs map[string]string
+	if result.deployedExists {
+		depAttrs = sortedAttrMap(result.deployedF)
+	} else {
+		depAttrs = map[string]string{}
+	}
+
+	var lines []string
+	oldCount, newCount := 0, 0
+
+	for _, field := range allAttrKeys(yamlAttrs, depAttrs) {
+		leftValue, leftHas := yamlAttrs[field]
+		rightValue, rightHas := depAttrs[field]
+
+		if result.deployedExists && ignoreAttr(field, leftHas, rightHas, envMode) {
+			continue
+		}
+
+		if leftHas && rightHas && leftValue == rightValue {
+			lines = append(lines, fmt.Sprintf(" %s: %s", field, leftValue))
+			oldCount++
+			newCount++
+			continue
+		}
+
+		if leftHas {
+			lines = append(lines, fmt.Sprintf("-%s: %s", field, leftValue))
+			oldCount++
+		}
+		if rightHas {
+			lines = append(lines, fmt.Sprintf("+%s: %s", field, rightValue))
+			newCount++
+		}
+	}
+
+	return lines, oldCount, newCount
+}
+
+func hunkRange(prefix string, count int) string {
+	start := 1
+	if count == 0 {
+		start = 0
+	}
+	return fmt.Sprintf("%s%d,%d", prefix, start, count)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+)
+
+const diffOutputYAML = `version: 1.0
+provider:
+  name: openfaas
+  gateway: http://127.0.0.1:8080
+functions:
+  myfunc:
+    lang: golang-middleware
+    handler: ./myfunc
+    image: ttl.sh/test/myfunc:v1.0.0
+    environment:
+      log_level: debug
+    labels:
+      team: payments
+`
+
+func runDiffOutput(t *testing.T, deployed []types.FunctionStatus, args ...string) (string, error) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	if err := os.WriteFile(yamlPath, []byte(diffOutputYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := test.MockHttpServer(t, []test.Request{
+		{
+			Method:             http.MethodGet,
+			Uri:                "/system/functions",
+			ResponseStatusCode: http.StatusOK,
+			ResponseBody:       deployed,
+		},
+	})
+	defer s.Close()
+
+	resetForTest()
+	defer resetForTest()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs(append([]string{
+			"diff",
+			"-f", yamlPath,
+			"--gateway=" + s.URL,
+		}, args...))
+		executeErr = faasCmd.Execute()
+	})
+
+	return stdOut, executeErr
+}
+
+func Test_diff_output_json(t *testing.T) {
+	labels := map[string]string{"owner": "ops"}
+	deployed := []types.FunctionStatus{
+		{
+			Name:    "myfunc",
+			Image:   "ttl.sh/test/myfunc:v2.0.0",
+			EnvVars: map[string]string{"log_level": "debug"},
+			Labels:  &labels,
+		},
+	}
+
+	stdOut, err := runDiffOutput(t, deployed, "-o", "json")
+
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) || exitErr.code != 1 {
+		t.Fatalf("want exit code 1 when differences are found, got: %v", err)
+	}
+
+	var report diffReport
+	if err := json.Unmarshal([]byte(stdOut), &report); err != nil {
+		t.Fatalf("want JSON output, got error: %s\n%s", err, stdOut)
+	}
+
+	if !report.Drift || len(report.Functions) != 1 {
+		t.Fatalf("want drift for 1 function, got: %+v", report)
+	}
+
+	fn := report.Functions[0]
+	if fn.Name != "myfunc" || !fn.Deployed {
+		t.Errorf("want deployed function myfunc, got: %+v", fn)
+	}
+
+	if got := fn.Changed["image"]; got.Stack != "ttl.sh/test/myfunc:v1.0.0" || got.Deployed != "ttl.sh/test/myfunc:v2.0.0" {
+		t.Errorf("want image in changed attributes, got: %+v", fn.Changed)
+	}
+	if fn.Added["label.team"] != "payments" {
+		t.Errorf("want label.team in added attributes, got: %+v", fn.Added)
+	}
+	if fn.Removed["label.owner"] != "ops" {
+		t.Errorf("want label.owner in removed attributes, got: %+v", fn.Removed)
+	}
+}
+
+func Test_diff_output_json_no_drift_exit_code_disabled(t *testing.T) {
+	deployed := []types.FunctionStatus{
+		{
+			Name:  "myfunc",
+			Image: "ttl.sh/test/myfunc:v2.0.0",
+		},
+	}
+
+	stdOut, err := runDiffOutput(t, deployed, "-o", "json", "--exit-code=false")
+	if err != nil {
+		t.Fatalf("want no error with --exit-code=false, got: %s", err)
+	}
+
+	if !strings.Contains(stdOut, `"drift": true`) {
+		t.Errorf("want drift in JSON output, got:\n%s", stdOut)
+	}
+}
+
+func Test_diff_output_patch(t *testing.T) {
+	deployed := []types.FunctionStatus{
+		{
+			Name:    "myfunc",
+			Image:   "ttl.sh/test/myfunc:v2.0.0",
+			EnvVars: map[string]string{"log_level": "debug"},
+		},
+	}
+
+	stdOut, err := runDiffOutput(t, deployed, "-o", "patch")
+
+	var exitErr *exitCodeError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("want exit code error when differences are found, got: %v", err)
+	}
+
+	want := `--- a/myfunc (stack.yaml)
++++ b/myfunc (deployed)
+@@ -1,4 +1,3 @@
+ env.log_level: debug
+-image: ttl.sh/test/myfunc:v1.0.0
++image: ttl.sh/test/myfunc:v2.0.0
+-label.team: payments
+ readonly_root_filesystem: false
+`
+	if stdOut != want {
+		t.Errorf("want patch:\n%s\ngot:\n%s", want, stdOut)
+	}
+}
+
+func Test_diff_output_invalid(t *testing.T) {
+	_, err := runDiffOutputNoServer(t, "-o", "yaml")
+	if err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Fatalf("want unknown output format error, got: %v", err)
+	}
+}
+
+func runDiffOutputNoServer(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	yamlPath := filepath.Join(tmpDir, "stack.yaml")
+	if err := os.WriteFile(yamlPath, []byte(diffOutputYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer resetForTest()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs(append([]string{"diff", "-f", yamlPath}, args...))
+		executeErr = faasCmd.Execute()
+	})
+
+	return stdOut, executeErr
+}
@@ -4,6 +4,7 @@
 package commands
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -24,3 +25,13 @@ func checkTLSInsecure(gateway string, tlsInsecure bool) string {
 	}
 	return ""
 }
+
+// exitCodeError exits the CLI with the given code without printing a
+// message, for commands whose output is meant to be read by other tools.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
@@ -60,6 +60,8 @@ var stat = func(filename string) (os.FileInfo, error) {
 // TODO: remove this workaround once these vars are no longer global
 func resetForTest() {
 	yamlFile = ""
+	yamlOverlays = nil
+	stackFiles.pending = nil
 	regex = ""
 	filter = ""
 	version.Version = ""
@@ -67,13 +69,48 @@ func resetForTest() {
 	appendFile = ""
 	tagFormat = 0
 	diffEnvMode = diffEnvLocal
+	diffOutput = diffOutputTable
+	diffExitCode = true
+	deployStrategy = deployStrategyRolling
+	canarySmokePath = ""
+	rollbackRevision = 0
+	rollbackVerifySignature = false
+	rollbackSignatureKey = ""
+	applyVerifySignature = false
+	applySignatureKey = ""
+	invokeBench = false
+	benchRate = ""
+	benchOutputFormat = benchOutputTable
+	invokeRecordDir = ""
+	invokeReplayDir = ""
+	invokeReplayURL = ""
+	contextOverride = ""
+	activeContext = nil
+	namespaceFromContext = false
+	deployFlags.verifySignature = false
+	deployFlags.signatureKey = ""
+	deployFlags.pinDigest = false
+	deployFlags.verifyManifest = ""
+	generatePinDigest = false
+	loginOIDC = false
+	oidcDeviceCode = false
+	tokenURL = ""
+	clientSecretFile = ""
+	tokenAudience = ""
+	loginServiceAccount = false
+	oidcIssuer = ""
+	oidcClientID = ""
+	templatePublishName = ""
+	templatePublishDescription = ""
+	templatePublishAnnotations = nil
+	templatePublishStoreFile = ""
 }
 
 func init() {
 	// Setup terminal std
 	term.StdStreams()
 
-	faasCmd.PersistentFlags().StringVarP(&yamlFile, "yaml", "f", "", "Path to YAML file describing function(s)")
+	faasCmd.PersistentFlags().VarP(stackFiles, "yaml", "f", "Path to YAML file describing function(s), repeat to overlay additional files")
 	faasCmd.PersistentFlags().StringVarP(&regex, "regex", "", "", "Regex to match with function names in YAML file")
 	faasCmd.PersistentFlags().StringVarP(&filter, "filter", "", "", "Wildcard to match with function names in YAML file")
 
@@ -138,6 +175,11 @@ func Execute(customArgs []string) {
 	}
 
 	if err := faasCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+
 		e := err.Error()
 		fmt.Println(strings.ToUpper(e[:1]) + e[1:])
 		os.Exit(1)
@@ -27,12 +27,18 @@ const TemplateDirectory = "./template/"
 
 const ShaPrefix = "sha-"
 
-// fetchTemplates fetch code templates using git clone.
-func fetchTemplates(templateURL, refName, templateName string, overwriteTemplates bool) error {
+// fetchTemplates fetch code templates using git clone, the commit of each
+// template is verified against and recorded in the templates.lock according
+// to lockMode.
+func fetchTemplates(templateURL, refName, templateName string, overwriteTemplates bool, lockMode templateLockMode) error {
 	if len(templateURL) == 0 {
 		return fmt.Errorf("pass valid templateURL")
 	}
 
+	if isArchiveTemplateSource(templateURL) {
+		return fetchTemplateArchive(templateURL, templateName, overwriteTemplates, lockMode)
+	}
+
 	refMsg := ""
 	if len(refName) > 0 {
 		refMsg = " [" + refName + "]"
@@ -115,6 +121,33 @@ func fetchTemplates(templateURL, refName, templateName string, overwriteTemplate
 		return err
 	}
 
+	return writeFetchedTemplates(extractedPath, templateURL, refName, sha, templateName, overwriteTemplates, lockMode)
+}
+
+// writeFetchedTemplates verifies the templates extracted to extractedPath
+// against the templates.lock, then copies them to ./template and records
+// them in the lock.
+func writeFetchedTemplates(extractedPath, templateURL, refName, sha, templateName string, overwriteTemplates bool, lockMode templateLockMode) error {
+	lock, err := loadTemplateLock(templateLockPath())
+	if err != nil {
+		return err
+	}
+
+	names, err := extractedTemplateNames(extractedPath, templateName, refName)
+	if err != nil {
+		return err
+	}
+
+	fetched := make([]lockedTemplate, 0, len(names))
+	for _, name := range names {
+		fetched = append(fetched, lockedTemplate{Name: name, Repository: templateURL, RefName: refName, Sha: sha})
+	}
+
+	// Nothing is written to ./template when a template has drifted
+	if err := lockTemplates(lock, lockMode, fetched); err != nil {
+		return err
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("can't get current working directory: %s", err)
@@ -137,6 +170,12 @@ func fetchTemplates(templateURL, refName, templateName string, overwriteTemplate
 
 	fmt.Printf("Wrote %d template(s) : %v\n", len(fetchedLanguages), fetchedLanguages)
 
+	if lockMode != lockIfExists || lock.exists {
+		if err := lock.Save(); err != nil {
+			return err
+		}
+	}
+
 	return err
 }
 
@@ -263,7 +302,30 @@ func writeTemplateMeta(languageDest, repository, refName, sha string) error {
 	return nil
 }
 
-func pullTemplate(repository, templateName string, overwriteTemplates bool) error {
+// readTemplateMeta reads the meta.json written when the template was pulled.
+func readTemplateMeta(languageDest string) (TemplateMeta, error) {
+	var templateMeta TemplateMeta
+
+	metaBytes, err := os.ReadFile(filepath.Join(languageDest, "meta.json"))
+	if err != nil {
+		return templateMeta, err
+	}
+
+	if err := json.Unmarshal(metaBytes, &templateMeta); err != nil {
+		return templateMeta, fmt.Errorf("error parsing template meta: %s", err)
+	}
+
+	return templateMeta, nil
+}
+
+func pullTemplate(repository, templateName string, overwriteTemplates bool, lockMode templateLockMode) error {
+
+	if isArchiveTemplateSource(repository) {
+		if err := fetchTemplates(repository, "", templateName, overwriteTemplates, lockMode); err != nil {
+			return fmt.Errorf("error while fetching templates: %w", err)
+		}
+		return nil
+	}
 
 	baseRepository := repository
 
@@ -299,7 +361,7 @@ func pullTemplate(repository, templateName string, overwriteTemplates bool) erro
 		}
 	}
 
-	if err := fetchTemplates(repository, refName, templateName, overwriteTemplates); err != nil {
+	if err := fetchTemplates(repository, refName, templateName, overwriteTemplates, lockMode); err != nil {
 		return fmt.Errorf("error while fetching templates: %w", err)
 	}
 
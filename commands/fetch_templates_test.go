@@ -33,7 +33,7 @@ func Test_PullTemplates(t *testing.T) {
 	t.Run("fetchTemplates with master ref", func(t *testing.T) {
 		defer tearDownFetchTemplates(t)
 
-		if err := fetchTemplates(localTemplateRepository, "master", templateName, overwrite); err != nil {
+		if err := fetchTemplates(localTemplateRepository, "master", templateName, overwrite, lockIfExists); err != nil {
 			t.Fatal(err)
 		}
 
@@ -43,7 +43,7 @@ func Test_PullTemplates(t *testing.T) {
 		defer tearDownFetchTemplates(t)
 
 		templateName := ""
-		if err := fetchTemplates(localTemplateRepository, "", templateName, overwrite); err != nil {
+		if err := fetchTemplates(localTemplateRepository, "", templateName, overwrite, lockIfExists); err != nil {
 			t.Error(err)
 		}
 
@@ -123,4 +123,29 @@ func tearDownFetchTemplates(t *testing.T) {
 	} else {
 		t.Logf("Template directory %s was not created: %s", fullPath, err)
 	}
+
+	if err := os.Remove(filepath.Join(cwd, TemplateLockFile)); err != nil && !os.IsNotExist(err) {
+		t.Log(err)
+	}
+}
+
+func Test_readTemplateMeta(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := writeTemplateMeta(dir, "https://github.com/openfaas/templates", "main", "4b6e6d1"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	meta, err := readTemplateMeta(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if meta.Repository != "https://github.com/openfaas/templates" || meta.RefName != "main" || meta.Sha != "4b6e6d1" {
+		t.Errorf("unexpected template meta: %+v", meta)
+	}
+
+	if _, err := readTemplateMeta(t.TempDir()); err == nil {
+		t.Errorf("want an error when meta.json is missing")
+	}
 }
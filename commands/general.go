@@ -2,6 +2,7 @@ package commands
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
 	"github.com/openfaas/go-sdk"
 )
 
@@ -57,7 +59,16 @@ func GetDefaultSDKClient() (*sdk.Client, error) {
 
 	authConfig, err := config.LookupAuthConfig(gatewayURL.String())
 	if err != nil {
-		fmt.Printf("Failed to lookup auth config: %s\n", err)
+		var storeErr *config.CredentialStoreError
+		if errors.As(err, &storeErr) {
+			if len(token) == 0 {
+				return nil, err
+			}
+			// The saved credentials are not needed when a token is given
+			authConfig = config.AuthConfig{}
+		} else {
+			fmt.Printf("Failed to lookup auth config: %s\n", err)
+		}
 	}
 
 	var clientAuth sdk.ClientAuth
@@ -74,6 +85,15 @@ func GetDefaultSDKClient() (*sdk.Client, error) {
 		}
 	}
 
+	tokenClient := proxy.MakeHTTPClient(&commandTimeout, tlsInsecure)
+
+	if authConfig.Auth == config.Oauth2AuthType && len(token) == 0 {
+		authConfig, err = proxy.RefreshOIDCAuthConfig(&tokenClient, authConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if authConfig.Auth == config.Oauth2AuthType {
 		tokenAuth := &StaticTokenAuth{
 			token: authConfig.Token,
@@ -83,6 +103,16 @@ func GetDefaultSDKClient() (*sdk.Client, error) {
 		functionTokenSource = tokenAuth
 	}
 
+	if (authConfig.Auth == config.ClientCredentialsAuthType || authConfig.Auth == config.ServiceAccountAuthType) && len(token) == 0 {
+		tokenAuth, err := proxy.NewExchangeTokenAuth(authConfig, &tokenClient)
+		if err != nil {
+			return nil, err
+		}
+
+		clientAuth = tokenAuth
+		functionTokenSource = tokenAuth
+	}
+
 	// User specified token gets priority
 	if len(token) > 0 {
 		tokenAuth := &StaticTokenAuth{
@@ -38,6 +38,7 @@ var (
 	desiredArch          string
 	annotationArgs       []string
 	labelArgs            []string
+	generatePinDigest    bool
 )
 
 func init() {
@@ -52,6 +53,7 @@ func init() {
 	generateCmd.Flags().StringVar(&desiredArch, "arch", "x86_64", "Desired image arch. (Default x86_64)")
 	generateCmd.Flags().StringArrayVar(&annotationArgs, "annotation", []string{}, "Any annotations you want to add (to store functions only)")
 	generateCmd.Flags().StringArrayVar(&labelArgs, "label", []string{}, "Any labels you want to add (to store functions only)")
+	generateCmd.Flags().BoolVar(&generatePinDigest, "pin-digest", false, "Resolve each image to its digest in the registry and write image@sha256:...")
 
 	faasCmd.AddCommand(generateCmd)
 }
@@ -64,7 +66,8 @@ var generateCmd = &cobra.Command{
   faas-cli generate --api=openfaas.com/v1 -f stack.yaml
   faas-cli generate --api=serving.knative.dev/v1 -f stack.yaml
   faas-cli generate --api=openfaas.com/v1 --namespace openfaas-fn -f stack.yaml
-  faas-cli generate --api=openfaas.com/v1 -f stack.yaml --tag branch -n openfaas-fn`,
+  faas-cli generate --api=openfaas.com/v1 -f stack.yaml --tag branch -n openfaas-fn
+  faas-cli generate --api=openfaas.com/v1 -f stack.yaml --pin-digest`,
 	PreRunE: preRunGenerate,
 	RunE:    runGenerate,
 }
@@ -173,7 +176,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		}
 
 	} else if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -190,8 +193,13 @@ Alternatively, to generate a definition for store functions, use "--from-store"`
 		os.Exit(1)
 	}
 
+	var resolve imageResolver
+	if generatePinDigest {
+		resolve = newImageResolver(cmd.Context())
+	}
+
 	objectsString, err := generateCRDYAML(services, tagFormat, api, crdFunctionNamespace,
-		builder.NewFunctionMetadataSourceLive())
+		builder.NewFunctionMetadataSourceLive(), resolve)
 	if err != nil {
 		return err
 	}
@@ -202,15 +210,16 @@ Alternatively, to generate a definition for store functions, use "--from-store"`
 	return nil
 }
 
-// generateCRDYAML generates CRD YAML for functions
-func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace string, metadataSource builder.FunctionMetadataSource) (string, error) {
+// generateCRDYAML generates CRD YAML for functions, images are pinned to
+// their digest when resolve is set
+func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace string, metadataSource builder.FunctionMetadataSource, resolve imageResolver) (string, error) {
 
 	var objectsString string
 
 	if len(services.Functions) > 0 {
 
 		if apiVersion == knativev1.APIVersionLatest {
-			return generateknativev1ServingServiceCRDYAML(services, format, api, crdFunctionNamespace)
+			return generateknativev1ServingServiceCRDYAML(services, format, api, crdFunctionNamespace, resolve)
 		}
 
 		orderedNames := generateFunctionOrder(services.Functions)
@@ -236,7 +245,10 @@ func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVers
 			}
 
 			metadata := schema.Metadata{Name: name, Namespace: namespace}
-			imageName := schema.BuildImageName(format, function.Image, version, branch)
+			imageName, err := generatedImageName(resolve, schema.BuildImageName(format, function.Image, version, branch))
+			if err != nil {
+				return "", err
+			}
 
 			spec := openfaasv1.Spec{
 				Name:                   name,
@@ -274,7 +286,7 @@ func generateCRDYAML(services stack.Services, format schema.BuildFormat, apiVers
 	return objectsString, nil
 }
 
-func generateknativev1ServingServiceCRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace string) (string, error) {
+func generateknativev1ServingServiceCRDYAML(services stack.Services, format schema.BuildFormat, apiVersion, namespace string, resolve imageResolver) (string, error) {
 	crds := []knativev1.ServingServiceCRD{}
 
 	orderedNames := generateFunctionOrder(services.Functions)
@@ -307,7 +319,10 @@ func generateknativev1ServingServiceCRDYAML(services stack.Services, format sche
 			return "", err
 		}
 
-		imageName := schema.BuildImageName(format, function.Image, version, branch)
+		imageName, err := generatedImageName(resolve, schema.BuildImageName(format, function.Image, version, branch))
+		if err != nil {
+			return "", err
+		}
 
 		crd := knativev1.ServingServiceCRD{
 			Metadata: schema.Metadata{
@@ -401,3 +416,11 @@ func orderknativeEnv(environment map[string]string) []knativev1.EnvPair {
 
 	return envVars
 }
+
+// generatedImageName pins the image to its digest when resolve is set.
+func generatedImageName(resolve imageResolver, imageName string) (string, error) {
+	if resolve == nil {
+		return imageName, nil
+	}
+	return resolve(imageName)
+}
@@ -248,7 +248,7 @@ func Test_generateCRDYAML(t *testing.T) {
 		services := *parsedServices
 
 		generatedYAML, err := generateCRDYAML(services, testcase.Format, testcase.APIVersion, testcase.Namespace,
-			NewFunctionMetadataSourceStub(testcase.Branch, testcase.Version))
+			NewFunctionMetadataSourceStub(testcase.Branch, testcase.Version), nil)
 		if err != nil {
 			t.Fatalf("%s failed: error while generating CRD YAML", testcase.Name)
 		}
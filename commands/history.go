@@ -0,0 +1,91 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	historyCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	historyCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	historyCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yaml file")
+
+	faasCmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   `history FUNCTION_NAME [--gateway GATEWAY_URL] [--namespace NAMESPACE]`,
+	Short: "List the recorded deployments of a function",
+	Long: `List the revisions of a function recorded by faas-cli each time it was
+deployed successfully. The history is kept per gateway and namespace in the
+faas-cli config directory. Use "faas-cli rollback" to redeploy a revision.`,
+	Example: `  faas-cli history figlet
+  faas-cli history figlet --gateway https://openfaas.example.com
+  faas-cli history figlet --namespace staging-fn`,
+	RunE: runHistory,
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("please provide a name for the function")
+	}
+
+	gatewayAddress, err := historyGatewayURL()
+	if err != nil {
+		return err
+	}
+
+	history, err := loadDeployHistory(gatewayAddress, functionNamespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(history.Revisions) == 0 {
+		fmt.Printf("No deployments recorded for %s\n", args[0])
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tDEPLOYED\tIMAGE")
+	for i, revision := range history.Revisions {
+		current := ""
+		if i == len(history.Revisions)-1 {
+			current = " (current)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s%s\n",
+			revision.Revision,
+			revision.Deployed.Local().Format(time.RFC3339),
+			revision.Spec.Image,
+			current)
+	}
+
+	return w.Flush()
+}
+
+// historyGatewayURL resolves the gateway in the same way as deploy, so that
+// the history recorded during a deploy is found again.
+func historyGatewayURL() (string, error) {
+	var yamlGateway string
+	if len(yamlFile) > 0 {
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
+		if err != nil {
+			return "", err
+		}
+
+		var services stack.Services
+		if parsedServices != nil {
+			services = *parsedServices
+			yamlGateway = services.Provider.GatewayURL
+		}
+	}
+
+	return getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment)), nil
+}
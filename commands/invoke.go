@@ -14,10 +14,10 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/alexellis/hmac/v2"
 	"github.com/openfaas/faas-cli/version"
-	"github.com/openfaas/go-sdk/stack"
 	"github.com/spf13/cobra"
 )
 
@@ -54,6 +54,16 @@ func init() {
 
 	invokeCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yaml file")
 
+	invokeCmd.Flags().BoolVar(&invokeBench, "bench", false, "Load test the function by sending the same request repeatedly and report the results")
+	invokeCmd.Flags().IntVarP(&benchConcurrency, "concurrency", "c", 10, "Number of concurrent workers sending requests, when used with --bench")
+	invokeCmd.Flags().IntVar(&benchRequests, "requests", 100, "Total number of requests to send, when used with --bench")
+	invokeCmd.Flags().StringVar(&benchRate, "rate", "", "Maximum request rate such as 100/s or 600/m, when used with --bench")
+	invokeCmd.Flags().StringVarP(&benchOutputFormat, "output", "o", benchOutputTable, "Output format for the --bench report: table or json")
+
+	invokeCmd.Flags().StringVar(&invokeRecordDir, "record", "", "Save the request, response and timing of the invocation as a fixture in this directory")
+	invokeCmd.Flags().StringVar(&invokeReplayDir, "replay", "", "Re-send the fixtures in this directory and compare the responses with the recorded ones")
+	invokeCmd.Flags().StringVar(&invokeReplayURL, "replay-url", "", "Send replayed fixtures to this URL instead of the gateway, such as a function started with local-run")
+
 	faasCmd.AddCommand(invokeCmd)
 }
 
@@ -68,11 +78,16 @@ var invokeCmd = &cobra.Command{
   faas-cli invoke resize-img --async -H "X-Callback-Url: http://gateway:8080/function/send2slack" < image.png
   faas-cli invoke env -H X-Ping-Url: http://request.bin/etc
   faas-cli invoke flask --method GET --namespace dev
-  faas-cli invoke env --sign X-GitHub-Event --key yoursecret`,
+  faas-cli invoke env --sign X-GitHub-Event --key yoursecret
+  faas-cli invoke env --bench -c 20 --requests 5000 --rate 100/s < /dev/null
+  faas-cli invoke env --bench --requests 1000 -o json <<< "Hello"
+  faas-cli invoke env --record fixtures/ <<< "Hello"
+  faas-cli invoke --replay fixtures/
+  faas-cli invoke --replay fixtures/ --replay-url http://127.0.0.1:8080`,
 	RunE: runInvoke,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if len(yamlFile) > 0 {
-			parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+			parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 			if err != nil {
 				return err
 			}
@@ -84,6 +99,13 @@ var invokeCmd = &cobra.Command{
 }
 
 func runInvoke(cmd *cobra.Command, args []string) error {
+	if len(invokeReplayDir) > 0 {
+		if invokeBench || len(invokeRecordDir) > 0 {
+			return fmt.Errorf("--replay cannot be used with --record or --bench")
+		}
+		return runInvokeReplay(args)
+	}
+
 	if len(args) < 1 {
 		return fmt.Errorf("please provide a name for the function")
 	}
@@ -103,6 +125,18 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("signing requires both --sign <header-value> and --key <key-value>")
 	}
 
+	if invokeBench {
+		if len(invokeRecordDir) > 0 {
+			return fmt.Errorf("--record cannot be used with --bench")
+		}
+		if benchConcurrency < 1 || benchRequests < 1 {
+			return fmt.Errorf("--concurrency and --requests must be greater than 0")
+		}
+		if err := validBenchOutput(benchOutputFormat); err != nil {
+			return err
+		}
+	}
+
 	err := validateHTTPMethod(httpMethod)
 	if err != nil {
 		return nil
@@ -147,6 +181,10 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	u, _ := url.Parse("/")
 	u.RawQuery = httpQuery.Encode()
 
+	if invokeBench {
+		return runInvokeBench(client, u.String(), httpHeader, functionInput)
+	}
+
 	body := bytes.NewReader(functionInput)
 	req, err := http.NewRequest(httpMethod, u.String(), body)
 	if err != nil {
@@ -154,6 +192,7 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	}
 	req.Header = httpHeader
 
+	started := time.Now()
 	res, err := client.InvokeFunction(functionName, functionNamespace, invokeAsync, authenticate, req)
 	if err != nil {
 		return fmt.Errorf("failed to invoke function: %s", err)
@@ -193,6 +232,12 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(invokeRecordDir) > 0 {
+		if err := recordInvokeResponse(res, started, httpHeader, httpQuery, functionInput); err != nil {
+			return err
+		}
+	}
+
 	if code := res.StatusCode; code < 200 || code > 299 {
 		resBody, err := io.ReadAll(res.Body)
 		if err != nil {
@@ -0,0 +1,334 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openfaas/go-sdk"
+)
+
+const (
+	benchOutputTable = "table"
+	benchOutputJSON  = "json"
+)
+
+var (
+	invokeBench       bool
+	benchConcurrency  int
+	benchRequests     int
+	benchRate         string
+	benchOutputFormat string
+)
+
+// benchInvoker sends a single invocation and returns the HTTP status code.
+type benchInvoker func() (int, error)
+
+// benchOptions configures a load test started with "faas-cli invoke --bench".
+type benchOptions struct {
+	Concurrency int
+	Requests    int
+
+	// Rate is the maximum number of requests started per second,
+	// 0 means no limit.
+	Rate float64
+}
+
+type benchResult struct {
+	sent       bool
+	latency    time.Duration
+	statusCode int
+	err        error
+}
+
+// benchReport is written by "faas-cli invoke --bench -o json". Latencies
+// are in milliseconds.
+type benchReport struct {
+	Function    string         `json:"function"`
+	Requests    int            `json:"requests"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	Errors      int            `json:"errors"`
+	Concurrency int            `json:"concurrency"`
+	DurationMs  float64        `json:"durationMs"`
+	Throughput  float64        `json:"throughput"`
+	Latency     benchLatency   `json:"latency"`
+	StatusCodes map[string]int `json:"statusCodes"`
+}
+
+type benchLatency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// parseBenchRate parses a rate such as "100", "100/s" or "600/m" into
+// requests per second.
+func parseBenchRate(rate string) (float64, error) {
+	if len(rate) == 0 {
+		return 0, nil
+	}
+
+	value, unit, _ := strings.Cut(rate, "/")
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("the --rate flag must be a positive number of requests, e.g. 100/s, got: %q", rate)
+	}
+
+	switch strings.TrimSpace(unit) {
+	case "", "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	}
+
+	return 0, fmt.Errorf("the --rate flag must use a unit of s, m or h, e.g. 100/s, got: %q", rate)
+}
+
+func validBenchOutput(output string) error {
+	switch output {
+	case benchOutputTable, benchOutputJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown --output %q, valid options are: %s, %s", output, benchOutputTable, benchOutputJSON)
+}
+
+// runBench sends opts.Requests invocations from opts.Concurrency workers and
+// records the latency and status code of each.
+func runBench(ctx context.Context, opts benchOptions, invoke benchInvoker) ([]benchResult, time.Duration) {
+	results := make([]benchResult, opts.Requests)
+
+	var tokens <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	var next int64 = -1
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= opts.Requests {
+					return
+				}
+
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				began := time.Now()
+				statusCode, err := invoke()
+				results[i] = benchResult{
+					sent:       true,
+					latency:    time.Since(began),
+					statusCode: statusCode,
+					err:        err,
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, time.Since(start)
+}
+
+// runInvokeBench load tests the function with the request built by invoke
+// and prints a report of the results.
+func runInvokeBench(client *sdk.Client, target string, header http.Header, body []byte) error {
+	rate, err := parseBenchRate(benchRate)
+	if err != nil {
+		return err
+	}
+
+	opts := benchOptions{
+		Concurrency: benchConcurrency,
+		Requests:    benchRequests,
+		Rate:        rate,
+	}
+
+	invoke := func(req *http.Request) (*http.Response, error) {
+		return client.InvokeFunction(functionName, functionNamespace, invokeAsync, authenticate, req)
+	}
+
+	if benchOutputFormat == benchOutputTable {
+		fmt.Fprintf(os.Stderr, "Sending %d requests to %s with %d workers\n", opts.Requests, functionName, opts.Concurrency)
+	}
+
+	results, elapsed := runBench(context.Background(), opts, newBenchInvoker(invoke, httpMethod, target, header, body))
+	report := summariseBench(functionName, opts.Concurrency, results, elapsed)
+
+	if err := printBenchReport(os.Stdout, report, benchOutputFormat); err != nil {
+		return err
+	}
+
+	if report.Succeeded == 0 {
+		return fmt.Errorf("none of the %d requests to %s succeeded", report.Requests, functionName)
+	}
+
+	return nil
+}
+
+// newBenchInvoker sends each request through the same SDK client as a
+// regular invocation, with the headers, query and signature already applied.
+func newBenchInvoker(invoke func(req *http.Request) (*http.Response, error), method, target string, header http.Header, body []byte) benchInvoker {
+	return func() (int, error) {
+		req, err := http.NewRequest(method, target, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header = header.Clone()
+
+		res, err := invoke(req)
+		if err != nil {
+			return 0, err
+		}
+		if res.Body != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		return res.StatusCode, nil
+	}
+}
+
+func summariseBench(functionName string, concurrency int, results []benchResult, elapsed time.Duration) benchReport {
+	report := benchReport{
+		Function:    functionName,
+		Concurrency: concurrency,
+		DurationMs:  durationMs(elapsed),
+		StatusCodes: make(map[string]int),
+	}
+
+	var latencies []time.Duration
+	var total time.Duration
+	for _, result := range results {
+		// Requests are not sent when the run is cancelled
+		if !result.sent {
+			continue
+		}
+
+		report.Requests++
+		latencies = append(latencies, result.latency)
+		total += result.latency
+
+		switch {
+		case result.err != nil:
+			report.Errors++
+			report.StatusCodes["error"]++
+		case result.statusCode >= 200 && result.statusCode <= 299:
+			report.Succeeded++
+			report.StatusCodes[strconv.Itoa(result.statusCode)]++
+		default:
+			report.Failed++
+			report.StatusCodes[strconv.Itoa(result.statusCode)]++
+		}
+	}
+
+	if elapsed > 0 {
+		report.Throughput = math.Round(float64(report.Requests)/elapsed.Seconds()*100) / 100
+	}
+
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		report.Latency = benchLatency{
+			Min:  durationMs(latencies[0]),
+			Mean: durationMs(total / time.Duration(len(latencies))),
+			P50:  durationMs(percentile(latencies, 50)),
+			P90:  durationMs(percentile(latencies, 90)),
+			P95:  durationMs(percentile(latencies, 95)),
+			P99:  durationMs(percentile(latencies, 99)),
+			Max:  durationMs(latencies[len(latencies)-1]),
+		}
+	}
+
+	return report
+}
+
+// percentile uses the nearest-rank method on sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func durationMs(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
+
+func printBenchReport(w io.Writer, report benchReport, output string) error {
+	if output == benchOutputJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Function:\t%s\n", report.Function)
+	fmt.Fprintf(tw, "Requests:\t%d (%d succeeded, %d failed, %d errors)\n", report.Requests, report.Succeeded, report.Failed, report.Errors)
+	fmt.Fprintf(tw, "Concurrency:\t%d\n", report.Concurrency)
+	fmt.Fprintf(tw, "Duration:\t%.2fs\n", report.DurationMs/1000)
+	fmt.Fprintf(tw, "Throughput:\t%.2f req/s\n", report.Throughput)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "Latency (ms):")
+	fmt.Fprintf(tw, "  min\t%.2f\n", report.Latency.Min)
+	fmt.Fprintf(tw, "  mean\t%.2f\n", report.Latency.Mean)
+	fmt.Fprintf(tw, "  p50\t%.2f\n", report.Latency.P50)
+	fmt.Fprintf(tw, "  p90\t%.2f\n", report.Latency.P90)
+	fmt.Fprintf(tw, "  p95\t%.2f\n", report.Latency.P95)
+	fmt.Fprintf(tw, "  p99\t%.2f\n", report.Latency.P99)
+	fmt.Fprintf(tw, "  max\t%.2f\n", report.Latency.Max)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "Status codes:")
+	codes := make([]string, 0, len(report.StatusCodes))
+	for code := range report.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(tw, "  %s\t%d\n", code, report.StatusCodes[code])
+	}
+
+	return tw.Flush()
+}
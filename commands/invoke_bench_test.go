@@ -0,0 +1,165 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_parseBenchRate(t *testing.T) {
+	cases := []struct {
+		rate    string
+		want    float64
+		wantErr bool
+	}{
+		{rate: "", want: 0},
+		{rate: "100", want: 100},
+		{rate: "100/s", want: 100},
+		{rate: "600/m", want: 10},
+		{rate: "0/s", wantErr: true},
+		{rate: "fast", wantErr: true},
+		{rate: "10/d", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.rate, func(t *testing.T) {
+			got, err := parseBenchRate(tc.rate)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want an error for %q", tc.rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_runBench_sendsAllRequests(t *testing.T) {
+	var calls int64
+	invoke := func() (int, error) {
+		n := atomic.AddInt64(&calls, 1)
+		switch {
+		case n%10 == 0:
+			return 0, errors.New("connection refused")
+		case n%5 == 0:
+			return http.StatusInternalServerError, nil
+		}
+		return http.StatusOK, nil
+	}
+
+	results, elapsed := runBench(context.Background(), benchOptions{Concurrency: 4, Requests: 50}, invoke)
+	report := summariseBench("env", 4, results, elapsed)
+
+	if calls != 50 {
+		t.Fatalf("want 50 invocations, got %d", calls)
+	}
+
+	if report.Requests != 50 || report.Succeeded != 40 || report.Failed != 5 || report.Errors != 5 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+
+	if report.StatusCodes["200"] != 40 || report.StatusCodes["500"] != 5 || report.StatusCodes["error"] != 5 {
+		t.Errorf("unexpected status codes: %v", report.StatusCodes)
+	}
+}
+
+func Test_runBench_rateLimits(t *testing.T) {
+	invoke := func() (int, error) { return http.StatusOK, nil }
+
+	_, elapsed := runBench(context.Background(), benchOptions{Concurrency: 5, Requests: 5, Rate: 50}, invoke)
+
+	// 5 requests at 50/s cannot start in under 100ms
+	if elapsed < 90*time.Millisecond {
+		t.Errorf("want the rate to be limited, finished in %s", elapsed)
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	for p, want := range map[float64]time.Duration{
+		50: 50 * time.Millisecond,
+		90: 90 * time.Millisecond,
+		99: 99 * time.Millisecond,
+		0:  1 * time.Millisecond,
+	} {
+		if got := percentile(latencies, p); got != want {
+			t.Errorf("p%v: want %s, got %s", p, want, got)
+		}
+	}
+}
+
+func Test_invoke_benchJSON(t *testing.T) {
+	defer resetForTest()
+
+	var calls int64
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		if r.URL.Path != "/function/env.openfaas-fn" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	os.Stdin, _ = os.CreateTemp("", "stdin")
+	os.Stdin.WriteString("test-data")
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+	}()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"env",
+			"--gateway=" + s.URL,
+			"--bench",
+			"-c=3",
+			"--requests=20",
+			"-o=json",
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("want no error, got: %s\n%s", err, stdOut)
+	}
+
+	// Skip any warnings printed before the report
+	start := strings.Index(stdOut, "{")
+	if start < 0 {
+		t.Fatalf("no JSON in output:\n%s", stdOut)
+	}
+
+	var report benchReport
+	if err := json.Unmarshal([]byte(stdOut[start:]), &report); err != nil {
+		t.Fatalf("output is not JSON: %s\n%s", err, stdOut)
+	}
+
+	if calls != 20 || report.Requests != 20 || report.Succeeded != 20 {
+		t.Errorf("want 20 successful requests, got %d calls and report: %+v", calls, report)
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openfaas/go-sdk"
+)
+
+var (
+	invokeRecordDir string
+	invokeReplayDir string
+	invokeReplayURL string
+)
+
+// invocationFixture is a recorded invocation written by
+// "faas-cli invoke --record" and re-sent by "faas-cli invoke --replay".
+type invocationFixture struct {
+	Function  string          `json:"function"`
+	Namespace string          `json:"namespace,omitempty"`
+	Async     bool            `json:"async,omitempty"`
+	Request   fixtureRequest  `json:"request"`
+	Response  fixtureResponse `json:"response"`
+	Timing    fixtureTiming   `json:"timing"`
+}
+
+type fixtureRequest struct {
+	Method  string      `json:"method"`
+	Query   string      `json:"query,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    fixtureBody `json:"body"`
+}
+
+type fixtureResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    fixtureBody `json:"body"`
+}
+
+// fixtureBody holds text bodies as-is and binary bodies as base64, in the
+// same way as the "text" and "encoding" fields of a HAR file.
+type fixtureBody struct {
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type fixtureTiming struct {
+	Started    time.Time `json:"started"`
+	DurationMs float64   `json:"durationMs"`
+}
+
+// fixtureIgnoredHeaders are not written to fixtures as they hold credentials.
+var fixtureIgnoredHeaders = []string{"Authorization", "Cookie"}
+
+func newFixtureBody(data []byte) fixtureBody {
+	if utf8.Valid(data) {
+		return fixtureBody{Text: string(data)}
+	}
+	return fixtureBody{Text: base64.StdEncoding.EncodeToString(data), Encoding: "base64"}
+}
+
+func (b fixtureBody) bytes() ([]byte, error) {
+	if b.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(b.Text)
+	}
+	return []byte(b.Text), nil
+}
+
+func fixtureHeaders(header http.Header) http.Header {
+	h := header.Clone()
+	for _, name := range fixtureIgnoredHeaders {
+		h.Del(name)
+	}
+	return h
+}
+
+// recordInvocation writes the fixture to dir, named after the function and
+// the time of the invocation so that fixtures are replayed in order.
+func recordInvocation(dir string, fixture invocationFixture) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create fixtures directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%s.json", fixture.Function, fixture.Timing.Started.UTC().Format("20060102T150405.000000000"))
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("unable to write fixture %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// loadFixtures reads every fixture in dir, sorted by file name.
+func loadFixtures(dir string) ([]string, []invocationFixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+
+	fixtures := make([]invocationFixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read fixture %s: %w", path, err)
+		}
+
+		var fixture invocationFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse fixture %s: %w", path, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return paths, fixtures, nil
+}
+
+// fixtureSender sends a recorded request and returns the response.
+type fixtureSender func(fixture invocationFixture, req *http.Request) (*http.Response, error)
+
+// gatewayFixtureSender sends fixtures through the gateway with the same SDK
+// client as a regular invocation.
+func gatewayFixtureSender(client *sdk.Client, functionName, namespace string) fixtureSender {
+	return func(fixture invocationFixture, req *http.Request) (*http.Response, error) {
+		name := fixture.Function
+		if len(functionName) > 0 {
+			name = functionName
+		}
+
+		ns := fixture.Namespace
+		if len(namespace) > 0 {
+			ns = namespace
+		}
+
+		return client.InvokeFunction(name, ns, fixture.Async, authenticate, req)
+	}
+}
+
+// directFixtureSender sends fixtures straight to a function's URL, such as
+// the port of a function started with "faas-cli local-run".
+func directFixtureSender(target string, httpClient *http.Client) (fixtureSender, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --replay-url %q: %w", target, err)
+	}
+
+	return func(fixture invocationFixture, req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = u.Scheme
+		req.URL.Host = u.Host
+		req.URL.Path = u.Path
+		return httpClient.Do(req)
+	}, nil
+}
+
+// replayFixtures re-sends each fixture and compares the status code,
+// Content-Type and body of the response with the recorded one. It returns
+// the number of responses which differed.
+func replayFixtures(w io.Writer, paths []string, fixtures []invocationFixture, send fixtureSender) (int, error) {
+	failed := 0
+
+	for i, fixture := range fixtures {
+		name := filepath.Base(paths[i])
+
+		body, err := fixture.Request.Body.bytes()
+		if err != nil {
+			return failed, fmt.Errorf("unable to decode request body of %s: %w", name, err)
+		}
+
+		req, err := http.NewRequest(fixture.Request.Method, "/?"+fixture.Request.Query, bytes.NewReader(body))
+		if err != nil {
+			return failed, fmt.Errorf("unable to create request for %s: %w", name, err)
+		}
+		if fixture.Request.Headers != nil {
+			req.Header = fixture.Request.Headers.Clone()
+		}
+
+		started := time.Now()
+		res, err := send(fixture, req)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL %s: %s\n", name, err)
+			continue
+		}
+
+		resBody, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return failed, fmt.Errorf("unable to read response for %s: %w", name, err)
+		}
+		duration := time.Since(started)
+
+		differences := compareFixtureResponse(fixture, res, resBody)
+		if len(differences) == 0 {
+			fmt.Fprintf(w, "PASS %s (%d, %.2fms, recorded %.2fms)\n", name, res.StatusCode, durationMs(duration), fixture.Timing.DurationMs)
+			continue
+		}
+
+		failed++
+		fmt.Fprintf(w, "FAIL %s\n", name)
+		for _, line := range differences {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+
+	return failed, nil
+}
+
+func compareFixtureResponse(fixture invocationFixture, res *http.Response, body []byte) []string {
+	var differences []string
+
+	if res.StatusCode != fixture.Response.Status {
+		differences = append(differences, fmt.Sprintf("status: %d -> %d", fixture.Response.Status, res.StatusCode))
+	}
+
+	recordedType := fixture.Response.Headers.Get("Content-Type")
+	if replayedType := res.Header.Get("Content-Type"); recordedType != replayedType {
+		differences = append(differences, fmt.Sprintf("Content-Type: %q -> %q", recordedType, replayedType))
+	}
+
+	recordedBody, err := fixture.Response.Body.bytes()
+	if err != nil {
+		return append(differences, fmt.Sprintf("unable to decode recorded body: %s", err))
+	}
+
+	if !bytes.Equal(recordedBody, body) {
+		differences = append(differences, "body:")
+		differences = append(differences, diffBodyLines(recordedBody, body)...)
+	}
+
+	return differences
+}
+
+// diffBodyLines compares the bodies line by line, with - for the recorded
+// response and + for the replayed response.
+func diffBodyLines(recorded, replayed []byte) []string {
+	if !utf8.Valid(recorded) || !utf8.Valid(replayed) {
+		return []string{fmt.Sprintf("binary body differs: %d bytes -> %d bytes", len(recorded), len(replayed))}
+	}
+
+	left := strings.Split(strings.TrimSuffix(string(recorded), "\n"), "\n")
+	right := strings.Split(strings.TrimSuffix(string(replayed), "\n"), "\n")
+
+	var lines []string
+	for i := 0; i < len(left) || i < len(right); i++ {
+		switch {
+		case i >= len(right):
+			lines = append(lines, "-"+left[i])
+		case i >= len(left):
+			lines = append(lines, "+"+right[i])
+		case left[i] != right[i]:
+			lines = append(lines, "-"+left[i], "+"+right[i])
+		}
+	}
+
+	return lines
+}
+
+// recordInvokeResponse saves the invocation to the --record directory. The
+// response body is read in full and replaced, so it can still be printed.
+func recordInvokeResponse(res *http.Response, started time.Time, header http.Header, query url.Values, body []byte) error {
+	duration := time.Since(started)
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read result from OpenFaaS on URL: %s %s", gateway, err)
+	}
+	_ = res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	fixture := invocationFixture{
+		Function:  functionName,
+		Namespace: functionNamespace,
+		Async:     invokeAsync,
+		Request: fixtureRequest{
+			Method:  httpMethod,
+			Query:   query.Encode(),
+			Headers: fixtureHeaders(header),
+			Body:    newFixtureBody(body),
+		},
+		Response: fixtureResponse{
+			Status:  res.StatusCode,
+			Headers: fixtureHeaders(res.Header),
+			Body:    newFixtureBody(resBody),
+		},
+		Timing: fixtureTiming{
+			Started:    started.UTC(),
+			DurationMs: durationMs(duration),
+		},
+	}
+
+	path, err := recordInvocation(invokeRecordDir, fixture)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Recorded invocation to: %s\n", path)
+	return nil
+}
+
+// runInvokeReplay re-sends the fixtures in the --replay directory and reports
+// any responses which differ from the recording.
+func runInvokeReplay(args []string) error {
+	paths, fixtures, err := loadFixtures(invokeReplayDir)
+	if err != nil {
+		return err
+	}
+
+	var send fixtureSender
+	if len(invokeReplayURL) > 0 {
+		httpClient := &http.Client{Timeout: commandTimeout}
+		if transport := GetDefaultCLITransport(tlsInsecure, &commandTimeout); transport != nil {
+			httpClient.Transport = transport
+		}
+
+		send, err = directFixtureSender(invokeReplayURL, httpClient)
+		if err != nil {
+			return err
+		}
+	} else {
+		client, err := GetDefaultSDKClient()
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		send = gatewayFixtureSender(client, name, functionInvokeNamespace)
+	}
+
+	failed, err := replayFixtures(os.Stdout, paths, fixtures, send)
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d replayed responses differ from the recording", failed, len(fixtures))
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_fixtureBody_roundTrip(t *testing.T) {
+	for _, data := range [][]byte{[]byte("hello world"), {0xff, 0xfe, 0x00, 0x01}} {
+		body := newFixtureBody(data)
+		got, err := body.bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(data) {
+			t.Errorf("want %v, got %v", data, got)
+		}
+	}
+
+	if body := newFixtureBody([]byte{0xff}); body.Encoding != "base64" {
+		t.Errorf("want binary body to be base64 encoded, got %q", body.Encoding)
+	}
+}
+
+func Test_diffBodyLines(t *testing.T) {
+	got := diffBodyLines([]byte("a\nb\nc\n"), []byte("a\nB\nc\nd\n"))
+	want := []string{"-b", "+B", "+d"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_invoke_recordAndReplay(t *testing.T) {
+	defer resetForTest()
+	defer func() {
+		headers = nil
+	}()
+
+	reply := "hello"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/function/env.openfaas-fn" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, reply)
+	}))
+	defer s.Close()
+
+	dir := t.TempDir()
+
+	os.Stdin, _ = os.CreateTemp("", "stdin")
+	os.Stdin.WriteString("test-data")
+	os.Stdin.Seek(0, 0)
+	defer func() {
+		os.Remove(os.Stdin.Name())
+	}()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"env",
+			"--gateway=" + s.URL,
+			"--header=Authorization: Bearer secret",
+			"--record=" + dir,
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("want no error recording, got: %s", err)
+	}
+	if !strings.Contains(stdOut, "hello") {
+		t.Errorf("want response to be printed while recording, got:\n%s", stdOut)
+	}
+
+	paths, fixtures, err := loadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("want 1 fixture, got %d", len(fixtures))
+	}
+	if !strings.HasPrefix(filepath.Base(paths[0]), "env-") {
+		t.Errorf("want fixture to be named after the function, got %s", paths[0])
+	}
+
+	fixture := fixtures[0]
+	if fixture.Request.Body.Text != "test-data" || fixture.Response.Body.Text != "hello" || fixture.Response.Status != http.StatusOK {
+		t.Errorf("unexpected fixture: %+v", fixture)
+	}
+	if fixture.Request.Headers.Get("Authorization") != "" {
+		t.Errorf("credentials should not be recorded")
+	}
+
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"--gateway=" + s.URL,
+			"--record=",
+			"--replay=" + dir,
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("want replay to pass, got: %s\n%s", err, stdOut)
+	}
+	if !strings.Contains(stdOut, "PASS env-") {
+		t.Errorf("want PASS in output, got:\n%s", stdOut)
+	}
+
+	reply = "goodbye"
+	stdOut = test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"invoke",
+			"--gateway=" + s.URL,
+			"--replay=" + dir,
+		})
+		err = faasCmd.Execute()
+	})
+	if err == nil {
+		t.Fatalf("want replay to fail when the response changes\n%s", stdOut)
+	}
+	if !strings.Contains(stdOut, "-hello") || !strings.Contains(stdOut, "+goodbye") {
+		t.Errorf("want body diff in output, got:\n%s", stdOut)
+	}
+}
+
+func Test_replayFixtures_directURL(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("repo") != "faas-cli" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer s.Close()
+
+	send, err := directFixtureSender(s.URL, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := []invocationFixture{
+		{
+			Function: "env",
+			Request:  fixtureRequest{Method: http.MethodGet, Query: "repo=faas-cli"},
+			Response: fixtureResponse{
+				Status:  http.StatusOK,
+				Headers: http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+				Body:    fixtureBody{Text: "ok"},
+			},
+		},
+	}
+
+	var out strings.Builder
+	failed, err := replayFixtures(&out, []string{"env-1.json"}, fixtures, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 0 {
+		t.Errorf("want no failures, got:\n%s", out.String())
+	}
+}
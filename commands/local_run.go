@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"os/exec"
 	"os/signal"
@@ -36,6 +37,23 @@ type runOptions struct {
 	output   io.Writer
 	err      io.Writer
 	build    bool
+	all      bool
+	addHosts []string
+
+	queueConcurrency int
+
+	secretFlags   []string
+	sealedSecrets string
+	sealedKey     string
+
+	// secrets are the values from --secret and --sealed-secrets, which
+	// are written to secretsDir for the function being started
+	secrets    map[string][]byte
+	secretsDir string
+
+	detach        bool
+	probeAttempts int
+	probeInterval time.Duration
 }
 
 var opts runOptions
@@ -43,7 +61,7 @@ var opts runOptions
 func newLocalRunCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
-		Use:   `local-run NAME --port PORT -f YAML_FILE [flags from build]`,
+		Use:   `local-run [NAME|--all] --port PORT -f YAML_FILE [flags from build]`,
 		Short: "Start a function with docker for local testing (experimental feature)",
 		Long: `Providing faas-cli build has already been run, this command will use the 
 docker command to start a container on your local machine using its image.
@@ -51,8 +69,19 @@ docker command to start a container on your local machine using its image.
 The function will be bound to the port specified by the --port flag, or 8080
 by default.
 
-There is limited support for secrets, and the function cannot contact other 
-services deployed within your OpenFaaS cluster.`,
+With --all, every function in the stack file is started on a shared docker
+network, behind a local gateway bound to --port, which routes /function/NAME
+and /async-function/NAME. Functions can reach the local gateway through the
+OPENFAAS_URL environment variable. Requests to /async-function/NAME are held
+in an in-memory queue, and the response is posted to the X-Callback-Url.
+
+Secrets are read from the .secrets folder, or given with --secret as a literal
+or an environment variable, or unsealed from a file created by
+"faas-cli secret seal". Secrets given with flags are written to a temporary
+folder, on tmpfs where available, which is removed on exit.
+
+The function cannot contact other services deployed within your OpenFaaS
+cluster.`,
 		Example: `
   # Run a function locally
   faas-cli local-run stronghash
@@ -63,18 +92,48 @@ services deployed within your OpenFaaS cluster.`,
   # Run on a random port
   faas-cli local-run -p 0
 
+  # Run in the background, returning once the function is healthy
+  faas-cli local-run stronghash --detach && curl -s http://127.0.0.1:8080
+
   # Use a custom YAML file other than stack.yaml
   faas-cli local-run stronghash -f ./stronghash.yaml
+
+  # Give secrets as literals, from env vars, or from a sealed file
+  faas-cli local-run stronghash --secret api-key=literal:s3cr3t --secret token=env:TOKEN
+  faas-cli local-run stronghash --sealed-secrets com.openfaas.secrets --sealed-key ./key
+
+  # Run every function in the stack file behind a local gateway,
+  # rebuilding only the function which changed
+  faas-cli local-run --all --watch
+
+  # Then invoke a function asynchronously with a callback
+  faas-cli invoke worker --async -H "X-Callback-Url: http://127.0.0.1:8080/function/receiver"
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 1 {
 				return fmt.Errorf("only one function name is allowed")
 			}
-			_, err := cmd.Flags().GetBool("watch")
+			if opts.all && len(args) > 0 {
+				return fmt.Errorf("give either a function name or --all")
+			}
+			watch, err := cmd.Flags().GetBool("watch")
 			if err != nil {
 				return err
 			}
 
+			if opts.probeAttempts < 1 {
+				return fmt.Errorf("attempts must be greater than 0")
+			}
+
+			if opts.detach {
+				if watch || opts.all {
+					return fmt.Errorf("--detach cannot be used with --watch or --all")
+				}
+				if len(opts.secretFlags) > 0 || len(opts.sealedSecrets) > 0 {
+					return fmt.Errorf("--detach cannot be used with --secret or --sealed-secrets, as they are removed when local-run exits")
+				}
+			}
+
 			return nil
 		},
 		RunE: runLocalRunE,
@@ -88,6 +147,15 @@ services deployed within your OpenFaaS cluster.`,
 	cmd.Flags().StringVar(&opts.network, "network", "", "connect function to an existing network, use 'host' to access other process already running on localhost. When using this, '--port' is ignored, if you have port collisions, you may change the port using '-e port=NEW_PORT'")
 	cmd.Flags().StringToStringVarP(&opts.extraEnv, "env", "e", map[string]string{}, "additional environment variables (ENVVAR=VALUE), use this to experiment with different values for your function")
 	cmd.Flags().BoolVar(&watch, "watch", false, "Watch for changes in files and re-deploy")
+	cmd.Flags().DurationVar(&watchDebounce, "watch-debounce", watchDebounce, "Time to wait for changes to settle before rebuilding with --watch")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "Run every function in the stack file behind a local gateway on --port")
+	cmd.Flags().StringArrayVar(&opts.secretFlags, "secret", []string{}, "Secret for the function as NAME=literal:VALUE or NAME=env:VARIABLE, takes priority over the .secrets folder")
+	cmd.Flags().StringVar(&opts.sealedSecrets, "sealed-secrets", "", "Read secrets from a sealed file, such as com.openfaas.secrets")
+	cmd.Flags().StringVar(&opts.sealedKey, "sealed-key", "key", "Private key from \"faas-cli secret keygen\" to unseal --sealed-secrets")
+	cmd.Flags().BoolVarP(&opts.detach, "detach", "d", false, "Start the function in the background, and return once it passes its health check")
+	cmd.Flags().IntVar(&opts.probeAttempts, "attempts", 60, "Number of attempts to check the function's health and readiness")
+	cmd.Flags().DurationVar(&opts.probeInterval, "interval", time.Second*1, "Interval between attempts in seconds")
+	cmd.Flags().IntVar(&opts.queueConcurrency, "queue-concurrency", 1, "Number of async requests to the local gateway which are processed at once, used with --all")
 
 	build, _, _ := faasCmd.Find([]string{"build"})
 	cmd.Flags().AddFlagSet(build.Flags())
@@ -99,6 +167,16 @@ func runLocalRunE(cmd *cobra.Command, args []string) error {
 
 	watch, _ := cmd.Flags().GetBool("watch")
 
+	secrets, err := loadLocalSecrets(opts.secretFlags, opts.sealedSecrets, opts.sealedKey)
+	if err != nil {
+		return err
+	}
+	opts.secrets = secrets
+
+	if opts.all {
+		return runLocalRunAll(cmd, args, watch)
+	}
+
 	// AE: This doesn't work currently due to the blocking nature of
 	// docker run.
 	// a channel and / or cancellation context will need to be implemented
@@ -144,6 +222,13 @@ func localBuild(cmd *cobra.Command, args []string) error {
 		fmt.Println("Building: " + args[0])
 		if args[0] != "" {
 			filter = args[0]
+			regex = ""
+
+			// --watch selects the functions affected by a change, but
+			// only the named one is run
+			if change := watchChangeFrom(cmd.Context()); !change.all {
+				cmd.SetContext(withWatchChange(cmd.Context(), watchChange{names: []string{args[0]}}))
+			}
 		}
 	}
 
@@ -158,7 +243,7 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 	var services *stack.Services
 
 	if len(name) == 0 {
-		s, err := stack.ParseYAMLFile(yamlFile, "", "", true)
+		s, err := parseYAMLFile(yamlFile, "", "", true)
 		if err != nil {
 			return err
 		}
@@ -178,7 +263,7 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 			for key := range services.Functions {
 				fnList = append(fnList, key)
 			}
-			return fmt.Errorf("give a function name to run: %v", fnList)
+			return fmt.Errorf("give a function name to run, or --all: %v", fnList)
 		}
 
 		for key := range services.Functions {
@@ -186,7 +271,7 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 			break
 		}
 	} else {
-		s, err := stack.ParseYAMLFile(yamlFile, "", name, true)
+		s, err := parseYAMLFile(yamlFile, "", name, true)
 		if err != nil {
 			return err
 		}
@@ -222,6 +307,15 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 		opts.port = randomPort
 	}
 
+	if len(function.Secrets) > 0 && len(opts.secrets) > 0 && !opts.print {
+		dir, cleanup, err := writeLocalSecrets(function.Secrets, opts.secrets)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		opts.secretsDir = dir
+	}
+
 	cmd, err := buildDockerRun(ctx, name, function, opts)
 	if err != nil {
 		return err
@@ -232,6 +326,10 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 		return nil
 	}
 
+	if opts.detach {
+		return runDetached(ctx, cmd, name, opts)
+	}
+
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
@@ -246,10 +344,13 @@ func runFunction(ctx context.Context, name string, opts runOptions) error {
 	errGrp, _ := errgroup.WithContext(grpContext)
 
 	errGrp.Go(func() error {
+		started := time.Now()
 		if err = cmd.Start(); err != nil {
 			return err
 		}
 
+		go reportLocalProbe(grpContext, name, localProbeURL(opts), started, opts)
+
 		if err := cmd.Wait(); err != nil {
 			if strings.Contains(err.Error(), "signal: killed") {
 				return nil
@@ -313,12 +414,21 @@ func removeContainer(name string) {
 
 // buildDockerRun constructs a exec.Cmd from the given stack Function
 func buildDockerRun(ctx context.Context, name string, fnc stack.Function, opts runOptions) (*exec.Cmd, error) {
-	args := []string{"run", "--name", name, "--rm", "-i", fmt.Sprintf("-p=%d:8080", opts.port)}
+	mode := "-i"
+	if opts.detach {
+		mode = "-d"
+	}
+
+	args := []string{"run", "--name", name, "--rm", mode, fmt.Sprintf("-p=%d:8080", opts.port)}
 
 	if opts.network != "" {
 		args = append(args, fmt.Sprintf("--network=%s", opts.network))
 	}
 
+	for _, host := range opts.addHosts {
+		args = append(args, fmt.Sprintf("--add-host=%s", host))
+	}
+
 	fprocess, err := deriveFprocess(fnc)
 	if err != nil {
 		return nil, err
@@ -372,7 +482,9 @@ func buildDockerRun(ctx context.Context, name string, fnc stack.Function, opts r
 		}
 	}
 
-	if len(fnc.Secrets) > 0 {
+	if len(fnc.Secrets) > 0 && len(opts.secretsDir) > 0 {
+		args = append(args, fmt.Sprintf("--volume=%s:/var/openfaas/secrets:ro", opts.secretsDir))
+	} else if len(fnc.Secrets) > 0 {
 		secretsPath, err := filepath.Abs(localSecretsDir)
 		if err != nil {
 			return nil, fmt.Errorf("can't determine secrets folder: %w", err)
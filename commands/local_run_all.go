@@ -0,0 +1,571 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// localRunNetwork is created for "local-run --all" when --network is not given
+	localRunNetwork = "openfaas-local"
+
+	// localGatewayHost is how containers reach the local gateway on the host
+	localGatewayHost = "host.docker.internal"
+)
+
+// localGateway routes /function/<name> and /async-function/<name> to the
+// containers started by "local-run --all", in the same way as the gateway.
+type localGateway struct {
+	mu        sync.RWMutex
+	upstreams map[string]*url.URL
+	queue     *localQueue
+}
+
+func newLocalGateway(queueConcurrency int) *localGateway {
+	return &localGateway{
+		upstreams: make(map[string]*url.URL),
+		queue:     newLocalQueue(queueConcurrency, &http.Client{}),
+	}
+}
+
+func (g *localGateway) close() {
+	g.queue.close()
+}
+
+func (g *localGateway) setUpstream(name string, upstream *url.URL) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.upstreams[name] = upstream
+}
+
+func (g *localGateway) removeUpstream(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.upstreams, name)
+}
+
+// lookup finds the function named in the path, which may carry a namespace
+// suffix such as "figlet.openfaas-fn".
+func (g *localGateway) lookup(name string) (*url.URL, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if upstream, ok := g.upstreams[name]; ok {
+		return upstream, true
+	}
+
+	if i := strings.Index(name, "."); i > 0 {
+		upstream, ok := g.upstreams[name[:i]]
+		return upstream, ok
+	}
+
+	return nil, false
+}
+
+// splitFunctionPath splits "/function/<name>/<path>" into the name and the
+// path to send to the function.
+func splitFunctionPath(path, prefix string) (string, string) {
+	rest := strings.TrimPrefix(path, prefix)
+	name, fnPath, _ := strings.Cut(rest, "/")
+	return name, "/" + fnPath
+}
+
+func (g *localGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var prefix string
+	async := false
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/function/"):
+		prefix = "/function/"
+	case strings.HasPrefix(r.URL.Path, "/async-function/"):
+		prefix = "/async-function/"
+		async = true
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	name, fnPath := splitFunctionPath(r.URL.Path, prefix)
+	upstream, ok := g.lookup(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("error finding function %s: not found", name), http.StatusNotFound)
+		return
+	}
+
+	// Every invocation gets a call ID, as with the gateway
+	if len(r.Header.Get("X-Call-Id")) == 0 {
+		r.Header.Set("X-Call-Id", newCallID())
+	}
+	r.Header.Set("X-Start-Time", strconv.FormatInt(time.Now().UnixNano(), 10))
+	w.Header().Set("X-Call-Id", r.Header.Get("X-Call-Id"))
+
+	if async {
+		g.serveAsync(w, r, name, upstream, fnPath)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Scheme = upstream.Scheme
+			pr.Out.URL.Host = upstream.Host
+			pr.Out.URL.Path = fnPath
+			pr.Out.URL.RawPath = ""
+			pr.Out.Host = upstream.Host
+			pr.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("error invoking function %s: %s", name, err), http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// serveAsync holds the request in the queue, and returns the X-Call-Id
+// which is sent to the function and the X-Callback-Url.
+func (g *localGateway) serveAsync(w http.ResponseWriter, r *http.Request, name string, upstream *url.URL, fnPath string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	target := *upstream
+	target.Path = fnPath
+	target.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequest(r.Method, target.String(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Del("X-Callback-Url")
+
+	queued := &asyncRequest{
+		callID:      r.Header.Get("X-Call-Id"),
+		function:    name,
+		callbackURL: localCallbackURL(r.Header.Get("X-Callback-Url")),
+		req:         req,
+		body:        body,
+		queued:      time.Now(),
+	}
+
+	if !g.queue.enqueue(queued) {
+		http.Error(w, fmt.Sprintf("queue is full, %d requests are waiting", localQueueSize), http.StatusTooManyRequests)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// prefixWriter writes each complete line with a prefix so that the logs of
+// several functions can share one output.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes any partial line left when the function exits.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
+
+// localFunction is a container started by "local-run --all".
+type localFunction struct {
+	port    int
+	cancel  context.CancelFunc
+	done    chan struct{}
+	cleanup func()
+}
+
+// localStack runs every function in the stack file on a shared docker
+// network, behind a local gateway on opts.port.
+type localStack struct {
+	opts    runOptions
+	network string
+
+	createdNetwork bool
+
+	gateway   *localGateway
+	server    *http.Server
+	functions map[string]*localFunction
+	logMu     sync.Mutex
+	prefixLen int
+
+	// mu is held while functions are started or stopped, since a rebuild
+	// in --watch mode may overlap with the previous one
+	mu sync.Mutex
+}
+
+func newLocalStack(opts runOptions) (*localStack, error) {
+	network := opts.network
+	if network == "host" {
+		return nil, fmt.Errorf("--all cannot be used with --network=host, as every function listens on port 8080")
+	}
+	if len(network) == 0 {
+		network = localRunNetwork
+	}
+
+	return &localStack{
+		opts:      opts,
+		network:   network,
+		gateway:   newLocalGateway(opts.queueConcurrency),
+		functions: make(map[string]*localFunction),
+	}, nil
+}
+
+// runLocalRunAll starts every function in the stack file and the local
+// gateway, then waits for Ctrl+C. With --watch, only the function which
+// changed is rebuilt and restarted.
+func runLocalRunAll(cmd *cobra.Command, args []string, watch bool) error {
+	opts.output = cmd.OutOrStdout()
+	opts.err = cmd.ErrOrStderr()
+
+	s, err := newLocalStack(opts)
+	if err != nil {
+		return err
+	}
+
+	defer s.stop()
+
+	if opts.print {
+		return s.print()
+	}
+
+	if watch {
+		return watchLoop(cmd, args, s.onChange)
+	}
+
+	if err := s.onChange(cmd, args, cmd.Context()); err != nil {
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case <-sigs:
+		log.Printf("Caught signal, exiting")
+	case <-ctx.Done():
+		log.Printf("Context cancelled, exiting..")
+	}
+
+	return nil
+}
+
+// onChange builds the functions selected by --filter, or all of them, then
+// (re)starts their containers. It is also called by watchLoop, which selects
+// only the functions affected by a change.
+func (s *localStack) onChange(cmd *cobra.Command, args []string, ctx context.Context) error {
+	if s.opts.build {
+		if err := localBuild(cmd, args); err != nil {
+			return err
+		}
+	}
+
+	services, err := parseYAMLFile(yamlFile, "", "", true)
+	if err != nil {
+		return err
+	}
+
+	if len(services.Functions) == 0 {
+		return fmt.Errorf("no functions found in the stack file")
+	}
+
+	if err := updateGitignore(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server == nil {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+
+	for name := range s.functions {
+		if _, ok := services.Functions[name]; !ok {
+			fmt.Printf("Stopping %s, removed from %s\n", name, yamlFile)
+			s.stopFunction(name)
+		}
+	}
+
+	change := watchChangeFrom(ctx)
+	names := sortedFunctionNames(services)
+	s.prefixLen = 0
+	for _, name := range names {
+		s.prefixLen = max(s.prefixLen, len(name))
+	}
+
+	for _, name := range names {
+		_, running := s.functions[name]
+		if running && !change.selected(name) {
+			continue
+		}
+
+		if running {
+			s.stopFunction(name)
+		}
+
+		if err := s.startFunction(name, services.Functions[name]); err != nil {
+			return fmt.Errorf("unable to start %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// start creates the network, unless it exists already, and the gateway.
+func (s *localStack) start() error {
+	if err := exec.Command("docker", "network", "inspect", s.network).Run(); err != nil {
+		out, err := exec.Command("docker", "network", "create", s.network).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable to create network %s: %s", s.network, strings.TrimSpace(string(out)))
+		}
+		s.createdNetwork = true
+	}
+
+	if s.opts.port == 0 {
+		port, err := getPort()
+		if err != nil {
+			return err
+		}
+		s.opts.port = port
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.opts.port))
+	if err != nil {
+		return fmt.Errorf("unable to start local gateway: %w", err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.gateway,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[Gateway] %s", err)
+		}
+	}()
+
+	fmt.Printf("Starting local gateway on: http://0.0.0.0:%d\n\n", s.opts.port)
+	return nil
+}
+
+// stop removes every container, the gateway and the network if it was
+// created by local-run.
+func (s *localStack) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.functions {
+		s.stopFunction(name)
+	}
+
+	if s.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.server.Shutdown(ctx)
+	}
+	s.gateway.close()
+
+	if s.createdNetwork {
+		_ = exec.Command("docker", "network", "rm", s.network).Run()
+	}
+}
+
+// functionOptions are the docker run options for one function of the stack.
+func (s *localStack) functionOptions(name string, function stack.Function, port int) runOptions {
+	fnOpts := s.opts
+	fnOpts.port = port
+	fnOpts.network = s.network
+	fnOpts.extraEnv = maps.Clone(s.opts.extraEnv)
+	if fnOpts.extraEnv == nil {
+		fnOpts.extraEnv = map[string]string{}
+	}
+
+	namespace := function.Namespace
+	if len(namespace) == 0 {
+		namespace = "openfaas-fn"
+	}
+
+	fnOpts.extraEnv["OPENFAAS_NAME"] = name
+	fnOpts.extraEnv["OPENFAAS_NAMESPACE"] = namespace
+	fnOpts.extraEnv["jwt_auth_local"] = "true"
+
+	// Functions call each other through the local gateway, in the same
+	// way as they would through the gateway in a cluster
+	fnOpts.extraEnv["OPENFAAS_URL"] = fmt.Sprintf("http://%s:%d", localGatewayHost, s.opts.port)
+	fnOpts.addHosts = []string{localGatewayHost + ":host-gateway"}
+
+	return fnOpts
+}
+
+func (s *localStack) startFunction(name string, function stack.Function) error {
+	port, err := getPort()
+	if err != nil {
+		return err
+	}
+
+	removeContainer(name)
+
+	fnOpts := s.functionOptions(name, function, port)
+
+	cleanup := func() {}
+	if len(function.Secrets) > 0 && len(s.opts.secrets) > 0 {
+		fnOpts.secretsDir, cleanup, err = writeLocalSecrets(function.Secrets, s.opts.secrets)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := buildDockerRun(ctx, name, function, fnOpts)
+	if err != nil {
+		cancel()
+		cleanup()
+		return err
+	}
+
+	prefix := fmt.Sprintf("%-*s | ", s.prefixLen, name)
+	stdout := &prefixWriter{mu: &s.logMu, w: s.opts.output, prefix: prefix}
+	stderr := &prefixWriter{mu: &s.logMu, w: s.opts.err, prefix: prefix}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		cancel()
+		cleanup()
+		return err
+	}
+
+	go reportLocalProbe(ctx, name, fmt.Sprintf("http://127.0.0.1:%d", port), started, fnOpts)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		err := cmd.Wait()
+		_ = stdout.Flush()
+		_ = stderr.Flush()
+
+		if err != nil && ctx.Err() == nil {
+			log.Printf("%s exited: %s", name, err)
+		}
+	}()
+
+	s.functions[name] = &localFunction{port: port, cancel: cancel, done: done, cleanup: cleanup}
+	s.gateway.setUpstream(name, &url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", port)})
+
+	fmt.Printf("Starting %s on: http://127.0.0.1:%d, via the gateway: http://127.0.0.1:%d/function/%s\n", name, port, s.opts.port, name)
+	return nil
+}
+
+func (s *localStack) stopFunction(name string) {
+	function, ok := s.functions[name]
+	if !ok {
+		return
+	}
+
+	s.gateway.removeUpstream(name)
+	function.cancel()
+	<-function.done
+	removeContainer(name)
+	function.cleanup()
+
+	delete(s.functions, name)
+}
+
+// print writes the docker commands which would be run for each function.
+func (s *localStack) print() error {
+	services, err := parseYAMLFile(yamlFile, "", "", true)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedFunctionNames(services) {
+		cmd, err := buildDockerRun(context.Background(), name, services.Functions[name], s.functionOptions(name, services.Functions[name], 0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.opts.output, "%s\n", cmd.String())
+	}
+
+	return nil
+}
+
+func sortedFunctionNames(services *stack.Services) []string {
+	names := make([]string, 0, len(services.Functions))
+	for name := range services.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
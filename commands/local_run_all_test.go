@@ -0,0 +1,176 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func newTestUpstream(t *testing.T, handler http.HandlerFunc) *url.URL {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func Test_localGateway_routesToFunction(t *testing.T) {
+	upstream := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + " " + string(body)))
+	})
+
+	gateway := newLocalGateway(1)
+	defer gateway.close()
+	gateway.setUpstream("figlet", upstream)
+
+	cases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "root path", path: "/function/figlet", want: "POST /?a=1 hi"},
+		{name: "sub path", path: "/function/figlet/api/v1", want: "POST /api/v1?a=1 hi"},
+		{name: "namespace suffix", path: "/function/figlet.openfaas-fn/x", want: "POST /x?a=1 hi"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path+"?a=1", strings.NewReader("hi"))
+			rec := httptest.NewRecorder()
+
+			gateway.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("want status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+			}
+			if got := rec.Body.String(); got != tc.want {
+				t.Fatalf("want body %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_localGateway_unknownFunction(t *testing.T) {
+	gateway := newLocalGateway(1)
+	defer gateway.close()
+
+	for _, path := range []string{"/function/missing", "/async-function/missing", "/system/functions"} {
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: want status %d, got %d", path, http.StatusNotFound, rec.Code)
+		}
+	}
+}
+
+func Test_localGateway_asyncFunction(t *testing.T) {
+	received := make(chan string, 1)
+	upstream := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r.URL.Path + " " + string(body)
+	})
+
+	gateway := newLocalGateway(1)
+	defer gateway.close()
+	gateway.setUpstream("worker", upstream)
+
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/async-function/worker/jobs", strings.NewReader("payload")))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("want status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	select {
+	case got := <-received:
+		if got != "/jobs payload" {
+			t.Fatalf("want %q, got %q", "/jobs payload", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("function was not invoked")
+	}
+}
+
+func Test_prefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+
+	w := &prefixWriter{mu: &mu, w: &out, prefix: "fn | "}
+	w.Write([]byte("first\nsec"))
+	w.Write([]byte("ond\nthird"))
+
+	if got, want := out.String(), "fn | first\nfn | second\n"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	w.Flush()
+	if got, want := out.String(), "fn | first\nfn | second\nfn | third\n"; got != want {
+		t.Fatalf("want %q after flush, got %q", want, got)
+	}
+}
+
+func Test_localStack_functionOptions(t *testing.T) {
+	tagFormat = 0
+
+	s, err := newLocalStack(runOptions{port: 8080, extraEnv: map[string]string{"debug": "true"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer s.gateway.close()
+
+	fnc := stack.Function{
+		Name:      "fn",
+		Image:     "alexellis/fn:latest",
+		FProcess:  "./handler",
+		Namespace: "dev",
+	}
+
+	cmd, err := buildDockerRun(context.Background(), "fn", fnc, s.functionOptions("fn", fnc, 31112))
+	if err != nil {
+		t.Fatalf("want no error, but got: %s", err)
+	}
+
+	for _, want := range []string{
+		"-p=31112:8080",
+		"--network=" + localRunNetwork,
+		"--add-host=host.docker.internal:host-gateway",
+		"-e=OPENFAAS_URL=http://host.docker.internal:8080",
+		"-e=OPENFAAS_NAME=fn",
+		"-e=OPENFAAS_NAMESPACE=dev",
+		"-e=debug=true",
+	} {
+		if !slices.Contains(cmd.Args, want) {
+			t.Fatalf("want %q in: %v", want, cmd.Args)
+		}
+	}
+
+	if _, ok := s.opts.extraEnv["OPENFAAS_NAME"]; ok {
+		t.Fatalf("want the shared environment left unchanged, got: %v", s.opts.extraEnv)
+	}
+}
+
+func Test_newLocalStack_hostNetwork(t *testing.T) {
+	if _, err := newLocalStack(runOptions{network: "host"}); err == nil {
+		t.Fatalf("want an error for --network=host")
+	}
+}
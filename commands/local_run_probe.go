@@ -0,0 +1,122 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// localProbePaths are served by the watchdog once the function can accept
+// requests. Older watchdogs have no /_/ready, so a 404 there is a pass.
+var localProbePaths = []string{"/_/health", "/_/ready"}
+
+// waitForLocalFunction polls the health and readiness endpoints of a
+// function started by local-run with the same attempts and interval as
+// "faas-cli ready", and returns the time since the container was started.
+func waitForLocalFunction(ctx context.Context, client *http.Client, w io.Writer, name, baseURL string, started time.Time, attempts int, interval time.Duration) (time.Duration, error) {
+	var lastErr error
+
+	for i := 0; i < attempts; i++ {
+		fmt.Fprintf(w, "[%d/%d] Waiting for function %s\n", i+1, attempts, name)
+
+		lastErr = probeLocalFunction(ctx, client, baseURL)
+		if lastErr == nil {
+			return time.Since(started), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+
+	return 0, fmt.Errorf("function %s not ready after: %s, %w", name, (interval * time.Duration(attempts)).Round(time.Second), lastErr)
+}
+
+func probeLocalFunction(ctx context.Context, client *http.Client, baseURL string) error {
+	for _, path := range localProbePaths {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		if res.StatusCode == http.StatusNotFound && path != localProbePaths[0] {
+			continue
+		}
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s returned: %d", path, res.StatusCode)
+		}
+	}
+
+	return nil
+}
+
+// localProbeURL is where the watchdog of a function can be reached from
+// the host.
+func localProbeURL(opts runOptions) string {
+	port := opts.port
+	if opts.network == "host" {
+		port = 8080
+		if envPort, ok := opts.extraEnv["port"]; ok {
+			fmt.Sscanf(envPort, "%d", &port)
+		}
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", port)
+}
+
+// reportLocalProbe waits for a function started in the foreground, and
+// prints whether it became healthy, so that a failing rebuild in --watch
+// mode is not missed.
+func reportLocalProbe(ctx context.Context, name, baseURL string, started time.Time, opts runOptions) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	startup, err := waitForLocalFunction(ctx, client, io.Discard, name, baseURL, started, opts.probeAttempts, opts.probeInterval)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(opts.err, "Function %s failed its health check: %s\n", name, err)
+		}
+		return
+	}
+
+	fmt.Fprintf(opts.err, "Function %s is ready after %s\n", name, startup.Round(time.Millisecond))
+}
+
+// runDetached starts the container in the background and waits until the
+// function is healthy, so that scripts can use it straight away.
+func runDetached(ctx context.Context, cmd *exec.Cmd, name string, opts runOptions) error {
+	started := time.Now()
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable to start %s: %s", name, strings.TrimSpace(string(out)))
+	}
+
+	baseURL := localProbeURL(opts)
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	startup, err := waitForLocalFunction(ctx, client, opts.output, name, baseURL, started, opts.probeAttempts, opts.probeInterval)
+	if err != nil {
+		return fmt.Errorf("%w, view the logs with: docker logs %s", err, name)
+	}
+
+	fmt.Fprintf(opts.output, "Function %s is ready on: %s after %s\n", name, baseURL, startup.Round(time.Millisecond))
+	fmt.Fprintf(opts.output, "Stop it with: docker rm -f %s\n", name)
+
+	return nil
+}
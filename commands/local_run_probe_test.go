@@ -0,0 +1,159 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func Test_waitForLocalFunction(t *testing.T) {
+	var healthCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_/health":
+			// The watchdog is still starting for the first two attempts
+			if atomic.AddInt32(&healthCalls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	startup, err := waitForLocalFunction(context.Background(), srv.Client(), &out, "fn", srv.URL, time.Now(), 5, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if startup <= 0 {
+		t.Errorf("want a startup time, got %s", startup)
+	}
+
+	if got := atomic.LoadInt32(&healthCalls); got != 3 {
+		t.Errorf("want 3 health checks, got %d", got)
+	}
+
+	if !strings.Contains(out.String(), "[3/5] Waiting for function fn") {
+		t.Errorf("want progress for each attempt, got:\n%s", out.String())
+	}
+}
+
+func Test_waitForLocalFunction_notReady(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	_, err := waitForLocalFunction(context.Background(), srv.Client(), &out, "fn", srv.URL, time.Now(), 2, time.Millisecond)
+	if err == nil {
+		t.Fatalf("want an error when /_/ready fails")
+	}
+
+	if !strings.Contains(err.Error(), "/_/ready returned: 503") {
+		t.Fatalf("want the failing probe in the error, got: %s", err)
+	}
+}
+
+func Test_waitForLocalFunction_notReadyMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	_, err := waitForLocalFunction(context.Background(), srv.Client(), &out, "fn", srv.URL, time.Now(), 2, 500*time.Millisecond)
+	if err == nil {
+		t.Fatalf("want an error when the function is not ready")
+	}
+
+	if want := "function fn not ready after: 1s, /_/health returned: 503"; err.Error() != want {
+		t.Fatalf("want %q, got %q", want, err.Error())
+	}
+}
+
+func Test_localProbeURL(t *testing.T) {
+	cases := []struct {
+		name string
+		opts runOptions
+		want string
+	}{
+		{name: "published port", opts: runOptions{port: 8081}, want: "http://127.0.0.1:8081"},
+		{name: "host network", opts: runOptions{port: 8081, network: "host"}, want: "http://127.0.0.1:8080"},
+		{name: "host network with port env", opts: runOptions{network: "host", extraEnv: map[string]string{"port": "9000"}}, want: "http://127.0.0.1:9000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := localProbeURL(tc.opts); got != tc.want {
+				t.Fatalf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_buildDockerRun_detach(t *testing.T) {
+	tagFormat = 0
+
+	fnc := stack.Function{Name: "fn", Image: "alexellis/fn:latest", FProcess: "./handler"}
+
+	cmd, err := buildDockerRun(context.Background(), "fn", fnc, runOptions{port: 8080, detach: true})
+	if err != nil {
+		t.Fatalf("want no error, but got: %s", err)
+	}
+
+	if !slices.Contains(cmd.Args, "-d") || slices.Contains(cmd.Args, "-i") {
+		t.Fatalf("want -d and not -i in: %v", cmd.Args)
+	}
+}
+
+func Test_localRun_detachValidation(t *testing.T) {
+	defer func() {
+		opts.detach = false
+		opts.secretFlags = nil
+		watch = false
+	}()
+
+	cases := [][]string{
+		{"local-run", "fn", "--detach", "--watch"},
+		{"local-run", "--detach", "--all"},
+		{"local-run", "fn", "--detach", "--secret", "token=literal:x"},
+		{"local-run", "fn", "--attempts", "0"},
+	}
+
+	for _, args := range cases {
+		resetForTest()
+		opts.detach = false
+		opts.all = false
+		opts.secretFlags = nil
+		opts.probeAttempts = 60
+		watch = false
+
+		faasCmd.SetArgs(args)
+		if err := faasCmd.Execute(); err == nil {
+			t.Errorf("want an error for: %v", args)
+		}
+	}
+
+	opts.all = false
+	opts.probeAttempts = 60
+}
@@ -0,0 +1,175 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// localQueueSize is the number of async requests held before the local
+// gateway starts to reject them.
+const localQueueSize = 1000
+
+// asyncRequest is an invocation accepted on /async-function/<name>.
+type asyncRequest struct {
+	callID      string
+	function    string
+	callbackURL string
+	req         *http.Request
+	body        []byte
+	queued      time.Time
+}
+
+// localQueue holds async requests in memory and sends them to the functions
+// from a fixed number of workers, in the same way as the queue-worker.
+type localQueue struct {
+	requests chan *asyncRequest
+	client   *http.Client
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newLocalQueue(concurrency int, client *http.Client) *localQueue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &localQueue{
+		requests: make(chan *asyncRequest, localQueueSize),
+		client:   client,
+		cancel:   cancel,
+	}
+
+	for i := 0; i < concurrency; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+
+			for {
+				select {
+				case r := <-q.requests:
+					q.process(ctx, r)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	return q
+}
+
+// enqueue returns false when the queue is full.
+func (q *localQueue) enqueue(r *asyncRequest) bool {
+	select {
+	case q.requests <- r:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the workers, any requests still queued are dropped.
+func (q *localQueue) close() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *localQueue) process(ctx context.Context, r *asyncRequest) {
+	started := time.Now()
+
+	req := r.req.Clone(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(r.body))
+	req.ContentLength = int64(len(r.body))
+
+	res, err := q.client.Do(req)
+	if err != nil {
+		log.Printf("[Queue] %s %s failed: %s", r.callID, r.function, err)
+		if len(r.callbackURL) > 0 {
+			q.callback(ctx, r, http.StatusBadGateway, nil, []byte(err.Error()), time.Since(started))
+		}
+		return
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		log.Printf("[Queue] %s %s failed reading the response: %s", r.callID, r.function, err)
+		return
+	}
+
+	duration := time.Since(started)
+	log.Printf("[Queue] %s %s: %d (%.3fs, queued %.3fs)", r.callID, r.function, res.StatusCode, duration.Seconds(), started.Sub(r.queued).Seconds())
+
+	if len(r.callbackURL) > 0 {
+		q.callback(ctx, r, res.StatusCode, res.Header, body, duration)
+	}
+}
+
+// callback posts the function's response to the X-Callback-Url with the
+// same headers as the queue-worker.
+func (q *localQueue) callback(ctx context.Context, r *asyncRequest, statusCode int, header http.Header, body []byte, duration time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[Queue] %s invalid callback URL %q: %s", r.callID, r.callbackURL, err)
+		return
+	}
+
+	if contentType := header.Get("Content-Type"); len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Call-Id", r.callID)
+	req.Header.Set("X-Function-Name", r.function)
+	req.Header.Set("X-Function-Status", strconv.Itoa(statusCode))
+	req.Header.Set("X-Duration-Seconds", fmt.Sprintf("%f", duration.Seconds()))
+
+	res, err := q.client.Do(req)
+	if err != nil {
+		log.Printf("[Queue] %s callback to %s failed: %s", r.callID, r.callbackURL, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	log.Printf("[Queue] %s callback to %s: %d", r.callID, r.callbackURL, res.StatusCode)
+}
+
+// localCallbackURL lets functions give a callback on the local gateway, as
+// seen from their container, which the queue then calls from the host.
+func localCallbackURL(callbackURL string) string {
+	u, err := url.Parse(callbackURL)
+	if err != nil || u.Hostname() != localGatewayHost {
+		return callbackURL
+	}
+
+	if port := u.Port(); len(port) > 0 {
+		u.Host = net.JoinHostPort("127.0.0.1", port)
+	} else {
+		u.Host = "127.0.0.1"
+	}
+	return u.String()
+}
+
+// newCallID returns a random UUID for requests without an X-Call-Id.
+func newCallID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
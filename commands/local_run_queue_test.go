@@ -0,0 +1,151 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_localGateway_asyncCallback(t *testing.T) {
+	upstream := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Callback-Url") != "" {
+			t.Errorf("want X-Callback-Url removed from the function's request")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("done: " + r.Header.Get("X-Call-Id")))
+	})
+
+	type callback struct {
+		header http.Header
+		body   string
+	}
+	callbacks := make(chan callback, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		callbacks <- callback{header: r.Header.Clone(), body: string(body)}
+	}))
+	defer receiver.Close()
+
+	gateway := newLocalGateway(1)
+	defer gateway.close()
+	gateway.setUpstream("worker", upstream)
+
+	req := httptest.NewRequest(http.MethodPost, "/async-function/worker", strings.NewReader("job"))
+	req.Header.Set("X-Callback-Url", receiver.URL)
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("want status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	callID := rec.Header().Get("X-Call-Id")
+	if len(callID) == 0 {
+		t.Fatalf("want an X-Call-Id in the response")
+	}
+
+	select {
+	case got := <-callbacks:
+		if got.body != "done: "+callID {
+			t.Errorf("want callback body %q, got %q", "done: "+callID, got.body)
+		}
+		for name, want := range map[string]string{
+			"X-Call-Id":         callID,
+			"X-Function-Name":   "worker",
+			"X-Function-Status": "201",
+			"Content-Type":      "text/plain",
+		} {
+			if value := got.header.Get(name); value != want {
+				t.Errorf("want callback header %s: %q, got %q", name, want, value)
+			}
+		}
+		if got.header.Get("X-Duration-Seconds") == "" {
+			t.Errorf("want an X-Duration-Seconds callback header")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("callback was not received")
+	}
+}
+
+func Test_localGateway_keepsCallID(t *testing.T) {
+	upstream := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Call-Id")))
+	})
+
+	gateway := newLocalGateway(1)
+	defer gateway.close()
+	gateway.setUpstream("fn", upstream)
+
+	req := httptest.NewRequest(http.MethodGet, "/function/fn", nil)
+	req.Header.Set("X-Call-Id", "given-id")
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+
+	if got := rec.Body.String(); got != "given-id" {
+		t.Fatalf("want the function to receive X-Call-Id %q, got %q", "given-id", got)
+	}
+	if got := rec.Header().Get("X-Call-Id"); got != "given-id" {
+		t.Fatalf("want X-Call-Id %q in the response, got %q", "given-id", got)
+	}
+}
+
+func Test_localQueue_concurrency(t *testing.T) {
+	var inflight, peak int32
+	release := make(chan struct{})
+	upstream := newTestUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inflight, -1)
+	})
+
+	gateway := newLocalGateway(2)
+	defer gateway.close()
+	gateway.setUpstream("slow", upstream)
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/async-function/slow", nil))
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("want status %d, got %d", http.StatusAccepted, rec.Code)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&inflight) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&peak); got != 2 {
+		t.Fatalf("want 2 requests in flight, got %d", got)
+	}
+	close(release)
+}
+
+func Test_localCallbackURL(t *testing.T) {
+	cases := map[string]string{
+		"http://host.docker.internal:8080/function/receiver": "http://127.0.0.1:8080/function/receiver",
+		"http://host.docker.internal/cb":                     "http://127.0.0.1/cb",
+		"https://example.com/callback":                       "https://example.com/callback",
+	}
+
+	for in, want := range cases {
+		if got := localCallbackURL(in); got != want {
+			t.Errorf("localCallbackURL(%q): want %q, got %q", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/openfaas/go-sdk/seal"
+)
+
+// localSecretsTmpfs is used for the secrets folder when available, so that
+// secret values are never written to disk.
+const localSecretsTmpfs = "/dev/shm"
+
+// loadLocalSecrets reads the secrets given with --sealed-secrets and
+// --secret, values from --secret take priority.
+func loadLocalSecrets(secretFlags []string, sealedFile, keyFile string) (map[string][]byte, error) {
+	values := make(map[string][]byte)
+
+	if len(sealedFile) > 0 {
+		privKey, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading private key for --sealed-secrets: %w", err)
+		}
+
+		envelope, err := os.ReadFile(sealedFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading sealed file: %w", err)
+		}
+
+		unsealed, err := seal.Unseal(privKey, envelope)
+		if err != nil {
+			return nil, fmt.Errorf("unsealing %s: %w", sealedFile, err)
+		}
+
+		for k, v := range unsealed {
+			values[k] = v
+		}
+	}
+
+	for _, secret := range secretFlags {
+		name, value, err := parseLocalSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// parseLocalSecret parses NAME=literal:VALUE or NAME=env:VARIABLE.
+func parseLocalSecret(secret string) (string, []byte, error) {
+	name, source, ok := strings.Cut(secret, "=")
+	if !ok || len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return "", nil, fmt.Errorf("invalid --secret %q, expected NAME=literal:VALUE or NAME=env:VARIABLE", secret)
+	}
+
+	kind, value, ok := strings.Cut(source, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("invalid --secret %q, expected NAME=literal:VALUE or NAME=env:VARIABLE", secret)
+	}
+
+	switch kind {
+	case "literal":
+		return name, []byte(value), nil
+	case "env":
+		envValue, ok := os.LookupEnv(value)
+		if !ok {
+			return "", nil, fmt.Errorf("environment variable %s for secret %s is not set", value, name)
+		}
+		return name, []byte(envValue), nil
+	}
+
+	return "", nil, fmt.Errorf("invalid --secret %q, the source must be literal or env", secret)
+}
+
+// writeLocalSecrets writes the secrets a function needs into a temporary
+// folder, taking each one from values or the local .secrets folder. The
+// returned func removes the folder.
+func writeLocalSecrets(names []string, values map[string][]byte) (string, func(), error) {
+	base := ""
+	if runtime.GOOS == "linux" {
+		if info, err := os.Stat(localSecretsTmpfs); err == nil && info.IsDir() {
+			base = localSecretsTmpfs
+		}
+	}
+
+	// The parent is only readable by the current user, the folder itself
+	// is bind-mounted so that the function's user can read the files
+	parent, err := os.MkdirTemp(base, "faas-cli-secrets-")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temporary secrets folder: %w", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(parent)
+	}
+
+	dir := filepath.Join(parent, "secrets")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("creating temporary secrets folder: %w", err)
+	}
+
+	secretsPath, err := filepath.Abs(localSecretsDir)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("can't determine secrets folder: %w", err)
+	}
+
+	missing := &missingFileError{dir: secretsPath, missing: []string{}}
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok {
+			value, err = os.ReadFile(filepath.Join(secretsPath, name))
+			if err != nil {
+				missing.AddMissingSecret(name)
+				continue
+			}
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name), value, 0644); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("writing secret %s: %w", name, err)
+		}
+	}
+
+	if len(missing.missing) > 0 {
+		cleanup()
+		return "", nil, fmt.Errorf("missing files: %w, or give them with --secret or --sealed-secrets", missing)
+	}
+
+	return dir, cleanup, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/go-sdk/seal"
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func Test_parseLocalSecret(t *testing.T) {
+	t.Setenv("LOCAL_RUN_TOKEN", "from-env")
+
+	cases := []struct {
+		in        string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{in: "api-key=literal:s3cr3t", wantName: "api-key", wantValue: "s3cr3t"},
+		{in: "dsn=literal:user:pass@host", wantName: "dsn", wantValue: "user:pass@host"},
+		{in: "token=env:LOCAL_RUN_TOKEN", wantName: "token", wantValue: "from-env"},
+		{in: "token=env:LOCAL_RUN_UNSET", wantErr: true},
+		{in: "token=file:./token", wantErr: true},
+		{in: "token=s3cr3t", wantErr: true},
+		{in: "=literal:x", wantErr: true},
+		{in: "../x=literal:x", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			name, value, err := parseLocalSecret(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if name != tc.wantName || string(value) != tc.wantValue {
+				t.Fatalf("want %s=%q, got %s=%q", tc.wantName, tc.wantValue, name, value)
+			}
+		})
+	}
+}
+
+func Test_loadLocalSecrets_sealed(t *testing.T) {
+	dir := t.TempDir()
+
+	pub, priv, err := seal.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	keyPath := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyPath, priv, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := seal.Seal(pub, map[string][]byte{
+		"token": []byte("sealed-token"),
+		"url":   []byte("https://example.com"),
+	})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	sealedPath := filepath.Join(dir, "com.openfaas.secrets")
+	if err := os.WriteFile(sealedPath, sealed, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := loadLocalSecrets([]string{"token=literal:override"}, sealedPath, keyPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := string(values["token"]); got != "override" {
+		t.Errorf("want --secret to take priority, got token=%q", got)
+	}
+	if got := string(values["url"]); got != "https://example.com" {
+		t.Errorf("want url from the sealed file, got %q", got)
+	}
+
+	if _, err := loadLocalSecrets(nil, sealedPath, filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("want an error for a missing private key")
+	}
+}
+
+func Test_writeLocalSecrets(t *testing.T) {
+	cwd, _ := os.Getwd()
+	work := t.TempDir()
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	if err := os.MkdirAll(localSecretsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(localSecretsDir, "from-folder"), []byte("folder"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string][]byte{"from-flag": []byte("flag")}
+
+	dir, cleanup, err := writeLocalSecrets([]string{"from-flag", "from-folder"}, values)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for name, want := range map[string]string{"from-flag": "flag", "from-folder": "folder"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("reading secret %s: %s", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("want secret %s=%q, got %q", name, want, got)
+		}
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("want the secrets folder removed, got: %v", err)
+	}
+
+	_, _, err = writeLocalSecrets([]string{"from-flag", "missing"}, values)
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("want an error naming the missing secret, got: %v", err)
+	}
+}
+
+func Test_buildDockerRun_secretsDir(t *testing.T) {
+	tagFormat = 0
+
+	fnc := stack.Function{
+		Name:     "fn",
+		Image:    "alexellis/fn:latest",
+		FProcess: "./handler",
+		Secrets:  []string{"token"},
+	}
+
+	cmd, err := buildDockerRun(context.Background(), "fn", fnc, runOptions{port: 8080, secretsDir: "/dev/shm/faas-cli-secrets-1/secrets"})
+	if err != nil {
+		t.Fatalf("want no error, but got: %s", err)
+	}
+
+	want := "--volume=/dev/shm/faas-cli-secrets-1/secrets:/var/openfaas/secrets:ro"
+	if !slices.Contains(cmd.Args, want) {
+		t.Fatalf("want %q in: %v", want, cmd.Args)
+	}
+}
@@ -21,6 +21,7 @@ var (
 	username      string
 	password      string
 	passwordStdin bool
+	credsStore    string
 )
 
 func init() {
@@ -30,6 +31,19 @@ func init() {
 	loginCmd.Flags().BoolVarP(&passwordStdin, "password-stdin", "s", false, "Reads the gateway password from stdin")
 	loginCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
 	loginCmd.Flags().Duration("timeout", time.Second*5, "Override the timeout for this API call")
+	loginCmd.Flags().StringVar(&credsStore, "credential-store", os.Getenv(config.CredentialStoreEnv), "Save the credentials in a credential store: \"file\" for a file encrypted with the passphrase in "+config.CredentialsPassphraseEnv+", or NAME to run faas-credential-NAME")
+
+	loginCmd.Flags().BoolVar(&loginOIDC, "oidc", false, "Log in with an OIDC issuer and exchange the ID token for a gateway token")
+	loginCmd.Flags().StringVar(&oidcIssuer, "issuer", "", "URL of the OIDC issuer, for use with --oidc")
+	loginCmd.Flags().StringVar(&oidcClientID, "client-id", "", "OAuth client ID registered with the issuer, for use with --oidc or --token-url")
+	loginCmd.Flags().StringArrayVar(&oidcScopes, "scope", []string{"openid", "profile", "email", "offline_access"}, "Scope to request from the issuer, for use with --oidc")
+	loginCmd.Flags().BoolVar(&oidcDeviceCode, "device", false, "Use the device code flow instead of opening a browser, for use with --oidc")
+	loginCmd.Flags().IntVar(&oidcCallbackPort, "callback-port", defaultOIDCPort, "Port on 127.0.0.1 for the browser to return to, for use with --oidc")
+
+	loginCmd.Flags().StringVar(&tokenURL, "token-url", "", "Token endpoint of the identity provider for the client credentials grant")
+	loginCmd.Flags().StringVar(&clientSecretFile, "client-secret-file", "", "File with the client secret for the client credentials grant, it is read on each command")
+	loginCmd.Flags().StringVar(&tokenAudience, "audience", "", "Audience to request for the client credentials grant")
+	loginCmd.Flags().BoolVar(&loginServiceAccount, "service-account", false, "Exchange the Kubernetes projected service account token for a gateway token")
 
 	faasCmd.AddCommand(loginCmd)
 }
@@ -37,10 +51,27 @@ func init() {
 var loginCmd = &cobra.Command{
 	Use:   `login [--username admin|USERNAME] [--password PASSWORD] [--gateway GATEWAY_URL] [--tls-no-verify]`,
 	Short: "Log in to OpenFaaS gateway",
-	Long:  "Log in to OpenFaaS gateway.\nIf no gateway is specified, the default value will be used.",
+	Long: `Log in to OpenFaaS gateway.
+If no gateway is specified, the default value will be used.
+
+With --oidc, log in with an OIDC issuer in a browser, or with --device on
+another device. The ID token is exchanged for a token from the gateway, which
+is refreshed when it expires.
+
+For CI, --client-id, --client-secret-file and --token-url save a client
+credentials configuration, or --service-account uses the Kubernetes projected
+service account token. A token is obtained and exchanged by each command, so
+no token or secret is saved.`,
 	Example: `  cat ~/faas_pass.txt | faas-cli login -u user --password-stdin
   echo $PASSWORD | faas-cli login -s  --gateway https://openfaas.mydomain.com
-  faas-cli login -u user -p password`,
+  faas-cli login -u user -p password
+  OPENFAAS_CREDENTIALS_PASSPHRASE=... faas-cli login -u user --password-stdin --credential-store file
+  faas-cli login -u user --password-stdin --credential-store pass
+  faas-cli login --oidc --issuer https://keycloak.example.com/realms/openfaas --client-id faas-cli
+  faas-cli login --oidc --device --issuer https://keycloak.example.com/realms/openfaas --client-id faas-cli
+  faas-cli login --client-id ci --client-secret-file ./client-secret \
+    --token-url https://keycloak.example.com/realms/openfaas/protocol/openid-connect/token
+  faas-cli login --service-account`,
 	RunE: runLogin,
 }
 
@@ -51,6 +82,34 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Check the store can be used before logging in
+	if len(credsStore) > 0 {
+		if _, err := config.NewCredentialStore(credsStore); err != nil {
+			return err
+		}
+	}
+
+	if loginOIDC || usesTokenSource() {
+		if len(password) > 0 || passwordStdin {
+			return fmt.Errorf("--password and --password-stdin are only used for basic auth")
+		}
+	}
+
+	if usesTokenSource() {
+		if loginOIDC {
+			return fmt.Errorf("--oidc cannot be used with --token-url, --client-secret-file or --service-account")
+		}
+
+		gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+		return runLoginTokenSource(gateway, timeout, cmd.Flags().Changed("scope"))
+	}
+
+	if loginOIDC {
+
+		gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
+		return runLoginOIDC(gateway, timeout)
+	}
+
 	if len(username) == 0 {
 		return fmt.Errorf("must provide --username or -u")
 	}
@@ -97,6 +156,8 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		Gateway: gateway,
 		Token:   token,
 		Auth:    config.BasicAuthType,
+
+		CredsStore: credsStore,
 	}
 	if err := config.UpdateAuthConfig(authConfig); err != nil {
 		return err
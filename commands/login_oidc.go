@@ -0,0 +1,339 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+	sdk "github.com/openfaas/go-sdk"
+)
+
+const (
+	oidcCallbackPath   = "/oauth/callback"
+	deviceCodeGrant    = "urn:ietf:params:oauth:grant-type:device_code"
+	oidcLoginTimeout   = 5 * time.Minute
+	defaultOIDCPort    = 31111
+	defaultDeviceDelay = 5 * time.Second
+)
+
+var (
+	loginOIDC        bool
+	oidcIssuer       string
+	oidcClientID     string
+	oidcScopes       []string
+	oidcDeviceCode   bool
+	oidcCallbackPort int
+
+	// openBrowser opens the authorization URL, it is replaced in tests.
+	openBrowser = openURL
+
+	// devicePollInterval is used when the issuer does not give an interval.
+	devicePollInterval = defaultDeviceDelay
+)
+
+// deviceAuthorization is the response from the device authorization
+// endpoint of an issuer.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// runLoginOIDC logs in with an OIDC issuer, exchanges the ID token for a
+// gateway token and saves it along with the refresh token.
+func runLoginOIDC(gatewayURL string, timeout time.Duration) error {
+	if len(oidcIssuer) == 0 {
+		return fmt.Errorf("give the URL of the OIDC issuer with --issuer")
+	}
+	if len(oidcClientID) == 0 {
+		return fmt.Errorf("give the OAuth client ID with --client-id")
+	}
+
+	idpClient := &http.Client{Timeout: timeout}
+
+	provider, err := proxy.DiscoverOIDC(idpClient, oidcIssuer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcLoginTimeout)
+	defer cancel()
+
+	var idpToken *proxy.OIDCToken
+	if oidcDeviceCode {
+		idpToken, err = oidcDeviceCodeFlow(ctx, idpClient, provider)
+	} else {
+		idpToken, err = oidcAuthCodeFlow(ctx, idpClient, provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(checkTLSInsecure(gatewayURL, tlsInsecure)) > 0 {
+		fmt.Println(NoTLSWarn)
+	}
+
+	gatewayClient := proxy.MakeHTTPClient(&timeout, tlsInsecure)
+	gatewayToken, err := proxy.ExchangeGatewayToken(&gatewayClient, gatewayURL, idpToken.IDToken)
+	if err != nil {
+		return err
+	}
+
+	authConfig := proxy.NewOIDCAuthConfig(gatewayURL, oidcIssuer, oidcClientID, provider.TokenEndpoint, idpToken, gatewayToken)
+	authConfig.CredsStore = credsStore
+	if err := config.UpdateAuthConfig(authConfig); err != nil {
+		return err
+	}
+
+	fmt.Println("credentials saved for", idTokenSubject(idpToken.IDToken), gatewayURL)
+	if !gatewayToken.Expiry.IsZero() {
+		fmt.Printf("token expires at %s", gatewayToken.Expiry.Format(time.RFC3339))
+		if len(idpToken.RefreshToken) > 0 {
+			fmt.Print(" and will be refreshed")
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// oidcAuthCodeFlow runs the authorization code flow with PKCE, the browser
+// is redirected back to a server on the loopback address with the code.
+func oidcAuthCodeFlow(ctx context.Context, client *http.Client, provider *proxy.OIDCProvider) (*proxy.OIDCToken, error) {
+	if len(provider.AuthorizationEndpoint) == 0 {
+		return nil, fmt.Errorf("the issuer has no authorization_endpoint, try --device")
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", oidcCallbackPort))
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen for the callback on port %d: %w", oidcCallbackPort, err)
+	}
+	defer listener.Close()
+
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), oidcCallbackPath)
+
+	verifier := randomURLString(32)
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomURLString(16)
+
+	authURL, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization_endpoint: %w", err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", oidcClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", strings.Join(oidcScopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var err error
+		switch {
+		case len(query.Get("error")) > 0:
+			err = &sdk.OAuthError{Err: query.Get("error"), Description: query.Get("error_description")}
+		case query.Get("state") != state:
+			err = fmt.Errorf("the state of the callback does not match the request")
+		case len(query.Get("code")) == 0:
+			err = fmt.Errorf("no code was given to the callback")
+		}
+
+		if err != nil {
+			http.Error(w, "Login failed: "+err.Error(), http.StatusBadRequest)
+			select {
+			case failures <- err:
+			default:
+			}
+			return
+		}
+
+		fmt.Fprintln(w, "Login complete, you can close this window and return to faas-cli.")
+		select {
+		case codes <- query.Get("code"):
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Opening a browser to log in, if it does not open visit:\n\n  %s\n\n", authURL.String())
+	go func() {
+		if err := openBrowser(authURL.String()); err != nil {
+			fmt.Printf("Unable to open a browser: %s\n", err)
+		}
+	}()
+
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-failures:
+		return nil, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for the login to complete")
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", redirectURI)
+	values.Set("client_id", oidcClientID)
+	values.Set("code_verifier", verifier)
+
+	return proxy.RequestOIDCToken(client, provider.TokenEndpoint, values)
+}
+
+// oidcDeviceCodeFlow runs the device authorization flow, for when there is
+// no browser on the machine running faas-cli.
+func oidcDeviceCodeFlow(ctx context.Context, client *http.Client, provider *proxy.OIDCProvider) (*proxy.OIDCToken, error) {
+	if len(provider.DeviceAuthorizationEndpoint) == 0 {
+		return nil, fmt.Errorf("the issuer has no device_authorization_endpoint")
+	}
+
+	values := url.Values{}
+	values.Set("client_id", oidcClientID)
+	values.Set("scope", strings.Join(oidcScopes, " "))
+
+	res, err := client.PostForm(provider.DeviceAuthorizationEndpoint, values)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the device login: %w", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from the device authorization endpoint: %d - %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	device := deviceAuthorization{}
+	if err := json.Unmarshal(body, &device); err != nil {
+		return nil, fmt.Errorf("unable to parse the device authorization: %w", err)
+	}
+
+	if len(device.VerificationURIComplete) > 0 {
+		fmt.Printf("To log in, visit:\n\n  %s\n\nand confirm the code: %s\n\n", device.VerificationURIComplete, device.UserCode)
+	} else {
+		fmt.Printf("To log in, visit:\n\n  %s\n\nand enter the code: %s\n\n", device.VerificationURI, device.UserCode)
+	}
+
+	interval := devicePollInterval
+	if device.Interval > 0 {
+		interval = time.Duration(device.Interval) * time.Second
+	}
+
+	if device.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(device.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	poll := url.Values{}
+	poll.Set("grant_type", deviceCodeGrant)
+	poll.Set("device_code", device.DeviceCode)
+	poll.Set("client_id", oidcClientID)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for the device login to be confirmed")
+		case <-time.After(interval):
+		}
+
+		token, err := proxy.RequestOIDCToken(client, provider.TokenEndpoint, poll)
+		if err == nil {
+			return token, nil
+		}
+
+		var oauthErr *sdk.OAuthError
+		if !errors.As(err, &oauthErr) {
+			return nil, err
+		}
+
+		switch oauthErr.Err {
+		case "authorization_pending":
+		case "slow_down":
+			interval += defaultDeviceDelay
+		default:
+			return nil, fmt.Errorf("device login failed: %w", err)
+		}
+	}
+}
+
+// idTokenSubject returns the email or subject of an ID token for display,
+// the gateway validates the token when it is exchanged.
+func idTokenSubject(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return "user"
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "user"
+	}
+
+	var claims struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "user"
+	}
+
+	if len(claims.Email) > 0 {
+		return claims.Email
+	}
+	if len(claims.Subject) > 0 {
+		return claims.Subject
+	}
+	return "user"
+}
+
+func randomURLString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func openURL(u string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", u).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", u).Start()
+	default:
+		return exec.Command("xdg-open", u).Start()
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+)
+
+// newFakeIssuer serves the endpoints of an OIDC issuer and the token
+// exchange of a gateway, the ID token is only issued for the PKCE verifier
+// of the code challenge, or once the device code has been polled twice.
+func newFakeIssuer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	idToken := "x." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1234","email":"user@example.com"}`)) + ".y"
+
+	var challenge string
+	var polls int32
+
+	mux := http.NewServeMux()
+	var s *httptest.Server
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(proxy.OIDCProvider{
+			Issuer:                      s.URL,
+			AuthorizationEndpoint:       s.URL + "/authorize",
+			TokenEndpoint:               s.URL + "/token",
+			DeviceAuthorizationEndpoint: s.URL + "/device",
+		})
+	})
+
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "faas-cli" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		challenge = q.Get("code_challenge")
+
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		rq := redirect.Query()
+		rq.Set("code", "code-1")
+		rq.Set("state", q.Get("state"))
+		redirect.RawQuery = rq.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(deviceAuthorization{
+			DeviceCode:      "device-1",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: s.URL + "/activate",
+			ExpiresIn:       60,
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("grant_type") {
+		case "authorization_code":
+			sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+			if r.FormValue("code") != "code-1" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+		case deviceCodeGrant:
+			if atomic.AddInt32(&polls, 1) < 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "idp-access",
+			"id_token":      idToken,
+			"refresh_token": "refresh-1",
+			"expires_in":    300,
+		})
+	})
+
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("subject_token") != idToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "gateway-token",
+			"expires_in":   3600,
+		})
+	})
+
+	s = httptest.NewServer(mux)
+	return s
+}
+
+func Test_login_oidc(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{name: "authorization code with PKCE", args: []string{"--callback-port=0"}},
+		{name: "device code", args: []string{"--device"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(config.ConfigLocationEnv, t.TempDir())
+
+			s := newFakeIssuer(t)
+			defer s.Close()
+
+			defer func(open func(string) error, interval time.Duration) {
+				openBrowser = open
+				devicePollInterval = interval
+				loginOIDC = false
+				oidcDeviceCode = false
+				oidcCallbackPort = defaultOIDCPort
+			}(openBrowser, devicePollInterval)
+
+			// The browser follows the redirect back to the callback
+			openBrowser = func(u string) error {
+				res, err := http.Get(u)
+				if err == nil {
+					res.Body.Close()
+				}
+				return err
+			}
+			devicePollInterval = 10 * time.Millisecond
+
+			resetForTest()
+			var err error
+			stdOut := test.CaptureStdout(func() {
+				faasCmd.SetArgs(append([]string{
+					"login",
+					"--oidc",
+					"--issuer", s.URL,
+					"--client-id", "faas-cli",
+					"--gateway", s.URL,
+				}, tc.args...))
+				err = faasCmd.Execute()
+			})
+			if err != nil {
+				t.Fatalf("login: %v\n%s", err, stdOut)
+			}
+
+			if !strings.Contains(stdOut, "credentials saved for user@example.com") {
+				t.Fatalf("want the user in the output, got:\n%s", stdOut)
+			}
+
+			authConfig, err := config.LookupAuthConfig(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if authConfig.Auth != config.Oauth2AuthType || authConfig.Token != "gateway-token" {
+				t.Fatalf("want the gateway token saved, got %+v", authConfig)
+			}
+
+			options := map[string]string{}
+			for _, option := range authConfig.Options {
+				options[option.Name] = option.Value
+			}
+			if options[proxy.OIDCRefreshTokenOption] != "refresh-1" || options[proxy.OIDCTokenURLOption] != s.URL+"/token" {
+				t.Fatalf("want the refresh token and token URL saved, got %v", options)
+			}
+			if len(options[proxy.OIDCExpiryOption]) == 0 {
+				t.Fatalf("want the expiry saved, got %v", options)
+			}
+		})
+	}
+}
+
+func Test_idTokenSubject(t *testing.T) {
+	encode := func(claims string) string {
+		return "x." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".y"
+	}
+
+	if got := idTokenSubject(encode(`{"sub":"1234"}`)); got != "1234" {
+		t.Fatalf("want the subject, got %s", got)
+	}
+	if got := idTokenSubject(encode(`{"sub":"1234","email":"a@b.c"}`)); got != "a@b.c" {
+		t.Fatalf("want the email, got %s", got)
+	}
+	if got := idTokenSubject("not-a-jwt"); got != "user" {
+		t.Fatalf("want user for an invalid token, got %s", got)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+)
+
+var (
+	clientSecretFile    string
+	tokenURL            string
+	tokenAudience       string
+	loginServiceAccount bool
+)
+
+// usesTokenSource is true when login should save a client credentials or
+// service account configuration rather than a password.
+func usesTokenSource() bool {
+	return len(tokenURL) > 0 || len(clientSecretFile) > 0 || loginServiceAccount
+}
+
+// runLoginTokenSource saves the configuration for the gateway tokens to be
+// obtained by each command, after checking a token can be exchanged now.
+// No secret is saved, the client secret is read from its file each time.
+func runLoginTokenSource(gatewayURL string, timeout time.Duration, scopeChanged bool) error {
+	authConfig := config.AuthConfig{
+		Gateway: gatewayURL,
+	}
+
+	subject := "service account"
+	if loginServiceAccount {
+		if len(tokenURL) > 0 || len(clientSecretFile) > 0 {
+			return fmt.Errorf("--service-account cannot be used with --token-url or --client-secret-file")
+		}
+		authConfig.Auth = config.ServiceAccountAuthType
+	} else {
+		if len(oidcClientID) == 0 || len(clientSecretFile) == 0 || len(tokenURL) == 0 {
+			return fmt.Errorf("--client-id, --client-secret-file and --token-url are all required for the client credentials grant")
+		}
+
+		secretPath, err := filepath.Abs(clientSecretFile)
+		if err != nil {
+			return err
+		}
+
+		authConfig.Auth = config.ClientCredentialsAuthType
+		authConfig.Options = []config.Option{
+			{Name: proxy.OIDCClientIDOption, Value: oidcClientID},
+			{Name: proxy.OIDCTokenURLOption, Value: tokenURL},
+			{Name: proxy.ClientSecretFileOption, Value: secretPath},
+		}
+		if scopeChanged {
+			authConfig.Options = append(authConfig.Options, config.Option{Name: proxy.ScopeOption, Value: strings.Join(oidcScopes, " ")})
+		}
+		if len(tokenAudience) > 0 {
+			authConfig.Options = append(authConfig.Options, config.Option{Name: proxy.AudienceOption, Value: tokenAudience})
+		}
+		subject = oidcClientID
+	}
+
+	if len(checkTLSInsecure(gatewayURL, tlsInsecure)) > 0 {
+		fmt.Println(NoTLSWarn)
+	}
+
+	fmt.Println("Exchanging a token with the OpenFaaS server to validate the configuration...")
+
+	client := proxy.MakeHTTPClient(&timeout, tlsInsecure)
+	auth, err := proxy.NewExchangeTokenAuth(authConfig, &client)
+	if err != nil {
+		return err
+	}
+	if _, err := auth.Token(); err != nil {
+		return err
+	}
+
+	if err := config.UpdateAuthConfig(authConfig); err != nil {
+		return err
+	}
+
+	fmt.Println("credentials saved for", subject, gatewayURL)
+	return nil
+}
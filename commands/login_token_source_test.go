@@ -0,0 +1,109 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_login_clientCredentials(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_secret") != "s3cr3t" || r.FormValue("scope") != "openid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "ci-access-token", "expires_in": 300})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("subject_token") != "ci-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "gateway-token", "expires_in": 3600})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	secretFile := filepath.Join(t.TempDir(), "client-secret")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer resetForTest()
+
+	var err error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"login",
+			"--gateway", s.URL,
+			"--client-id", "ci",
+			"--client-secret-file", secretFile,
+			"--token-url", s.URL + "/token",
+		})
+		err = faasCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("login: %v\n%s", err, stdOut)
+	}
+	if !strings.Contains(stdOut, "credentials saved for ci") {
+		t.Fatalf("want the client ID in the output, got:\n%s", stdOut)
+	}
+
+	authConfig, err := config.LookupAuthConfig(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Auth != config.ClientCredentialsAuthType || len(authConfig.Token) > 0 {
+		t.Fatalf("want a client credentials configuration without a token, got %+v", authConfig)
+	}
+
+	data, err := os.ReadFile(filepath.Join(os.Getenv(config.ConfigLocationEnv), config.DefaultFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Fatalf("want the client secret kept out of the config file, got:\n%s", data)
+	}
+
+	auth, err := proxy.NewCLIAuth("", s.URL, false)
+	if err != nil {
+		t.Fatalf("NewCLIAuth: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, s.URL+"/system/functions", nil)
+	if err := auth.Set(req); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer gateway-token" {
+		t.Fatalf("want the gateway token, got %q", got)
+	}
+}
+
+func Test_login_clientCredentials_requiresAllFlags(t *testing.T) {
+	resetForTest()
+	defer resetForTest()
+
+	faasCmd.SetArgs([]string{
+		"login",
+		"--client-id", "ci",
+		"--token-url", "http://127.0.0.1:8080/token",
+	})
+	err := faasCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--client-secret-file") {
+		t.Fatalf("want an error for the missing client secret, got: %v", err)
+	}
+}
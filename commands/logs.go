@@ -16,7 +16,6 @@ import (
 	"github.com/openfaas/faas-provider/logs"
 
 	"github.com/openfaas/faas-cli/proxy"
-	"github.com/openfaas/go-sdk/stack"
 	"github.com/spf13/cobra"
 )
 
@@ -104,7 +103,7 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	}
 
 	logRequest := logRequestFromFlags(cmd, args)
-	cliAuth, err := proxy.NewCLIAuth(logFlagValues.token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(logFlagValues.token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
@@ -153,7 +152,7 @@ func logsGatewayURL(cmd *cobra.Command) (string, error) {
 		// is applied, it could only make the parse fail. envsubst is passed as
 		// true to match the default of the --envsubst flag on the commands
 		// that do parse functions out of the stack file.
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, "", "", true)
+		parsedServices, err := parseYAMLFile(yamlFile, "", "", true)
 		if err != nil {
 			// yamlFile is populated from the working directory when -f was not
 			// given, and a file named stack.yaml is not necessarily an
@@ -0,0 +1,27 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+)
+
+// TestMain points the config directory at a temporary folder, so that tests
+// which deploy functions do not write history into the user's home directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "faas-cli-config")
+	if err != nil {
+		panic(err)
+	}
+
+	os.Setenv(config.ConfigLocationEnv, dir)
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
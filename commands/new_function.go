@@ -160,7 +160,7 @@ Download templates:
 			templateName += "#" + ref
 		}
 
-		if err := pullTemplate(templateInfo.Repository, templateName, overwrite); err != nil {
+		if err := pullTemplate(templateInfo.Repository, templateName, overwrite, lockIfExists); err != nil {
 			return fmt.Errorf("error while pulling template: %s", err)
 		}
 
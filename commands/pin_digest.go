@@ -0,0 +1,83 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	ggcrname "github.com/google/go-containerregistry/pkg/name"
+)
+
+// imageResolver returns the image pinned to the digest it points to in the
+// registry.
+type imageResolver func(image string) (string, error)
+
+// newImageResolver resolves images with the credentials from the docker
+// config, as used by docker push.
+func newImageResolver(ctx context.Context) imageResolver {
+	return func(image string) (string, error) {
+		return pinImageDigest(image, crane.WithContext(ctx))
+	}
+}
+
+// pinImageDigest resolves the tag of the image to its digest in the registry
+// and returns image@sha256:..., keeping the tag so that it remains readable.
+// An image which is already pinned is returned as it is.
+func pinImageDigest(image string, options ...crane.Option) (string, error) {
+	if _, digest := splitImageDigest(image); len(digest) > 0 {
+		return image, nil
+	}
+
+	ref, err := ggcrname.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", image, err)
+	}
+
+	digest, err := crane.Digest(ref.String(), options...)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the digest of %s: %w", image, err)
+	}
+
+	return image + "@" + digest, nil
+}
+
+// pinImages pins the image of each function, before any of them is deployed.
+func pinImages(resolve imageResolver, images map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(images))
+	for functionName := range images {
+		names = append(names, functionName)
+	}
+	sort.Strings(names)
+
+	pinned := make(map[string]string, len(images))
+	for _, functionName := range names {
+		image, err := resolve(images[functionName])
+		if err != nil {
+			return nil, fmt.Errorf("unable to pin %s: %w", functionName, err)
+		}
+		pinned[functionName] = image
+	}
+	return pinned, nil
+}
+
+// splitImageDigest splits image@sha256:... into the image and its digest,
+// the digest is empty when the image is not pinned.
+func splitImageDigest(image string) (string, string) {
+	if i := strings.LastIndex(image, "@"); i > -1 {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// imageRepository returns the image without its tag.
+func imageRepository(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
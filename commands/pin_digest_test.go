@@ -0,0 +1,219 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+	types "github.com/openfaas/faas-provider/types"
+	"github.com/openfaas/go-sdk/stack"
+)
+
+func Test_pinImageDigest(t *testing.T) {
+	host := newTestRegistry(t)
+
+	digest := pushSignedImage(t, host+"/fn:latest", nil, "")
+
+	pinned, err := pinImageDigest(host + "/fn:latest")
+	if err != nil {
+		t.Fatalf("pinImageDigest: %v", err)
+	}
+	if want := host + "/fn:latest@" + digest.String(); pinned != want {
+		t.Fatalf("want %s, got %s", want, pinned)
+	}
+
+	if again, err := pinImageDigest(pinned); err != nil || again != pinned {
+		t.Fatalf("want a pinned image to be unchanged, got %s %v", again, err)
+	}
+
+	if _, err := pinImageDigest(host + "/missing:latest"); err == nil || !strings.Contains(err.Error(), "unable to resolve the digest") {
+		t.Fatalf("want an error for a missing image, got: %v", err)
+	}
+}
+
+func Test_splitImageDigest(t *testing.T) {
+	cases := []struct {
+		image      string
+		wantImage  string
+		wantDigest string
+	}{
+		{image: "ttl.sh/fn:latest", wantImage: "ttl.sh/fn:latest"},
+		{image: "ttl.sh/fn:latest@sha256:abc", wantImage: "ttl.sh/fn:latest", wantDigest: "sha256:abc"},
+		{image: "localhost:5000/fn@sha256:abc", wantImage: "localhost:5000/fn", wantDigest: "sha256:abc"},
+	}
+
+	for _, tc := range cases {
+		image, digest := splitImageDigest(tc.image)
+		if image != tc.wantImage || digest != tc.wantDigest {
+			t.Fatalf("%s: want %q %q, got %q %q", tc.image, tc.wantImage, tc.wantDigest, image, digest)
+		}
+	}
+
+	if got := imageRepository("localhost:5000/fn:0.1.0"); got != "localhost:5000/fn" {
+		t.Fatalf("want localhost:5000/fn, got %s", got)
+	}
+	if got := imageRepository("localhost:5000/fn"); got != "localhost:5000/fn" {
+		t.Fatalf("want localhost:5000/fn, got %s", got)
+	}
+}
+
+func Test_deploy_pinDigest(t *testing.T) {
+	host := newTestRegistry(t)
+
+	digest := pushSignedImage(t, host+"/fn:0.1.0", nil, "")
+
+	var mu sync.Mutex
+	var deployed []string
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.FunctionDeployment
+		if err := json.NewDecoder(r.Body).Decode(&req); err == nil {
+			mu.Lock()
+			deployed = append(deployed, req.Image)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	stackPath := filepath.Join(t.TempDir(), "stack.yaml")
+	stackYAML := `version: 1.0
+provider:
+  name: openfaas
+functions:
+  fn:
+    image: ` + host + `/fn:0.1.0
+`
+	if err := os.WriteFile(stackPath, []byte(stackYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resetForTest()
+	defer func() {
+		resetForTest()
+		readTemplate = true
+	}()
+
+	test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"deploy",
+			"-f", stackPath,
+			"--gateway=" + gw.URL,
+			"--read-template=false",
+			"--pin-digest",
+		})
+		if err := faasCmd.Execute(); err != nil {
+			t.Fatalf("deploy: %v", err)
+		}
+	})
+
+	want := host + "/fn:0.1.0@" + digest.String()
+	if len(deployed) != 1 || deployed[0] != want {
+		t.Fatalf("want %s deployed, got %v", want, deployed)
+	}
+}
+
+func Test_generateCRDYAML_pinDigest(t *testing.T) {
+	services, err := stack.ParseYAMLData([]byte(`version: 1.0
+provider:
+  name: openfaas
+functions:
+  fn:
+    image: ttl.sh/fn:0.1.0
+`), "", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := func(image string) (string, error) {
+		return image + "@sha256:abc", nil
+	}
+
+	generated, err := generateCRDYAML(*services, 0, defaultAPIVersion, "openfaas-fn",
+		NewFunctionMetadataSourceStub("", ""), resolve)
+	if err != nil {
+		t.Fatalf("generateCRDYAML: %v", err)
+	}
+
+	if !strings.Contains(generated, "image: ttl.sh/fn:0.1.0@sha256:abc") {
+		t.Fatalf("want the pinned image, got:\n%s", generated)
+	}
+}
+
+func Test_diffImageDigests(t *testing.T) {
+	resolve := func(image string) (string, error) {
+		return image + "@sha256:new", nil
+	}
+
+	cases := []struct {
+		name          string
+		yamlImage     string
+		deployedImage string
+		wantMoved     bool
+		wantRows      []string
+	}{
+		{
+			name:          "tag unchanged",
+			yamlImage:     "ttl.sh/fn:0.1.0",
+			deployedImage: "ttl.sh/fn:0.1.0@sha256:new",
+		},
+		{
+			name:          "tag moved",
+			yamlImage:     "ttl.sh/fn:0.1.0",
+			deployedImage: "ttl.sh/fn:0.1.0@sha256:old",
+			wantMoved:     true,
+			wantRows:      []string{"image.digest"},
+		},
+		{
+			name:          "deployed by digest without the tag",
+			yamlImage:     "ttl.sh/fn:0.1.0",
+			deployedImage: "ttl.sh/fn@sha256:old",
+			wantMoved:     true,
+			wantRows:      []string{"image.digest"},
+		},
+		{
+			name:          "image changed",
+			yamlImage:     "ttl.sh/fn:0.2.0",
+			deployedImage: "ttl.sh/fn:0.1.0@sha256:old",
+			wantRows:      []string{"image"},
+		},
+		{
+			name:          "not pinned",
+			yamlImage:     "ttl.sh/fn:0.2.0",
+			deployedImage: "ttl.sh/fn:0.1.0",
+			wantRows:      []string{"image"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			yamlF := funcDiff{Image: tc.yamlImage}
+			deployedF := funcDiff{Image: tc.deployedImage}
+
+			moved, err := diffImageDigests(&yamlF, &deployedF, resolve)
+			if err != nil {
+				t.Fatalf("diffImageDigests: %v", err)
+			}
+			if moved != tc.wantMoved {
+				t.Fatalf("want tag moved %t, got %t", tc.wantMoved, moved)
+			}
+
+			rows, _ := buildSideBySide(yamlF, true, deployedF, true, diffEnvLocal)
+			var fields []string
+			for _, row := range rows {
+				fields = append(fields, row.left.field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tc.wantRows, ",") {
+				t.Fatalf("want rows %v, got %v", tc.wantRows, fields)
+			}
+		})
+	}
+}
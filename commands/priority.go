@@ -28,6 +28,8 @@ func getGatewayURL(argumentURL, defaultURL, yamlURL, environmentURL string) stri
 		gatewayURL = yamlURL
 	} else if len(environmentURL) > 0 {
 		gatewayURL = environmentURL
+	} else if activeContext != nil && len(activeContext.Gateway) > 0 {
+		gatewayURL = activeContext.Gateway
 	} else {
 		gatewayURL = defaultURL
 	}
@@ -66,7 +68,7 @@ func getTemplateStoreURL(argumentURL, environmentURL, defaultURL string) string
 
 func getNamespace(flagNamespace, stackNamespace string) string {
 	// If the namespace flag is passed use it
-	if len(flagNamespace) > 0 {
+	if len(flagNamespace) > 0 && !namespaceFromContext {
 		return flagNamespace
 	}
 	// https://github.com/openfaas/faas-cli/issues/742#issuecomment-625746405
@@ -74,6 +76,11 @@ func getNamespace(flagNamespace, stackNamespace string) string {
 		return stackNamespace
 	}
 
+	// The namespace of the active context
+	if len(flagNamespace) > 0 {
+		return flagNamespace
+	}
+
 	return defaultFunctionNamespace
 
 }
@@ -8,12 +8,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	v2execute "github.com/alexellis/go-execute/v2"
 	"github.com/morikuni/aec"
 	"github.com/openfaas/faas-cli/util"
+	"github.com/openfaas/faas-cli/versioncontrol"
 
 	"github.com/openfaas/faas-cli/builder"
 	"github.com/openfaas/go-sdk/stack"
@@ -29,6 +32,8 @@ var (
 	payloadSecretPath    string
 	builderPublicKeyPath string
 	builderKeyID         string
+	sbom                 bool
+	provenance           bool
 )
 
 func init() {
@@ -58,6 +63,8 @@ func init() {
 	publishCmd.Flags().StringVar(&payloadSecretPath, "payload-secret", "", "Path to the payload secret file")
 	publishCmd.Flags().StringVar(&builderPublicKeyPath, "builder-public-key", "", "Builder public key as a literal value, or a path to a file containing raw base64 or the JSON response from /publickey")
 	publishCmd.Flags().BoolVar(&forcePull, "pull", false, "Force a re-pull of base images in template during build, useful for publishing images")
+	publishCmd.Flags().BoolVar(&sbom, "sbom", false, "Attach an SBOM attestation to each image")
+	publishCmd.Flags().BoolVar(&provenance, "provenance", false, "Attach a provenance attestation to each image, with the maximum level of detail")
 
 	publishCmd.Flags().BoolVar(&pullDebug, "debug", false, "Enable debug output when pulling templates")
 	publishCmd.Flags().BoolVar(&overwrite, "overwrite", true, "Overwrite existing templates from the template repository")
@@ -85,6 +92,7 @@ var publishCmd = &cobra.Command{
                    [--tag <digest|sha|branch|describe>]
                    [--platforms linux/amd64,linux/arm64]
                    [--reset-qemu]
+                   [--sbom] [--provenance]
                    [--remote-builder http://127.0.0.1:8081]`,
 	Short: "Builds and pushes multi-arch OpenFaaS container images",
 	Long: `Builds and pushes multi-arch OpenFaaS container images using Docker buildx.
@@ -92,6 +100,11 @@ Most users will want faas-cli build or faas-cli up for development and testing.
 This command is designed to make releasing and publishing multi-arch container 
 images easier.
 
+Each image that is published is recorded in ` + builder.PublishManifestFile + `
+with its digest, template and Git SHA. Run faas-cli deploy --verify-manifest
+to deploy those digests, it refuses to deploy an image or template which has
+changed since it was published.
+
 A stack.yaml file is required, and any images that are built will not be 
 available in the local Docker library. This is due to technical constraints in 
 Docker and buildx. You must use a multi-arch template to use this command with 
@@ -105,7 +118,9 @@ See also: faas-cli build`,
   faas-cli publish --tag sha
   faas-cli publish --tag digest
   faas-cli publish --reset-qemu
-  faas-cli publish --remote-builder http://127.0.0.1:8081 --payload-secret /var/openfaas/secrets/payload-secret -f stack.yml`,
+  faas-cli publish --sbom --provenance
+  faas-cli publish --remote-builder http://127.0.0.1:8081 --payload-secret /var/openfaas/secrets/payload-secret -f stack.yml
+  faas-cli publish --remote-builder http://127.0.0.1:8081 --payload-secret /var/openfaas/secrets/payload-secret --parallel 4`,
 	PreRunE: preRunPublish,
 	RunE:    runPublish,
 }
@@ -128,6 +143,10 @@ func preRunPublish(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("the --parallel flag must be great than 0")
 	}
 
+	if len(remoteBuilder) > 0 && (sbom || provenance) {
+		return fmt.Errorf("--sbom and --provenance are not supported with --remote-builder")
+	}
+
 	if len(yamlFile) == 0 {
 		return fmt.Errorf("--yaml or -f is required")
 	}
@@ -139,7 +158,8 @@ func runPublish(cmd *cobra.Command, args []string) error {
 	var services stack.Services
 
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		stackRegex, stackFilter := stackFilters(cmd.Context())
+		parsedServices, err := parseYAMLFile(yamlFile, stackRegex, stackFilter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -253,6 +273,21 @@ func publish(services *stack.Services, queueDepth int, shrinkwrap, quietBuild, m
 	startOuter := time.Now()
 
 	errors := []error{}
+	var errorsMu sync.Mutex
+
+	var manifest *builder.PublishManifest
+	if !shrinkwrap {
+		var err error
+		if manifest, err = builder.LoadPublishManifest(builder.PublishManifestFile); err != nil {
+			return []error{err}
+		}
+	}
+
+	// Remote builds of functions running in parallel are shown in one view
+	var progress *builder.BuildProgress
+	if len(remoteBuilder) > 0 {
+		progress = builder.NewBuildProgress(os.Stdout)
+	}
 
 	wg := sync.WaitGroup{}
 
@@ -271,7 +306,7 @@ func publish(services *stack.Services, queueDepth int, shrinkwrap, quietBuild, m
 					combinedBuildOptions := combineBuildOpts(function.BuildOptions, buildOptions)
 					combinedBuildArgMap := util.MergeMap(function.BuildArgs, buildArgMap)
 					combinedExtraPaths := util.MergeSlice(services.StackConfiguration.CopyExtraPaths, copyExtra)
-					err := builder.PublishImage(function.Image,
+					published, err := builder.PublishImage(function.Image,
 						function.Handler,
 						function.Name,
 						function.Language,
@@ -291,10 +326,17 @@ func publish(services *stack.Services, queueDepth int, shrinkwrap, quietBuild, m
 						payloadSecretPath,
 						builderPublicKeyPath,
 						forcePull,
+						sbom,
+						provenance,
+						progress,
 					)
 
 					if err != nil {
+						errorsMu.Lock()
 						errors = append(errors, err)
+						errorsMu.Unlock()
+					} else if manifest != nil {
+						manifest.Record(function.Name, publishedFunction(function, published))
 					}
 				}
 
@@ -321,7 +363,53 @@ func publish(services *stack.Services, queueDepth int, shrinkwrap, quietBuild, m
 
 	wg.Wait()
 
+	if progress != nil {
+		progress.Summary()
+	}
+
+	if manifest != nil {
+		if err := manifest.Save(); err != nil {
+			errors = append(errors, err)
+		} else {
+			fmt.Printf("Wrote publish manifest: %s\n", builder.PublishManifestFile)
+		}
+	}
+
 	duration := time.Since(startOuter)
 	fmt.Printf("\n%s\n", aec.Apply(fmt.Sprintf("Total build time: %1.2fs", duration.Seconds()), aec.YellowF))
 	return errors
 }
+
+// publishedFunction describes a published image for the manifest, with the
+// template's meta.json from when it was pulled and the Git SHA of the handler.
+func publishedFunction(function stack.Function, published builder.PublishedImage) builder.PublishedFunction {
+	template := builder.PublishedTemplate{Name: function.Language}
+	if meta, err := readTemplateMeta(filepath.Join(TemplateDirectory, function.Language)); err == nil {
+		template.Repository = meta.Repository
+		template.RefName = meta.RefName
+		template.Sha = meta.Sha
+	}
+
+	return builder.PublishedFunction{
+		Image:      published.Name,
+		Digest:     published.Digest,
+		Platforms:  strings.Split(platforms, ","),
+		Template:   template,
+		GitSHA:     handlerGitSHA(function.Handler),
+		SBOM:       sbom,
+		Provenance: provenance,
+		Published:  time.Now().UTC(),
+	}
+}
+
+var gitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// handlerGitSHA returns the full commit SHA of the repository holding the
+// handler, or an empty string when it is not in a Git repository.
+func handlerGitSHA(handler string) string {
+	sha, err := versioncontrol.GetGitSHAFor(handler, false)
+	if err != nil || !gitSHAPattern.MatchString(sha) {
+		return ""
+	}
+	return sha
+}
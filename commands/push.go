@@ -51,7 +51,8 @@ func runPush(cmd *cobra.Command, args []string) error {
 
 	var services stack.Services
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		stackRegex, stackFilter := stackFilters(cmd.Context())
+		parsedServices, err := parseYAMLFile(yamlFile, stackRegex, stackFilter, envsubst)
 		if err != nil {
 			return err
 		}
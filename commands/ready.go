@@ -64,7 +64,7 @@ func runReadyCmd(cmd *cobra.Command, args []string) error {
 	var gatewayAddress string
 	var yamlGateway string
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -116,8 +116,7 @@ func runReadyCmd(cmd *cobra.Command, args []string) error {
 
 	} else {
 		functionName := args[0]
-		ready := false
-		cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+		cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 		if err != nil {
 			return err
 		}
@@ -127,33 +126,42 @@ func runReadyCmd(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		ctx := context.Background()
+		if err := waitForFunction(context.Background(), cliClient, functionName, functionNamespace, attempts, interval); err != nil {
+			return err
+		}
+	}
 
-		for i := 0; i < attempts; i++ {
-			suffix := ""
-			if len(functionNamespace) > 0 {
-				suffix = "." + functionNamespace
-			}
+	return nil
+}
 
-			fmt.Printf("[%d/%d] Waiting for function %s%s\n", i+1, attempts, functionName, suffix)
+// waitForFunction polls the gateway until the function has at least one
+// available replica, or the attempts run out.
+func waitForFunction(ctx context.Context, cliClient *proxy.Client, functionName, namespace string, attempts int, interval time.Duration) error {
+	ready := false
 
-			function, err := cliClient.GetFunctionInfo(ctx, functionName, functionNamespace)
-			if err != nil {
-				fmt.Printf("[%d/%d] Error getting function info: %s\n", i+1, attempts, err.Error())
-			}
+	for i := 0; i < attempts; i++ {
+		suffix := ""
+		if len(namespace) > 0 {
+			suffix = "." + namespace
+		}
 
-			if function.AvailableReplicas > 0 {
-				fmt.Printf("Function %s is ready\n", functionName)
-				ready = true
-				break
-			}
-			time.Sleep(interval)
+		fmt.Printf("[%d/%d] Waiting for function %s%s\n", i+1, attempts, functionName, suffix)
+
+		function, err := cliClient.GetFunctionInfo(ctx, functionName, namespace)
+		if err != nil {
+			fmt.Printf("[%d/%d] Error getting function info: %s\n", i+1, attempts, err.Error())
 		}
 
-		if !ready {
-			return fmt.Errorf("function %s not ready after: %s", functionName, interval*time.Duration(attempts).Round(time.Second))
+		if function.AvailableReplicas > 0 {
+			fmt.Printf("Function %s is ready\n", functionName)
+			ready = true
+			break
 		}
+		time.Sleep(interval)
+	}
 
+	if !ready {
+		return fmt.Errorf("function %s not ready after: %s", functionName, interval*time.Duration(attempts).Round(time.Second))
 	}
 
 	return nil
@@ -47,7 +47,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	var gatewayAddress string
 	var yamlGateway string
 	if len(yamlFile) > 0 && len(args) == 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 		if err != nil {
 			return err
 		}
@@ -60,7 +60,7 @@ func runDelete(cmd *cobra.Command, args []string) error {
 
 	gatewayAddress = getGatewayURL(gateway, defaultGateway, yamlGateway, os.Getenv(openFaaSURLEnvironment))
 
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
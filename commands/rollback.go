@@ -0,0 +1,126 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openfaas/faas-cli/proxy"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackRevision        int
+	rollbackVerifySignature bool
+	rollbackSignatureKey    string
+)
+
+func init() {
+	rollbackCmd.Flags().StringVarP(&gateway, "gateway", "g", defaultGateway, "Gateway URL starting with http(s)://")
+	rollbackCmd.Flags().StringVarP(&functionNamespace, "namespace", "n", "", "Namespace of the function")
+	rollbackCmd.Flags().BoolVar(&tlsInsecure, "tls-no-verify", false, "Disable TLS validation")
+	rollbackCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yaml file")
+	rollbackCmd.Flags().StringVarP(&token, "token", "k", "", "Pass a JWT token to use instead of basic auth")
+	rollbackCmd.Flags().IntVar(&rollbackRevision, "to", 0, "Revision to roll back to, defaults to the revision before the current one")
+	rollbackCmd.Flags().DurationVar(&timeoutOverride, "timeout", commandTimeout, "Timeout for any HTTP calls made to the OpenFaaS API.")
+	rollbackCmd.Flags().BoolVar(&rollbackVerifySignature, "verify-signature", false, "Refuse to roll back unless the image has a cosign signature made by --signature-key")
+	rollbackCmd.Flags().StringVar(&rollbackSignatureKey, "signature-key", "", "Path to the PEM public key used to verify image signatures, overrides public_key in the stack.yaml policy")
+
+	faasCmd.AddCommand(rollbackCmd)
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   `rollback FUNCTION_NAME [--to REVISION] [--gateway GATEWAY_URL] [--namespace NAMESPACE]`,
+	Short: "Redeploy a previous revision of a function",
+	Long: `Redeploy a revision of a function from the history recorded by faas-cli,
+see "faas-cli history" for the list of revisions. The rollback is recorded as
+a new revision.
+
+The image is verified first when --verify-signature or the policy in the
+stack.yaml in the current folder asks for signatures to be verified.`,
+	Example: `  # Roll back to the revision before the current one
+  faas-cli rollback figlet
+
+  # Roll back to revision 3
+  faas-cli rollback figlet --to 3`,
+	RunE: runRollback,
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("please provide a name for the function")
+	}
+	functionName := args[0]
+
+	if rollbackRevision < 0 {
+		return fmt.Errorf("--to must be a revision number")
+	}
+
+	gatewayAddress, err := historyGatewayURL()
+	if err != nil {
+		return err
+	}
+
+	history, err := loadDeployHistory(gatewayAddress, functionNamespace, functionName)
+	if err != nil {
+		return err
+	}
+
+	revision, err := history.get(rollbackRevision)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	policy, err := readSignaturePolicy(yamlFile)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := newImageVerifier(ctx, DeployFlags{verifySignature: rollbackVerifySignature, signatureKey: rollbackSignatureKey}, policy)
+	if err != nil {
+		return err
+	}
+
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
+	if err != nil {
+		return err
+	}
+	transport := GetDefaultCLITransport(tlsInsecure, &timeoutOverride)
+	cliClient, err := proxy.NewClient(cliAuth, gatewayAddress, transport, &timeoutOverride)
+	if err != nil {
+		return err
+	}
+
+	spec := revision.Spec
+	spec.Token = token
+	spec.TLSInsecure = tlsInsecure
+	spec.Update = true
+	spec.Replace = false
+
+	// An older revision must still be signed before it is deployed again
+	if verifier != nil {
+		verified, err := verifyImages(verifier, map[string]string{functionName: spec.Image})
+		if err != nil {
+			return err
+		}
+		spec.Image = verified[functionName]
+	}
+
+	if msg := checkTLSInsecure(gatewayAddress, spec.TLSInsecure); len(msg) > 0 {
+		fmt.Println(msg)
+	}
+
+	fmt.Printf("Rolling back %s to revision %d (%s)\n", functionName, revision.Revision, spec.Image)
+	statusCode := cliClient.DeployFunction(ctx, &spec)
+	if badStatusCode(statusCode) {
+		return deployFailed(map[string]int{functionName: statusCode})
+	}
+
+	recordDeployment(cliClient.GatewayURL.String(), &spec)
+
+	return nil
+}
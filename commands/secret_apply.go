@@ -48,7 +48,7 @@ func runSecretApply(cmd *cobra.Command, args []string) error {
 		fmt.Println(msg)
 	}
 
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
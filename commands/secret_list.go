@@ -53,7 +53,7 @@ func runSecretList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
@@ -56,7 +56,7 @@ func runSecretRemove(cmd *cobra.Command, args []string) error {
 		Namespace: functionNamespace,
 	}
 
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,21 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	faasCmd.AddCommand(stackCmd)
+}
+
+// stackCmd groups commands which work with the stack.yaml file
+var stackCmd = &cobra.Command{
+	Use:   `stack [COMMAND]`,
+	Short: "Work with stack.yaml files",
+	Long:  "Inspect the stack.yaml file, including any overlays given with repeated -f flags",
+	Example: `  faas-cli stack render
+  faas-cli stack render -f stack.yaml -f stack.staging.yaml`,
+}
@@ -0,0 +1,203 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// yamlOverlays holds any additional stack files given by repeating the
+// --yaml / -f flag. Each overlay is deep-merged over the files before it.
+var yamlOverlays []string
+
+// stackFilesValue implements pflag.Value for --yaml so that the flag can be
+// repeated, i.e. -f stack.yaml -f stack.staging.yaml
+type stackFilesValue struct {
+	pending []string
+}
+
+func (v *stackFilesValue) String() string {
+	return yamlFile
+}
+
+func (v *stackFilesValue) Set(value string) error {
+	v.pending = append(v.pending, value)
+	return nil
+}
+
+func (v *stackFilesValue) Type() string {
+	return "string"
+}
+
+var stackFiles = &stackFilesValue{}
+
+func init() {
+	cobra.OnInitialize(applyStackFiles)
+}
+
+// applyStackFiles runs after the flags have been parsed and splits the
+// values given for --yaml into the base stack file and its overlays.
+func applyStackFiles() {
+	if len(stackFiles.pending) > 0 {
+		yamlFile = stackFiles.pending[0]
+		yamlOverlays = stackFiles.pending[1:]
+	} else {
+		yamlOverlays = nil
+	}
+
+	stackFiles.pending = nil
+}
+
+// parseYAMLFile parses the stack file along with any overlays given via
+// repeated --yaml flags. When there are no overlays the file is parsed
+// exactly as stack.ParseYAMLFile would.
+func parseYAMLFile(yamlFile, regex, filter string, envsubst bool) (*stack.Services, error) {
+	if len(yamlOverlays) == 0 {
+		return stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+	}
+
+	data, err := mergeStackFiles(append([]string{yamlFile}, yamlOverlays...))
+	if err != nil {
+		return nil, err
+	}
+
+	return stack.ParseYAMLData(data, regex, filter, envsubst)
+}
+
+// readMergedStack returns the raw YAML for the stack file and its overlays,
+// before environment substitution has been applied.
+func readMergedStack(yamlFile string) ([]byte, error) {
+	if len(yamlOverlays) == 0 {
+		return readStackFile(yamlFile)
+	}
+
+	return mergeStackFiles(append([]string{yamlFile}, yamlOverlays...))
+}
+
+// mergeStackFiles reads each file in order and deep-merges it over the
+// result of the files before it.
+//
+// Maps such as environment, labels, annotations, limits and provider are
+// merged key by key, secrets are combined, and any other value is replaced
+// by the value in the later file.
+func mergeStackFiles(files []string) ([]byte, error) {
+	var merged *yaml.Node
+
+	for _, file := range files {
+		data, err := readStackFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+		}
+
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("unable to merge %s: expected a YAML map at the top level", file)
+		}
+
+		if merged == nil {
+			merged = doc.Content[0]
+			continue
+		}
+
+		mergeYAMLNodes(merged, doc.Content[0])
+	}
+
+	if merged == nil {
+		return []byte{}, nil
+	}
+
+	return yaml.Marshal(merged)
+}
+
+// mergeYAMLNodes merges the mapping src into dst, in place.
+func mergeYAMLNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		srcKey := src.Content[i]
+		srcValue := src.Content[i+1]
+
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != srcKey.Value {
+				continue
+			}
+			found = true
+
+			dstValue := dst.Content[j+1]
+			switch {
+			case dstValue.Kind == yaml.MappingNode && srcValue.Kind == yaml.MappingNode:
+				mergeYAMLNodes(dstValue, srcValue)
+			case srcKey.Value == "secrets" && dstValue.Kind == yaml.SequenceNode && srcValue.Kind == yaml.SequenceNode:
+				dstValue.Content = mergeYAMLSequence(dstValue.Content, srcValue.Content)
+			default:
+				dst.Content[j+1] = srcValue
+			}
+			break
+		}
+
+		if !found {
+			dst.Content = append(dst.Content, srcKey, srcValue)
+		}
+	}
+}
+
+// mergeYAMLSequence appends any scalar values from src which are not
+// already present in dst.
+func mergeYAMLSequence(dst, src []*yaml.Node) []*yaml.Node {
+	for _, item := range src {
+		exists := false
+		for _, existing := range dst {
+			if existing.Kind == yaml.ScalarNode && item.Kind == yaml.ScalarNode && existing.Value == item.Value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
+
+// readStackFile reads a stack file from disk, or fetches it when a URL is given.
+func readStackFile(yamlFile string) ([]byte, error) {
+	urlParsed, err := url.Parse(yamlFile)
+	if err != nil || len(urlParsed.Scheme) == 0 {
+		return os.ReadFile(yamlFile)
+	}
+
+	fmt.Println("Parsed: " + urlParsed.String())
+
+	client := http.Client{
+		Timeout: 120 * time.Second,
+	}
+
+	res, err := client.Get(urlParsed.String())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch %s, status code: %d", urlParsed.String(), res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,202 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openfaas/faas-cli/test"
+)
+
+const overlayBaseYAML = `version: 1.0
+provider:
+  name: openfaas
+  gateway: http://127.0.0.1:8080
+functions:
+  api:
+    lang: golang-middleware
+    handler: ./api
+    image: ttl.sh/test/api:latest
+    environment:
+      log_level: debug
+      write_timeout: 10s
+    labels:
+      team: payments
+    secrets:
+      - db-password
+    limits:
+      memory: 128Mi
+      cpu: 100m
+  worker:
+    lang: python3
+    handler: ./worker
+    image: ttl.sh/test/worker:latest
+`
+
+const overlayStagingYAML = `provider:
+  gateway: https://staging.example.com
+functions:
+  api:
+    image: ttl.sh/test/api:0.1.0
+    environment:
+      log_level: info
+    labels:
+      env: staging
+    secrets:
+      - db-password
+      - api-key
+    limits:
+      memory: 256Mi
+`
+
+func writeOverlayFiles(t *testing.T) (string, string) {
+	tmpDir := t.TempDir()
+
+	basePath := filepath.Join(tmpDir, "stack.yaml")
+	if err := os.WriteFile(basePath, []byte(overlayBaseYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	overlayPath := filepath.Join(tmpDir, "stack.staging.yaml")
+	if err := os.WriteFile(overlayPath, []byte(overlayStagingYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return basePath, overlayPath
+}
+
+func Test_parseYAMLFile_MergesOverlays(t *testing.T) {
+	basePath, overlayPath := writeOverlayFiles(t)
+
+	resetForTest()
+	defer resetForTest()
+
+	yamlOverlays = []string{overlayPath}
+
+	services, err := parseYAMLFile(basePath, "", "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if services.Provider.GatewayURL != "https://staging.example.com" {
+		t.Errorf("want gateway from overlay, got %q", services.Provider.GatewayURL)
+	}
+	if services.Provider.Name != "openfaas" {
+		t.Errorf("want provider name from base file, got %q", services.Provider.Name)
+	}
+
+	api := services.Functions["api"]
+	if api.Image != "ttl.sh/test/api:0.1.0" {
+		t.Errorf("want image from overlay, got %q", api.Image)
+	}
+	if api.Language != "golang-middleware" {
+		t.Errorf("want lang from base file, got %q", api.Language)
+	}
+
+	wantEnv := map[string]string{"log_level": "info", "write_timeout": "10s"}
+	if !reflect.DeepEqual(api.Environment, wantEnv) {
+		t.Errorf("want environment %v, got %v", wantEnv, api.Environment)
+	}
+
+	wantLabels := map[string]string{"team": "payments", "env": "staging"}
+	if api.Labels == nil || !reflect.DeepEqual(*api.Labels, wantLabels) {
+		t.Errorf("want labels %v, got %v", wantLabels, api.Labels)
+	}
+
+	wantSecrets := []string{"db-password", "api-key"}
+	if !reflect.DeepEqual(api.Secrets, wantSecrets) {
+		t.Errorf("want secrets %v, got %v", wantSecrets, api.Secrets)
+	}
+
+	if api.Limits == nil || api.Limits.Memory != "256Mi" || api.Limits.CPU != "100m" {
+		t.Errorf("want limits memory: 256Mi, cpu: 100m, got %v", api.Limits)
+	}
+
+	if _, ok := services.Functions["worker"]; !ok {
+		t.Errorf("want worker function from base file to be kept")
+	}
+}
+
+func Test_parseYAMLFile_OverlaysAppliedBeforeFilter(t *testing.T) {
+	basePath, overlayPath := writeOverlayFiles(t)
+
+	resetForTest()
+	defer resetForTest()
+
+	yamlOverlays = []string{overlayPath}
+
+	services, err := parseYAMLFile(basePath, "", "api", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(services.Functions) != 1 {
+		t.Fatalf("want 1 function, got %d", len(services.Functions))
+	}
+	if services.Functions["api"].Image != "ttl.sh/test/api:0.1.0" {
+		t.Errorf("want image from overlay, got %q", services.Functions["api"].Image)
+	}
+}
+
+func Test_mergeStackFiles_RejectsNonMapDocument(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	basePath := filepath.Join(tmpDir, "stack.yaml")
+	if err := os.WriteFile(basePath, []byte(overlayBaseYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	overlayPath := filepath.Join(tmpDir, "list.yaml")
+	if err := os.WriteFile(overlayPath, []byte("- one\n- two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := mergeStackFiles([]string{basePath, overlayPath})
+	if err == nil {
+		t.Fatal("want error for an overlay which is not a map")
+	}
+	if !strings.Contains(err.Error(), "expected a YAML map") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func Test_stackRender_RepeatedYAMLFlag(t *testing.T) {
+	basePath, overlayPath := writeOverlayFiles(t)
+
+	resetForTest()
+	defer resetForTest()
+
+	var executeErr error
+	stdOut := test.CaptureStdout(func() {
+		faasCmd.SetArgs([]string{
+			"stack", "render",
+			"-f", basePath,
+			"-f", overlayPath,
+		})
+		executeErr = faasCmd.Execute()
+	})
+
+	if executeErr != nil {
+		t.Fatalf("unexpected error: %s", executeErr)
+	}
+
+	if yamlFile != basePath {
+		t.Errorf("want yamlFile %q, got %q", basePath, yamlFile)
+	}
+
+	for _, want := range []string{
+		"gateway: https://staging.example.com",
+		"image: ttl.sh/test/api:0.1.0",
+		"write_timeout: 10s",
+		"- api-key",
+	} {
+		if !strings.Contains(stdOut, want) {
+			t.Errorf("want %q in output, got:\n%s", want, stdOut)
+		}
+	}
+}
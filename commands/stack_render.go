@@ -0,0 +1,57 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func init() {
+	stackRenderCmd.Flags().BoolVar(&envsubst, "envsubst", true, "Substitute environment variables in stack.yaml file")
+
+	stackCmd.AddCommand(stackRenderCmd)
+}
+
+var stackRenderCmd = &cobra.Command{
+	Use:   `render [-f YAML_FILE]... [--filter "WILDCARD"] [--regex "REGEX"]`,
+	Short: "Print the stack.yaml file after merging any overlays",
+	Long: `Prints the stack.yaml file after each file given with -f has been merged
+over the one before it, and after environment variables have been substituted.
+
+Maps such as environment, labels, annotations, limits and provider are merged
+key by key, secrets are combined, and any other value is replaced by the later
+file.`,
+	Example: `  faas-cli stack render
+  faas-cli stack render -f stack.yaml -f stack.prod.yaml
+  faas-cli stack render -f stack.yaml -f stack.dev.yaml --filter "api-*"
+  faas-cli stack render -f stack.yaml -f stack.dev.yaml --envsubst=false`,
+	RunE: runStackRender,
+}
+
+func runStackRender(cmd *cobra.Command, args []string) error {
+	if len(yamlFile) == 0 {
+		return fmt.Errorf("no YAML file specified - use -f to provide a stack.yaml")
+	}
+
+	services, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(services); err != nil {
+		return err
+	}
+	encoder.Close()
+
+	fmt.Print(buf.String())
+
+	return nil
+}
@@ -136,7 +136,7 @@ func runStoreDeploy(cmd *cobra.Command, args []string) error {
 	imageName := item.GetImageName(targetPlatform)
 
 	gateway = getGatewayURL(gateway, defaultGateway, "", os.Getenv(openFaaSURLEnvironment))
-	cliAuth, err := proxy.NewCLIAuth(token, gateway)
+	cliAuth, err := proxy.NewCLIAuth(token, gateway, tlsInsecure)
 	if err != nil {
 		return err
 	}
@@ -20,5 +20,8 @@ var templateCmd = &cobra.Command{
   faas-cli template store list
   faas-cli template store ls
   faas-cli template store pull ruby-http
-  faas-cli template store pull openfaas-incubator/ruby-http`,
+  faas-cli template store pull openfaas-incubator/ruby-http
+  faas-cli template outdated
+  faas-cli template upgrade golang-middleware
+  faas-cli template publish ./template/golang-middleware oci://ghcr.io/org/tpl:1.0`,
 }
@@ -0,0 +1,213 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// TemplateLockFile records the commit each template was pulled from, it is
+// written next to the stack.yaml.
+const TemplateLockFile = "templates.lock"
+
+const templateLockHeader = "# Written by faas-cli template pull, update with faas-cli template upgrade\n"
+
+// templateLockMode controls how a pull uses the templates.lock.
+type templateLockMode int
+
+const (
+	// lockIfExists verifies and records templates only when there is
+	// already a templates.lock, such as for templates pulled by build.
+	lockIfExists templateLockMode = iota
+
+	// lockRecord verifies templates already in the templates.lock, and
+	// creates it to record new ones.
+	lockRecord
+
+	// lockUpgrade records the commit which was pulled, replacing any
+	// commit which was locked before.
+	lockUpgrade
+)
+
+// templateLock is the templates.lock file.
+type templateLock struct {
+	Templates []lockedTemplate `yaml:"templates"`
+
+	path   string
+	exists bool
+}
+
+// lockedTemplate is a template as written to ./template/<name>.
+type lockedTemplate struct {
+	Name       string `yaml:"name"`
+	Repository string `yaml:"repository"`
+	RefName    string `yaml:"ref,omitempty"`
+	Sha        string `yaml:"sha"`
+}
+
+// templateLockPath returns the path of the templates.lock next to the
+// stack.yaml, or in the current directory when there is no stack.yaml.
+func templateLockPath() string {
+	if len(yamlFile) > 0 && !strings.HasPrefix(yamlFile, "http://") && !strings.HasPrefix(yamlFile, "https://") {
+		return filepath.Join(filepath.Dir(yamlFile), TemplateLockFile)
+	}
+	return TemplateLockFile
+}
+
+// loadTemplateLock reads the templates.lock, which is empty when the file
+// does not exist yet.
+func loadTemplateLock(path string) (*templateLock, error) {
+	lock := &templateLock{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	lock.exists = true
+
+	return lock, nil
+}
+
+// Get returns the locked template by name.
+func (l *templateLock) Get(name string) (lockedTemplate, bool) {
+	for _, t := range l.Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return lockedTemplate{}, false
+}
+
+// Set records or replaces a locked template.
+func (l *templateLock) Set(locked lockedTemplate) {
+	for i, t := range l.Templates {
+		if t.Name == locked.Name {
+			l.Templates[i] = locked
+			return
+		}
+	}
+	l.Templates = append(l.Templates, locked)
+}
+
+// Verify returns an error when a template in the lock would be replaced by
+// a different repository or commit.
+func (l *templateLock) Verify(fetched lockedTemplate) error {
+	locked, ok := l.Get(fetched.Name)
+	if !ok {
+		return nil
+	}
+
+	if locked.Repository != fetched.Repository || locked.RefName != fetched.RefName {
+		return fmt.Errorf("template %s is locked to %s in %s, not %s, run \"faas-cli template upgrade %s\" to change it",
+			fetched.Name, templateSource(locked), l.path, templateSource(fetched), fetched.Name)
+	}
+
+	if locked.Sha != fetched.Sha {
+		return fmt.Errorf("template %s has drifted from %s: locked at %s, fetched %s, run \"faas-cli template upgrade %s\" to update it",
+			fetched.Name, l.path, shortSHA(locked.Sha), shortSHA(fetched.Sha), fetched.Name)
+	}
+
+	return nil
+}
+
+// Save writes the templates.lock, sorted by name.
+func (l *templateLock) Save() error {
+	sort.Slice(l.Templates, func(i, j int) bool {
+		return l.Templates[i].Name < l.Templates[j].Name
+	})
+
+	var buff bytes.Buffer
+	buff.WriteString(templateLockHeader)
+
+	encoder := yaml.NewEncoder(&buff)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(l.path, buff.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", l.path, err)
+	}
+	l.exists = true
+
+	return nil
+}
+
+// lockTemplates verifies the templates about to be written to
+// ./template against the lock, then records them, according to the mode.
+// The lock is only saved by the caller once the templates are written.
+func lockTemplates(lock *templateLock, mode templateLockMode, fetched []lockedTemplate) error {
+	if mode == lockIfExists && !lock.exists {
+		return nil
+	}
+
+	if mode != lockUpgrade {
+		for _, t := range fetched {
+			if err := lock.Verify(t); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, t := range fetched {
+		lock.Set(t)
+	}
+	return nil
+}
+
+// extractedTemplateNames returns the names the templates in a clone will be
+// written as under ./template, matching moveTemplates.
+func extractedTemplateNames(extractedPath, templateName, refName string) ([]string, error) {
+	if len(templateName) > 0 {
+		return []string{templateName}, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(extractedPath, TemplateDirectory))
+	if err != nil {
+		return nil, fmt.Errorf("can't find templates in: %s", filepath.Join(extractedPath, TemplateDirectory))
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		if len(refName) > 0 {
+			name += "@" + refName
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func templateSource(t lockedTemplate) string {
+	if len(t.RefName) > 0 {
+		return t.Repository + "#" + t.RefName
+	}
+	return t.Repository
+}
+
+func shortSHA(sha string) string {
+	sha = strings.TrimPrefix(sha, "sha256:")
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
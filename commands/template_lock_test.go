@@ -0,0 +1,230 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v2execute "github.com/alexellis/go-execute/v2"
+	"github.com/openfaas/faas-cli/test"
+	"github.com/openfaas/faas-cli/versioncontrol"
+)
+
+func Test_lockTemplates(t *testing.T) {
+	dogfood := lockedTemplate{Name: "dockerfile", Repository: "https://github.com/openfaas/templates", Sha: "1111111aaaa"}
+
+	t.Run("no lock is created by builds", func(t *testing.T) {
+		lock := &templateLock{path: TemplateLockFile}
+		if err := lockTemplates(lock, lockIfExists, []lockedTemplate{dogfood}); err != nil {
+			t.Fatal(err)
+		}
+		if len(lock.Templates) != 0 {
+			t.Fatalf("want no templates recorded, got %v", lock.Templates)
+		}
+	})
+
+	t.Run("pull records new templates", func(t *testing.T) {
+		lock := &templateLock{path: TemplateLockFile}
+		if err := lockTemplates(lock, lockRecord, []lockedTemplate{dogfood}); err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := lock.Get("dockerfile"); !ok || got != dogfood {
+			t.Fatalf("want %v recorded, got %v", dogfood, got)
+		}
+	})
+
+	drifted := dogfood
+	drifted.Sha = "2222222bbbb"
+
+	moved := dogfood
+	moved.RefName = "v2"
+
+	for _, mode := range []templateLockMode{lockIfExists, lockRecord} {
+		for _, tc := range []struct {
+			fetched lockedTemplate
+			want    string
+		}{
+			{fetched: drifted, want: "template dockerfile has drifted from templates.lock: locked at 1111111, fetched 2222222"},
+			{fetched: moved, want: "template dockerfile is locked to https://github.com/openfaas/templates in templates.lock, not https://github.com/openfaas/templates#v2"},
+		} {
+			t.Run(fmt.Sprintf("mode %d fails on %s", mode, tc.fetched.Sha), func(t *testing.T) {
+				lock := &templateLock{path: TemplateLockFile, exists: true, Templates: []lockedTemplate{dogfood}}
+
+				err := lockTemplates(lock, mode, []lockedTemplate{tc.fetched})
+				if err == nil || !strings.Contains(err.Error(), tc.want) {
+					t.Fatalf("want an error containing %q, got: %v", tc.want, err)
+				}
+				if got, _ := lock.Get("dockerfile"); got != dogfood {
+					t.Fatalf("want the lock unchanged, got %v", got)
+				}
+			})
+		}
+	}
+
+	t.Run("upgrade replaces the locked commit", func(t *testing.T) {
+		lock := &templateLock{path: TemplateLockFile, exists: true, Templates: []lockedTemplate{dogfood}}
+		if err := lockTemplates(lock, lockUpgrade, []lockedTemplate{drifted}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := lock.Get("dockerfile"); got != drifted {
+			t.Fatalf("want %v recorded, got %v", drifted, got)
+		}
+	})
+}
+
+func Test_templateLock_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), TemplateLockFile)
+
+	lock, err := loadTemplateLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.exists {
+		t.Fatalf("want no lock before it is saved")
+	}
+
+	lock.Set(lockedTemplate{Name: "python3", Repository: "https://github.com/openfaas/templates", Sha: "bbb"})
+	lock.Set(lockedTemplate{Name: "golang-middleware", Repository: "https://github.com/openfaas/golang-http-template", RefName: "v1", Sha: "aaa"})
+	if err := lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := loadTemplateLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.exists || len(saved.Templates) != 2 {
+		t.Fatalf("want 2 templates saved, got %v", saved.Templates)
+	}
+	if saved.Templates[0].Name != "golang-middleware" || saved.Templates[0].RefName != "v1" {
+		t.Fatalf("want the templates sorted by name, got %v", saved.Templates)
+	}
+}
+
+func Test_checkTemplateStatus(t *testing.T) {
+	lock := &templateLock{
+		Templates: []lockedTemplate{
+			{Name: "dockerfile", Repository: "https://github.com/openfaas/templates", Sha: "aaa"},
+			{Name: "python3", Repository: "https://github.com/openfaas/templates", Sha: "aaa"},
+			{Name: "golang-middleware", Repository: "https://github.com/openfaas/golang-http-template", RefName: "v1", Sha: "bbb"},
+			{Name: "node20", Repository: "https://github.com/openfaas/templates", RefName: "sha-ccc", Sha: "ccc"},
+			{Name: "java11", Repository: "https://example.com/missing", Sha: "ddd"},
+		},
+	}
+
+	calls := 0
+	remoteSHA := func(repository, refName string) (string, error) {
+		calls++
+		switch repository {
+		case "https://github.com/openfaas/templates":
+			return "aaa", nil
+		case "https://github.com/openfaas/golang-http-template":
+			return "eee", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+
+	want := map[string]string{
+		"dockerfile":        "up to date",
+		"python3":           "up to date",
+		"golang-middleware": "outdated",
+		"node20":            "pinned",
+		"java11":            "unknown: not found",
+	}
+
+	for _, s := range checkTemplateStatus(lock, remoteSHA) {
+		if s.status != want[s.locked.Name] {
+			t.Errorf("%s: want status %q, got %q", s.locked.Name, want[s.locked.Name], s.status)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("want each repository looked up once, got %d calls", calls)
+	}
+}
+
+func Test_templateLock_pullOutdatedUpgrade(t *testing.T) {
+	repository := setupLocalTemplateRepo(t)
+	defer os.RemoveAll(repository)
+	defer tearDownFetchTemplates(t)
+
+	locked := func() lockedTemplate {
+		t.Helper()
+		lock, err := loadTemplateLock(TemplateLockFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locked, ok := lock.Get("dockerfile")
+		if !ok {
+			t.Fatalf("want dockerfile in %s, got %v", TemplateLockFile, lock.Templates)
+		}
+		return locked
+	}
+
+	run := func(args ...string) (string, error) {
+		resetForTest()
+		var err error
+		stdOut := test.CaptureStdout(func() {
+			faasCmd.SetArgs(args)
+			err = faasCmd.Execute()
+		})
+		return stdOut, err
+	}
+
+	if out, err := run("template", "pull", repository); err != nil {
+		t.Fatalf("template pull: %v\n%s", err, out)
+	}
+
+	head, err := versioncontrol.GetRemoteSHA(repository, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := locked(); got.Sha != head || got.Repository != repository {
+		t.Fatalf("want dockerfile locked to %s, got %v", head, got)
+	}
+
+	if out, err := run("template", "outdated"); err != nil || !strings.Contains(out, "up to date") {
+		t.Fatalf("want templates up to date, got: %v\n%s", err, out)
+	}
+
+	// A new commit upstream makes the locked templates drift
+	if err := os.WriteFile(filepath.Join(repository, "CHANGELOG.md"), []byte("v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "Second commit"}} {
+		res, err := v2execute.ExecTask{Command: "git", Args: append([]string{"-C", repository}, args...)}.Execute(context.Background())
+		if err != nil || res.ExitCode != 0 {
+			t.Fatalf("git %v: %v %s", args, err, res.Stderr)
+		}
+	}
+
+	newHead, err := versioncontrol.GetRemoteSHA(repository, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("template", "pull", repository)
+	if err == nil || !strings.Contains(err.Error(), "has drifted from templates.lock") {
+		t.Fatalf("want the pull to fail on drift, got: %v\n%s", err, out)
+	}
+
+	if out, err := run("template", "outdated"); err != nil || !strings.Contains(out, "outdated") {
+		t.Fatalf("want templates outdated, got: %v\n%s", err, out)
+	}
+
+	if out, err := run("template", "upgrade", "dockerfile"); err != nil {
+		t.Fatalf("template upgrade: %v\n%s", err, out)
+	}
+	if got := locked(); got.Sha != newHead {
+		t.Fatalf("want dockerfile upgraded to %s, got %v", newHead, got)
+	}
+
+	if _, err := run("template", "upgrade", "cobol"); err == nil || !strings.Contains(err.Error(), "template cobol is not in templates.lock") {
+		t.Fatalf("want an error for a template which is not locked, got: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openfaas/faas-cli/versioncontrol"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	templateCmd.AddCommand(templateOutdatedCmd)
+}
+
+var templateOutdatedCmd = &cobra.Command{
+	Use:   `outdated`,
+	Short: `Reports templates which have newer commits upstream`,
+	Long: `Compares the commit of each template in templates.lock with the latest commit
+of the branch or tag it was pulled from, or the digest of the image or tarball.
+Templates pinned to a SHA or an image digest are not checked. Nothing is
+changed, use "faas-cli template upgrade" to update the lock.`,
+	Example: `  faas-cli template outdated
+  faas-cli template outdated -f stack.yaml`,
+	RunE: runTemplateOutdated,
+}
+
+// templateStatus compares a locked template with the latest commit upstream.
+type templateStatus struct {
+	locked lockedTemplate
+	latest string
+	status string
+}
+
+func runTemplateOutdated(cmd *cobra.Command, args []string) error {
+	lock, err := loadTemplateLock(templateLockPath())
+	if err != nil {
+		return err
+	}
+	if !lock.exists {
+		return fmt.Errorf("no %s found, run \"faas-cli template pull\" to create it", lock.path)
+	}
+
+	statuses := checkTemplateStatus(lock, latestTemplateSHA)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEMPLATE\tSOURCE\tLOCKED\tLATEST\tSTATUS")
+	outdated := 0
+	for _, s := range statuses {
+		if s.status == "outdated" {
+			outdated++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.locked.Name, templateSource(s.locked), shortSHA(s.locked.Sha), shortSHA(s.latest), s.status)
+	}
+	w.Flush()
+
+	if outdated > 0 {
+		fmt.Printf("\n%d template(s) outdated, run \"faas-cli template upgrade\" to update them.\n", outdated)
+	}
+	return nil
+}
+
+// checkTemplateStatus looks up the latest commit of each locked template,
+// each repository and ref is only looked up once.
+func checkTemplateStatus(lock *templateLock, remoteSHA func(repository, refName string) (string, error)) []templateStatus {
+	latest := map[string]string{}
+	failed := map[string]error{}
+
+	statuses := make([]templateStatus, 0, len(lock.Templates))
+	for _, t := range lock.Templates {
+		s := templateStatus{locked: t}
+
+		if strings.HasPrefix(t.RefName, ShaPrefix) || isPinnedImageSource(t.Repository) {
+			s.latest = t.Sha
+			s.status = "pinned"
+			statuses = append(statuses, s)
+			continue
+		}
+
+		source := templateSource(t)
+		if _, ok := latest[source]; !ok && failed[source] == nil {
+			sha, err := remoteSHA(t.Repository, t.RefName)
+			if err != nil {
+				failed[source] = err
+			} else {
+				latest[source] = sha
+			}
+		}
+
+		switch {
+		case failed[source] != nil:
+			s.status = "unknown: " + failed[source].Error()
+		case latest[source] == t.Sha:
+			s.latest = latest[source]
+			s.status = "up to date"
+		default:
+			s.latest = latest[source]
+			s.status = "outdated"
+		}
+		statuses = append(statuses, s)
+	}
+
+	return statuses
+}
+
+// latestTemplateSHA returns the latest commit of a git repository, or the
+// current digest of an image or tarball.
+func latestTemplateSHA(repository, refName string) (string, error) {
+	if isArchiveTemplateSource(repository) {
+		return archiveTemplateDigest(repository)
+	}
+	return versioncontrol.GetRemoteSHA(repository, refName)
+}
+
+func isPinnedImageSource(repository string) bool {
+	return strings.HasPrefix(repository, ociTemplateScheme) && strings.Contains(repository, "@sha256:")
+}
@@ -0,0 +1,362 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	ggcrname "github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/openfaas/faas-cli/util"
+	"github.com/openfaas/faas-cli/versioncontrol"
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+)
+
+// templateConfigMediaType marks an image as a template published by
+// faas-cli, rather than an image which can be run.
+const templateConfigMediaType types.MediaType = "application/vnd.openfaas.template.config.v1+json"
+
+// Annotations added to a published template, as well as the standard
+// org.opencontainers.image ones.
+const (
+	templateLanguageAnnotation = "com.openfaas.template.language"
+	templateFProcessAnnotation = "com.openfaas.template.fprocess"
+)
+
+var gitCommitSHA = regexp.MustCompile(`^[a-f0-9]{40}$`)
+
+var (
+	templatePublishName        string
+	templatePublishDescription string
+	templatePublishAnnotations []string
+	templatePublishStoreFile   string
+)
+
+func init() {
+	templatePublishCmd.Flags().StringVar(&templatePublishName, "name", "", "Name of the template, defaults to the name of the folder")
+	templatePublishCmd.Flags().StringVar(&templatePublishDescription, "description", "", "Description of the template for the annotations and the store file")
+	templatePublishCmd.Flags().StringArrayVar(&templatePublishAnnotations, "annotation", []string{}, "Add an annotation to the artifact (KEY=VALUE)")
+	templatePublishCmd.Flags().StringVar(&templatePublishStoreFile, "store-file", "", "Add or update the template in a template store JSON file")
+
+	templateCmd.AddCommand(templatePublishCmd)
+}
+
+var templatePublishCmd = &cobra.Command{
+	Use:   `publish TEMPLATE_FOLDER oci://REGISTRY/REPOSITORY:TAG`,
+	Short: `Publishes a template folder to a registry as an OCI artifact`,
+	Long: `Validates the template.yml of a template folder, then pushes the folder to a
+registry as an OCI artifact, using the credentials from the docker config.
+The artifact can be pulled with "faas-cli template pull oci://...", or from
+the configuration.templates section of stack.yaml.
+
+Use --store-file to list the template in a template store JSON file, which
+can be read with "faas-cli template store list --url file://templates.json",
+or served over HTTP.`,
+	Example: `  faas-cli template publish ./template/golang-middleware oci://ghcr.io/org/tpl:1.0
+  faas-cli template publish ./template/python3-http oci://registry:5000/templates/python3-http:1.2 \
+    --description "Python 3 HTTP template" \
+    --annotation org.opencontainers.image.source=https://github.com/org/templates \
+    --store-file ./templates.json`,
+	RunE: runTemplatePublish,
+}
+
+func runTemplatePublish(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("give the template folder and an oci:// reference to publish it to")
+	}
+
+	dir := args[0]
+	image, ok := strings.CutPrefix(args[1], ociTemplateScheme)
+	if !ok {
+		return fmt.Errorf("the reference must start with %s, got: %s", ociTemplateScheme, args[1])
+	}
+
+	ref, err := ggcrname.ParseReference(image)
+	if err != nil {
+		return fmt.Errorf("invalid reference %s: %w", args[1], err)
+	}
+
+	name := templatePublishName
+	if len(name) == 0 {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		name = filepath.Base(abs)
+	}
+
+	langTemplate, err := validateLanguageTemplate(dir)
+	if err != nil {
+		return err
+	}
+
+	extra, err := util.ParseMap(templatePublishAnnotations, "annotation")
+	if err != nil {
+		return err
+	}
+
+	annotations := templateAnnotations(dir, name, ref, langTemplate)
+	if len(templatePublishDescription) > 0 {
+		annotations["org.opencontainers.image.description"] = templatePublishDescription
+	}
+	annotations = util.MergeMap(annotations, extra)
+
+	img, err := templateArtifact(dir, name, annotations)
+	if err != nil {
+		return err
+	}
+
+	if err := crane.Push(img, ref.String(), crane.WithContext(cmd.Context())); err != nil {
+		return fmt.Errorf("unable to push %s: %w", ref, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Published template: %s to %s%s@%s\n", name, ociTemplateScheme, ref, digest)
+
+	if len(templatePublishStoreFile) > 0 {
+		info := TemplateInfo{
+			TemplateName: name,
+			Platform:     mainPlatform,
+			Language:     langTemplate.Language,
+			Source:       templateStoreSource(ref),
+			Description:  templatePublishDescription,
+			Repository:   ociTemplateScheme + ref.String(),
+			Official:     "false",
+		}
+		if err := updateTemplateStoreFile(templatePublishStoreFile, info); err != nil {
+			return err
+		}
+		fmt.Printf("Updated template store: %s\n", templatePublishStoreFile)
+	}
+
+	return nil
+}
+
+// validateLanguageTemplate checks the template.yml of a template folder
+// before it is published.
+func validateLanguageTemplate(dir string) (*stack.LanguageTemplate, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "template.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("can't find template.yml in: %s", dir)
+	}
+
+	langTemplate, err := stack.ParseYAMLDataForLanguageTemplate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template.yml in %s: %w", dir, err)
+	}
+
+	if len(langTemplate.Language) == 0 {
+		return nil, fmt.Errorf("template.yml in %s needs a language", dir)
+	}
+
+	// A template's Dockerfile runs the fprocess in the watchdog, only the
+	// dockerfile template has none as it builds the function's Dockerfile
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err == nil && len(langTemplate.FProcess) == 0 {
+		return nil, fmt.Errorf("template.yml in %s needs an fprocess", dir)
+	}
+
+	seen := map[string]bool{}
+	for i, option := range langTemplate.BuildOptions {
+		if len(option.Name) == 0 {
+			return nil, fmt.Errorf("build_options[%d] in %s needs a name", i, dir)
+		}
+		if seen[option.Name] {
+			return nil, fmt.Errorf("build option %s is given more than once in %s", option.Name, dir)
+		}
+		seen[option.Name] = true
+	}
+
+	if len(langTemplate.HandlerFolder) > 0 {
+		handler := filepath.Clean(langTemplate.HandlerFolder)
+		if filepath.IsAbs(handler) || handler == ".." || strings.HasPrefix(handler, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("handler_folder %s must be within the template", langTemplate.HandlerFolder)
+		}
+		if info, err := os.Stat(filepath.Join(dir, handler)); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("handler_folder %s is not a folder in %s", langTemplate.HandlerFolder, dir)
+		}
+	}
+
+	return langTemplate, nil
+}
+
+// templateAnnotations describes the template, the revision is only given
+// when the folder is within a git repository.
+func templateAnnotations(dir, name string, ref ggcrname.Reference, langTemplate *stack.LanguageTemplate) map[string]string {
+	annotations := map[string]string{
+		"org.opencontainers.image.title": name,
+		templateLanguageAnnotation:       langTemplate.Language,
+	}
+
+	if tag, ok := ref.(ggcrname.Tag); ok {
+		annotations["org.opencontainers.image.version"] = tag.TagStr()
+	}
+	if len(langTemplate.FProcess) > 0 {
+		annotations[templateFProcessAnnotation] = langTemplate.FProcess
+	}
+	if sha, err := versioncontrol.GetGitSHAFor(dir, false); err == nil && gitCommitSHA.MatchString(sha) {
+		annotations["org.opencontainers.image.revision"] = sha
+	}
+
+	return annotations
+}
+
+// templateArtifact packages a template folder as template/<name> in a single
+// layer, in the same layout as a git repository of templates. The layer has
+// no timestamps, so that publishing the same files gives the same digest.
+func templateArtifact(dir, name string, annotations map[string]string) (v1.Image, error) {
+	data, err := templateTar(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}, tarball.WithMediaType(types.OCILayer))
+	if err != nil {
+		return nil, err
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, templateConfigMediaType)
+	img, err = mutate.AppendLayers(img, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	return mutate.Annotations(img, annotations).(v1.Image), nil
+}
+
+func templateTar(dir, name string) ([]byte, error) {
+	var buff bytes.Buffer
+	tw := tar.NewWriter(&buff)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join("template", name, filepath.ToSlash(rel))
+		if d.IsDir() {
+			header.Name += "/"
+		}
+		header.ModTime = time.Time{}
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+		header.Format = tar.FormatPAX
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to package %s: %w", dir, err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+// templateStoreSource lists a template published to registry/org/tpl as
+// from registry/org.
+func templateStoreSource(ref ggcrname.Reference) string {
+	repository := ref.Context().Name()
+	return repository[:strings.LastIndex(repository, "/")]
+}
+
+// updateTemplateStoreFile adds the template to a template store JSON file,
+// or replaces it when a template with the same name and platform is there.
+func updateTemplateStoreFile(storeFile string, info TemplateInfo) error {
+	templates := []TemplateInfo{}
+
+	data, err := os.ReadFile(storeFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read %s: %w", storeFile, err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &templates); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", storeFile, err)
+		}
+	}
+
+	found := false
+	for i, t := range templates {
+		if t.TemplateName == info.TemplateName && strings.EqualFold(t.Platform, info.Platform) {
+			templates[i] = info
+			found = true
+		}
+	}
+	if !found {
+		templates = append(templates, info)
+	}
+
+	out, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(storeFile, append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write %s: %w", storeFile, err)
+	}
+	return nil
+}
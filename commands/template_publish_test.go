@@ -0,0 +1,211 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/openfaas/faas-cli/test"
+)
+
+func Test_validateLanguageTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		wantErr  string
+		wantLang string
+	}{
+		{
+			name:     "template with fprocess",
+			files:    map[string]string{"template.yml": "language: ruby\nfprocess: ruby index.rb\n", "Dockerfile": "FROM ruby\n"},
+			wantLang: "ruby",
+		},
+		{
+			name:     "dockerfile template without a Dockerfile",
+			files:    map[string]string{"template.yml": "language: dockerfile\n", "function/Dockerfile": "FROM alpine\n"},
+			wantLang: "dockerfile",
+		},
+		{
+			name:     "handler folder",
+			files:    map[string]string{"template.yml": "language: go\nfprocess: ./handler\nhandler_folder: function\n", "Dockerfile": "FROM golang\n", "function/handler.go": "package function\n"},
+			wantLang: "go",
+		},
+		{
+			name:    "missing template.yml",
+			files:   map[string]string{"Dockerfile": "FROM alpine\n"},
+			wantErr: "can't find template.yml",
+		},
+		{
+			name:    "missing language",
+			files:   map[string]string{"template.yml": "fprocess: ./handler\n"},
+			wantErr: "needs a language",
+		},
+		{
+			name:    "Dockerfile without fprocess",
+			files:   map[string]string{"template.yml": "language: go\n", "Dockerfile": "FROM golang\n"},
+			wantErr: "needs an fprocess",
+		},
+		{
+			name:    "unnamed build option",
+			files:   map[string]string{"template.yml": "language: python3\nfprocess: python3 index.py\nbuild_options:\n- packages: [gcc]\n"},
+			wantErr: "build_options[0]",
+		},
+		{
+			name:    "repeated build option",
+			files:   map[string]string{"template.yml": "language: python3\nfprocess: python3 index.py\nbuild_options:\n- name: dev\n- name: dev\n"},
+			wantErr: "build option dev is given more than once",
+		},
+		{
+			name:    "missing handler folder",
+			files:   map[string]string{"template.yml": "language: go\nfprocess: ./handler\nhandler_folder: function\n"},
+			wantErr: "handler_folder function is not a folder",
+		},
+		{
+			name:    "handler folder outside the template",
+			files:   map[string]string{"template.yml": "language: go\nfprocess: ./handler\nhandler_folder: ../other\n"},
+			wantErr: "must be within the template",
+		},
+		{
+			name:    "invalid YAML",
+			files:   map[string]string{"template.yml": "language: [go\n"},
+			wantErr: "invalid template.yml",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tc.files {
+				p := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			langTemplate, err := validateLanguageTemplate(dir)
+			if len(tc.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("want an error containing %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateLanguageTemplate: %v", err)
+			}
+			if langTemplate.Language != tc.wantLang {
+				t.Fatalf("want language %s, got %s", tc.wantLang, langTemplate.Language)
+			}
+		})
+	}
+}
+
+func Test_templatePublish(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	host := newTestRegistry(t)
+
+	ref := host + "/templates/ruby:1.0"
+	storeFile := filepath.Join(t.TempDir(), "templates.json")
+	templateDir := filepath.Join("testdata", "templates", "template", "ruby")
+
+	publish := func() string {
+		t.Helper()
+		resetForTest()
+		var err error
+		stdOut := test.CaptureStdout(func() {
+			faasCmd.SetArgs([]string{
+				"template", "publish", templateDir, "oci://" + ref,
+				"--description", "Ruby template",
+				"--annotation", "org.opencontainers.image.source=https://example.com/templates",
+				"--store-file", storeFile,
+			})
+			err = faasCmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("template publish: %v\n%s", err, stdOut)
+		}
+		return stdOut
+	}
+
+	first := publish()
+	if !strings.Contains(first, "Published template: ruby to oci://"+ref+"@sha256:") {
+		t.Fatalf("want the digest in the output, got:\n%s", first)
+	}
+
+	data, err := crane.Manifest(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest v1.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Config.MediaType != templateConfigMediaType {
+		t.Fatalf("want config media type %s, got %s", templateConfigMediaType, manifest.Config.MediaType)
+	}
+
+	wantAnnotations := map[string]string{
+		"org.opencontainers.image.title":       "ruby",
+		"org.opencontainers.image.version":     "1.0",
+		"org.opencontainers.image.description": "Ruby template",
+		"org.opencontainers.image.source":      "https://example.com/templates",
+		templateLanguageAnnotation:             "ruby",
+		templateFProcessAnnotation:             "ruby index.rb",
+	}
+	for k, v := range wantAnnotations {
+		if manifest.Annotations[k] != v {
+			t.Errorf("want annotation %s=%s, got %q", k, v, manifest.Annotations[k])
+		}
+	}
+
+	// The same files give the same digest
+	if second := publish(); second != first {
+		t.Fatalf("want the same digest when publishing again, got:\n%s\n%s", first, second)
+	}
+
+	var templates []TemplateInfo
+	storeData, err := os.ReadFile(storeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(storeData, &templates); err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].TemplateName != "ruby" || templates[0].Repository != "oci://"+ref || templates[0].Source != host+"/templates" {
+		t.Fatalf("want ruby listed once in the store file, got %+v", templates)
+	}
+
+	infos, err := getTemplateInfo("file://" + storeFile)
+	if err != nil || len(infos) != 1 || infos[0].Language != "ruby" {
+		t.Fatalf("want the store file to be read, got %+v %v", infos, err)
+	}
+
+	if err := pullTemplate("oci://"+ref, "", true, lockIfExists); err != nil {
+		t.Fatalf("pullTemplate: %v", err)
+	}
+	for _, name := range []string{"template.yml", "index.rb", "Dockerfile", "function"} {
+		if _, err := os.Stat(filepath.Join(TemplateDirectory, "ruby", name)); err != nil {
+			t.Fatalf("want %s pulled: %v", name, err)
+		}
+	}
+}
+
+func Test_templatePublish_invalidReference(t *testing.T) {
+	resetForTest()
+	faasCmd.SetArgs([]string{"template", "publish", filepath.Join("testdata", "templates", "template", "ruby"), "registry/templates/ruby:1.0"})
+
+	err := faasCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "must start with oci://") {
+		t.Fatalf("want an error for a reference without oci://, got: %v", err)
+	}
+}
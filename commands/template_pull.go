@@ -25,15 +25,22 @@ func init() {
 // templatePullCmd allows the user to fetch a template from a repository
 var templatePullCmd = &cobra.Command{
 	Use:   `pull [REPOSITORY_URL]`,
-	Short: `Downloads templates from the specified git repo`,
+	Short: `Downloads templates from the specified git repo, image or tarball`,
 	Long: `Downloads templates from the specified git repo specified by [REPOSITORY_URL], and copies the 'template'
 directory from the root of the repo, if it exists.
 
 [REPOSITORY_URL] may specify a specific branch or tag to copy by adding a URL fragment with the branch or tag name.
+
+Templates can also be pulled without git from an OCI image with oci://, or from a tarball with file://,
+http:// or https://. Add #sha256=<hex> to a tarball to verify its checksum, and pin an image with
+@sha256:<digest>. The same sources can be given in the configuration.templates section of stack.yaml.
 	`,
 	Example: `
   faas-cli template pull https://github.com/openfaas/templates
   faas-cli template pull https://github.com/openfaas/templates#1.0
+  faas-cli template pull oci://ghcr.io/openfaas/templates/python3:1.2
+  faas-cli template pull https://example.com/templates.tar.gz#sha256=<hex>
+  faas-cli template pull file:///mnt/templates.tar.gz
 `,
 	RunE:          runTemplatePull,
 	SilenceErrors: true,
@@ -54,7 +61,7 @@ func templatePull(repository string, overwriteTemplates bool) error {
 
 	repository = getTemplateURL(repository, os.Getenv(templateURLEnvironment), DefaultTemplateRepository)
 
-	return pullTemplate(repository, templateName, overwriteTemplates)
+	return pullTemplate(repository, templateName, overwriteTemplates, lockRecord)
 }
 
 func pullDebugPrint(message string) {
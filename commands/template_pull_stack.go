@@ -55,7 +55,7 @@ func loadTemplateConfig() ([]stack.TemplateSource, error) {
 func readStackConfig() (stack.Configuration, error) {
 	configField := stack.Configuration{}
 
-	configFieldBytes, err := os.ReadFile(yamlFile)
+	configFieldBytes, err := readMergedStack(yamlFile)
 	if err != nil {
 		return configField, fmt.Errorf("can't read file %s, error: %s", yamlFile, err.Error())
 	}
@@ -73,7 +73,7 @@ func pullConfigTemplates(templateSources []stack.TemplateSource) error {
 	for _, config := range templateSources {
 		fmt.Printf("Pulling template: %s from %s\n", config.Name, config.Source)
 
-		if err := pullTemplate(config.Source, config.Name, overwrite); err != nil {
+		if err := pullTemplate(config.Source, config.Name, overwrite, lockRecord); err != nil {
 			return err
 		}
 	}
@@ -102,7 +102,7 @@ func pullStackTemplates(missingTemplates []string, templateSources []stack.Templ
 			fmt.Printf("Pulling template: %s from %s\n", val, templateConfig.Source)
 
 			templateName := templateConfig.Name
-			if err := pullTemplate(templateConfig.Source, templateName, overwrite); err != nil {
+			if err := pullTemplate(templateConfig.Source, templateName, overwrite, lockIfExists); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,274 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alexellis/arkade/pkg/archive"
+	"github.com/google/go-containerregistry/pkg/crane"
+)
+
+// Templates can be pulled from an OCI registry or a tarball as well as from
+// git, so that no git server is needed in an air-gapped environment.
+const (
+	ociTemplateScheme  = "oci://"
+	fileTemplateScheme = "file://"
+
+	// checksumFragment gives the SHA256 of a tarball, i.e.
+	// https://example.com/templates.tar.gz#sha256=<hex>
+	checksumFragment = "sha256="
+)
+
+var sha256Hex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// templateArchiveClient downloads tarballs of templates.
+var templateArchiveClient = &http.Client{Timeout: commandTimeout}
+
+// isArchiveTemplateSource returns true for templates which are not pulled
+// with git: oci:// images, file:// tarballs and http(s) URLs of tarballs.
+func isArchiveTemplateSource(source string) bool {
+	if strings.HasPrefix(source, ociTemplateScheme) || strings.HasPrefix(source, fileTemplateScheme) {
+		return true
+	}
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		source, _, _ = strings.Cut(source, "#")
+		u, err := url.Parse(source)
+		if err != nil {
+			return false
+		}
+		return isTarballName(u.Path)
+	}
+
+	return false
+}
+
+func isTarballName(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".tar")
+}
+
+// splitTemplateChecksum removes the #sha256=<hex> fragment from a tarball
+// source and returns the checksum, which is empty when none is given.
+func splitTemplateChecksum(source string) (string, string, error) {
+	base, fragment, found := strings.Cut(source, "#")
+	if !found {
+		return source, "", nil
+	}
+
+	checksum, ok := strings.CutPrefix(fragment, checksumFragment)
+	if !ok || !sha256Hex.MatchString(checksum) {
+		return "", "", fmt.Errorf("invalid checksum in %s, give #%s followed by 64 hex characters", source, checksumFragment)
+	}
+	return base, strings.ToLower(checksum), nil
+}
+
+// fetchTemplateArchive pulls the templates from an OCI image or tarball,
+// then writes them to ./template in the same way as a git clone. The digest
+// of the image or tarball is recorded in place of a commit.
+func fetchTemplateArchive(source, templateName string, overwriteTemplates bool, lockMode templateLockMode) error {
+	source, checksum, err := splitTemplateChecksum(source)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(source, ociTemplateScheme) && len(checksum) > 0 {
+		return fmt.Errorf("pin an OCI image with @sha256:<digest> rather than a checksum: %s", source)
+	}
+
+	log.Printf("Fetching templates from %s", source)
+
+	extractedPath, err := os.MkdirTemp("", "openfaas-templates-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary directory: %s", err)
+	}
+
+	if !pullDebug {
+		defer os.RemoveAll(extractedPath)
+	}
+
+	pullDebugPrint(fmt.Sprintf("Temp files in %s", extractedPath))
+
+	contents := filepath.Join(extractedPath, "archive")
+
+	var digest string
+	if strings.HasPrefix(source, ociTemplateScheme) {
+		digest, err = pullTemplateImage(strings.TrimPrefix(source, ociTemplateScheme), contents)
+	} else {
+		digest, err = pullTemplateTarball(source, checksum, contents)
+	}
+	if err != nil {
+		return err
+	}
+
+	root, err := templateArchiveRoot(extractedPath, contents, source, templateName)
+	if err != nil {
+		return err
+	}
+
+	return writeFetchedTemplates(root, source, "", digest, templateName, overwriteTemplates, lockMode)
+}
+
+// pullTemplateImage extracts the filesystem of an image into dir, using the
+// credentials from the docker config. The image's digest is returned.
+func pullTemplateImage(image, dir string, options ...crane.Option) (string, error) {
+	img, err := crane.Pull(image, options...)
+	if err != nil {
+		return "", fmt.Errorf("pulling %s: %w", image, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the digest of %s: %w", image, err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(crane.Export(img, pw))
+	}()
+	defer pr.Close()
+
+	if err := archive.UntarNested(pr, dir, false, true); err != nil {
+		return "", fmt.Errorf("exporting %s: %w", image, err)
+	}
+
+	return digest.String(), nil
+}
+
+// pullTemplateTarball downloads a file:// or http(s) tarball and extracts it
+// into dir, after checking its checksum when one is given. The SHA256 of
+// the tarball is returned.
+func pullTemplateTarball(source, checksum, dir string) (string, error) {
+	data, err := readTemplateTarball(source)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if len(checksum) > 0 && checksum != digest {
+		return "", fmt.Errorf("checksum mismatch for %s: want sha256 %s, got %s", source, checksum, digest)
+	}
+
+	// Compressed tarballs are detected by their content, not the name
+	gzipped := len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+	if err := archive.UntarNested(bytes.NewReader(data), dir, gzipped, true); err != nil {
+		return "", fmt.Errorf("unable to extract %s: %w", source, err)
+	}
+
+	return "sha256:" + digest, nil
+}
+
+func readTemplateTarball(source string) ([]byte, error) {
+	if strings.HasPrefix(source, fileTemplateScheme) {
+		u, err := url.Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file URL %s: %w", source, err)
+		}
+
+		data, err := os.ReadFile(filepath.FromSlash(u.Host + u.Path))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	res, err := templateArchiveClient.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download %s: %w", source, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download %s: unexpected status code %d", source, res.StatusCode)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download %s: %w", source, err)
+	}
+	return data, nil
+}
+
+// templateArchiveRoot returns the directory containing the template folder
+// of an archive extracted to contents. A tarball of a repository, such as
+// the one GitHub makes for a release, has a single top-level folder. An
+// archive of a single template has its template.yml at the root, and is
+// moved to template/<name> within extractedPath.
+func templateArchiveRoot(extractedPath, contents, source, templateName string) (string, error) {
+	if _, err := os.Stat(filepath.Join(contents, TemplateDirectory)); err == nil {
+		return contents, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(contents, "template.yml")); err == nil {
+		name := strings.SplitN(templateName, "@", 2)[0]
+		if len(name) == 0 {
+			name = archiveTemplateName(source)
+		}
+
+		if err := os.MkdirAll(filepath.Join(extractedPath, TemplateDirectory), 0755); err != nil {
+			return "", err
+		}
+		if err := os.Rename(contents, filepath.Join(extractedPath, TemplateDirectory, name)); err != nil {
+			return "", err
+		}
+		return extractedPath, nil
+	}
+
+	entries, err := os.ReadDir(contents)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		nested := filepath.Join(contents, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(nested, TemplateDirectory)); err == nil {
+			return nested, nil
+		}
+	}
+
+	return "", fmt.Errorf("can't find templates in: %s, the archive needs a template folder or a template.yml", source)
+}
+
+// archiveTemplateName names a single template after its image or tarball,
+// i.e. oci://ghcr.io/openfaas/templates/python3:1.2 is python3.
+func archiveTemplateName(source string) string {
+	name, isImage := strings.CutPrefix(source, ociTemplateScheme)
+	if u, err := url.Parse(source); !isImage && err == nil {
+		name = u.Path
+	}
+
+	name, _, _ = strings.Cut(name, "@")
+	name = path.Base(name)
+	if i := strings.LastIndex(name, ":"); i > -1 {
+		name = name[:i]
+	}
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// archiveTemplateDigest returns the current digest of an image or tarball,
+// for comparing with the digest in the templates.lock.
+func archiveTemplateDigest(source string) (string, error) {
+	if image, ok := strings.CutPrefix(source, ociTemplateScheme); ok {
+		return crane.Digest(image)
+	}
+
+	data, err := readTemplateTarball(source)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,240 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+)
+
+func Test_isArchiveTemplateSource(t *testing.T) {
+	cases := map[string]bool{
+		"oci://ghcr.io/openfaas/templates/python3:1.2":      true,
+		"file:///mnt/templates.tar.gz":                      true,
+		"https://example.com/templates.tar.gz":              true,
+		"https://example.com/templates.tgz#sha256=abc":      true,
+		"http://example.com/templates.tar?download=1":       true,
+		"https://github.com/openfaas/templates":             false,
+		"https://github.com/openfaas/templates.git#1.0":     false,
+		"git@github.com:openfaas/templates.git":             false,
+		"/home/user/templates":                              false,
+		"https://example.com/templates.tar.gz.sig":          false,
+		"https://github.com/openfaas/templates#sha-1234567": false,
+	}
+
+	for source, want := range cases {
+		if got := isArchiveTemplateSource(source); got != want {
+			t.Errorf("%s: want %v, got %v", source, want, got)
+		}
+	}
+}
+
+func Test_splitTemplateChecksum(t *testing.T) {
+	checksum := strings.Repeat("ab", 32)
+
+	source, got, err := splitTemplateChecksum("https://example.com/t.tar.gz#sha256=" + strings.ToUpper(checksum))
+	if err != nil || source != "https://example.com/t.tar.gz" || got != checksum {
+		t.Fatalf("want the source and checksum, got %s %s %v", source, got, err)
+	}
+
+	source, got, err = splitTemplateChecksum("https://example.com/t.tar.gz")
+	if err != nil || source != "https://example.com/t.tar.gz" || len(got) > 0 {
+		t.Fatalf("want no checksum, got %s %s %v", source, got, err)
+	}
+
+	if _, _, err := splitTemplateChecksum("https://example.com/t.tar.gz#md5=abc"); err == nil {
+		t.Fatalf("want an error for an invalid checksum")
+	}
+}
+
+func Test_archiveTemplateName(t *testing.T) {
+	cases := map[string]string{
+		"oci://ghcr.io/openfaas/templates/python3:1.2":    "python3",
+		"oci://localhost:5000/python3":                    "python3",
+		"oci://localhost:5000/node20@sha256:abc":          "node20",
+		"https://example.com/dl/golang-middleware.tar.gz": "golang-middleware",
+		"file:///mnt/ruby.tgz":                            "ruby",
+	}
+
+	for source, want := range cases {
+		if got := archiveTemplateName(source); got != want {
+			t.Errorf("%s: want %s, got %s", source, want, got)
+		}
+	}
+}
+
+// templateTarball returns a gzipped tarball of files.
+func templateTarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buff bytes.Buffer
+	gz := gzip.NewWriter(&buff)
+	tw := tar.NewWriter(gz)
+	for name, contents := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buff.Bytes()
+}
+
+func Test_pullTemplate_tarball(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	release := templateTarball(t, map[string]string{
+		"templates-1.0/template/python3/template.yml": "language: python3\n",
+		"templates-1.0/template/python3/index.py":     "# handler\n",
+		"templates-1.0/template/node20/template.yml":  "language: node20\n",
+	})
+	sum := sha256.Sum256(release)
+	checksum := hex.EncodeToString(sum[:])
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/templates-1.0.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(release)
+	}))
+	defer s.Close()
+
+	source := s.URL + "/templates-1.0.tar.gz"
+
+	if err := pullTemplate(source+"#sha256="+strings.Repeat("0", 64), "", true, lockRecord); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("want a checksum mismatch, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(TemplateDirectory, "python3")); err == nil {
+		t.Fatalf("want no templates written when the checksum does not match")
+	}
+
+	if err := pullTemplate(source+"#sha256="+checksum, "", true, lockRecord); err != nil {
+		t.Fatalf("pullTemplate: %v", err)
+	}
+
+	for _, name := range []string{"python3/template.yml", "python3/index.py", "node20/template.yml"} {
+		if _, err := os.Stat(filepath.Join(TemplateDirectory, name)); err != nil {
+			t.Fatalf("want %s written: %v", name, err)
+		}
+	}
+
+	meta, err := readTemplateMeta(filepath.Join(TemplateDirectory, "python3"))
+	if err != nil || meta.Repository != source || meta.Sha != "sha256:"+checksum {
+		t.Fatalf("want the source and checksum in meta.json, got %+v %v", meta, err)
+	}
+
+	lock, err := loadTemplateLock(TemplateLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked, ok := lock.Get("node20"); !ok || locked.Repository != source || locked.Sha != "sha256:"+checksum {
+		t.Fatalf("want node20 locked to the tarball, got %+v", lock.Templates)
+	}
+
+	if latest, err := latestTemplateSHA(source, ""); err != nil || latest != "sha256:"+checksum {
+		t.Fatalf("want the digest of the tarball, got %s %v", latest, err)
+	}
+}
+
+func Test_pullTemplate_fileTarball(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	path := filepath.Join(t.TempDir(), "ruby.tgz")
+	data := templateTarball(t, map[string]string{
+		"template.yml": "language: ruby\n",
+		"Dockerfile":   "FROM ruby\n",
+	})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pullTemplate("file://"+filepath.ToSlash(path), "", true, lockIfExists); err != nil {
+		t.Fatalf("pullTemplate: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(TemplateDirectory, "ruby", "Dockerfile")); err != nil {
+		t.Fatalf("want a single template named after the tarball: %v", err)
+	}
+	if _, err := os.Stat(TemplateLockFile); err == nil {
+		t.Fatalf("want no %s created when there was none", TemplateLockFile)
+	}
+}
+
+func Test_pullTemplate_oci(t *testing.T) {
+	defer tearDownFetchTemplates(t)
+
+	host := newTestRegistry(t)
+
+	img, err := crane.Image(map[string][]byte{
+		"template/python3/template.yml": []byte("language: python3\n"),
+		"template/python3/index.py":     []byte("# handler\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := host + "/templates/python3:1.2"
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pullTemplate("oci://"+ref, "python3", true, lockRecord); err != nil {
+		t.Fatalf("pullTemplate: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(TemplateDirectory, "python3", "index.py")); err != nil {
+		t.Fatalf("want python3 written: %v", err)
+	}
+
+	lock, err := loadTemplateLock(TemplateLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked, ok := lock.Get("python3"); !ok || locked.Sha != digest.String() {
+		t.Fatalf("want python3 locked to %s, got %+v", digest, lock.Templates)
+	}
+
+	// Pushing the tag again makes the locked template drift
+	moved, err := crane.Image(map[string][]byte{
+		"template/python3/template.yml": []byte("language: python3\nwelcome_message: v2\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(moved, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pullTemplate("oci://"+ref, "python3", true, lockRecord); err == nil || !strings.Contains(err.Error(), "has drifted") {
+		t.Fatalf("want the pull to fail on drift, got: %v", err)
+	}
+
+	pinned := "oci://" + host + "/templates/python3@" + digest.String()
+	statuses := checkTemplateStatus(&templateLock{Templates: []lockedTemplate{{Name: "python3", Repository: pinned, Sha: digest.String()}}}, latestTemplateSHA)
+	if len(statuses) != 1 || statuses[0].status != "pinned" {
+		t.Fatalf("want an image pinned by digest not to be checked, got %+v", statuses)
+	}
+}
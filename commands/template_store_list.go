@@ -118,6 +118,15 @@ func runTemplateStoreList(cmd *cobra.Command, args []string) error {
 }
 
 func getTemplateInfo(repository string) ([]TemplateInfo, error) {
+	// A store file written by "faas-cli template publish --store-file"
+	if storeFile, ok := strings.CutPrefix(repository, fileTemplateScheme); ok {
+		body, err := os.ReadFile(storeFile)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading template list: %s", err.Error())
+		}
+		return parseTemplateInfo(body)
+	}
+
 	req, reqErr := http.NewRequest(http.MethodGet, repository, nil)
 	if reqErr != nil {
 		return nil, fmt.Errorf("error while trying to create request to take template info: %s", reqErr.Error())
@@ -150,6 +159,10 @@ func getTemplateInfo(repository string) ([]TemplateInfo, error) {
 		return nil, fmt.Errorf("error while reading response: %s", err.Error())
 	}
 
+	return parseTemplateInfo(body)
+}
+
+func parseTemplateInfo(body []byte) ([]TemplateInfo, error) {
 	templatesInfo := []TemplateInfo{}
 	if err := json.Unmarshal(body, &templatesInfo); err != nil {
 		return nil, fmt.Errorf("can't unmarshal text: %s, value: %s", err.Error(), string(body))
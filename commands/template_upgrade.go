@@ -0,0 +1,70 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	templateUpgradeCmd.Flags().BoolVar(&pullDebug, "debug", false, "Enable debug output")
+
+	templateCmd.AddCommand(templateUpgradeCmd)
+}
+
+var templateUpgradeCmd = &cobra.Command{
+	Use:   `upgrade [TEMPLATE_NAME]...`,
+	Short: `Pulls the latest commit of templates and updates templates.lock`,
+	Long: `Pulls each template in templates.lock again from the branch or tag it was
+pulled from, overwriting ./template and recording the new commit in the lock.
+Give the names of templates to upgrade only those.`,
+	Example: `  faas-cli template upgrade
+  faas-cli template upgrade golang-middleware
+  faas-cli template upgrade -f stack.yaml node20`,
+	RunE: runTemplateUpgrade,
+}
+
+func runTemplateUpgrade(cmd *cobra.Command, args []string) error {
+	lock, err := loadTemplateLock(templateLockPath())
+	if err != nil {
+		return err
+	}
+	if !lock.exists {
+		return fmt.Errorf("no %s found, run \"faas-cli template pull\" to create it", lock.path)
+	}
+
+	selected := lock.Templates
+	if len(args) > 0 {
+		selected = nil
+		for _, name := range args {
+			t, ok := lock.Get(name)
+			if !ok {
+				return fmt.Errorf("template %s is not in %s", name, lock.path)
+			}
+			selected = append(selected, t)
+		}
+	}
+
+	for _, t := range selected {
+		fmt.Printf("Upgrading template: %s from %s\n", t.Name, templateSource(t))
+
+		if err := fetchTemplates(t.Repository, t.RefName, t.Name, true, lockUpgrade); err != nil {
+			return fmt.Errorf("error upgrading %s: %w", t.Name, err)
+		}
+
+		upgraded, err := loadTemplateLock(lock.path)
+		if err != nil {
+			return err
+		}
+		if u, ok := upgraded.Get(t.Name); ok && u.Sha != t.Sha {
+			fmt.Printf("%s: %s => %s\n", t.Name, shortSHA(t.Sha), shortSHA(u.Sha))
+		} else {
+			fmt.Printf("%s: already at %s\n", t.Name, shortSHA(t.Sha))
+		}
+	}
+
+	return nil
+}
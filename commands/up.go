@@ -35,6 +35,7 @@ func init() {
 	upFlagset.StringVar(&builderPublicKeyPath, "builder-public-key", "", "Builder public key as a literal value, or a path to a file containing raw base64 or the JSON response from /publickey")
 
 	upFlagset.BoolVar(&watch, "watch", false, "Watch for changes in files and re-deploy")
+	upFlagset.DurationVar(&watchDebounce, "watch-debounce", watchDebounce, "Time to wait for changes to settle before rebuilding with --watch")
 	upCmd.Flags().AddFlagSet(upFlagset)
 
 	build, _, _ := faasCmd.Find([]string{"build"})
@@ -110,6 +111,11 @@ func upHandler(cmd *cobra.Command, args []string) error {
 }
 
 func upRunner(cmd *cobra.Command, args []string) error {
+	// --explain only reports on what would be rebuilt
+	if buildExplain {
+		return runBuild(cmd, args)
+	}
+
 	if usePublish {
 		if err := runPublish(cmd, args); err != nil {
 			return err
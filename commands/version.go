@@ -145,7 +145,7 @@ func getVersionGatewayInfo() (versionGatewayInfo, error) {
 	var services stack.Services
 	var yamlGateway string
 	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
+		parsedServices, err := parseYAMLFile(yamlFile, regex, filter, envsubst)
 		if err == nil && parsedServices != nil {
 			services = *parsedServices
 			yamlGateway = services.Provider.GatewayURL
@@ -156,7 +156,7 @@ func getVersionGatewayInfo() (versionGatewayInfo, error) {
 	info.GatewayAddress = gatewayAddress
 
 	versionTimeout := 5 * time.Second
-	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress)
+	cliAuth, err := proxy.NewCLIAuth(token, gatewayAddress, tlsInsecure)
 	if err != nil {
 		return info, err
 	}
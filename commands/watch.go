@@ -2,43 +2,243 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/bep/debounce"
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/openfaas/faas-cli/util"
 	"github.com/openfaas/go-sdk/stack"
 	"github.com/spf13/cobra"
 )
 
-// watchLoop will watch for changes to function handler files and the stack.yaml
-// then call onChange when a change is detected
-func watchLoop(cmd *cobra.Command, args []string, onChange func(cmd *cobra.Command, args []string, ctx context.Context) error) error {
+// watchDebounce is how long to wait for changes to settle before rebuilding
+var watchDebounce = 1500 * time.Millisecond
 
-	var services stack.Services
-	if len(yamlFile) > 0 {
-		parsedServices, err := stack.ParseYAMLFile(yamlFile, regex, filter, envsubst)
-		if err != nil {
-			return err
+// watchTargets maps each watched path to the functions which are affected
+// when something under it changes.
+type watchTargets map[string][]string
+
+// watchStackPaths returns the paths of the stack file and of any overlays
+// given by repeating -f, a change to any of them reloads every function.
+func watchStackPaths(cwd string) []string {
+	stackFiles := append([]string{yamlFile}, yamlOverlays...)
+
+	stackPaths := make([]string, 0, len(stackFiles))
+	for _, stackFile := range stackFiles {
+		if filepath.IsAbs(stackFile) {
+			stackPaths = append(stackPaths, stackFile)
+		} else {
+			stackPaths = append(stackPaths, path.Join(cwd, stackFile))
+		}
+	}
+	return stackPaths
+}
+
+// buildWatchTargets maps each function's handler, its template in
+// ./template/<lang>, and the shared copy paths to the functions built from
+// them.
+func buildWatchTargets(services *stack.Services, cwd string) watchTargets {
+	targets := make(watchTargets)
+
+	add := func(p, name string) {
+		for _, existing := range targets[p] {
+			if existing == name {
+				return
+			}
+		}
+		targets[p] = append(targets[p], name)
+	}
+
+	extraPaths := util.MergeSlice(services.StackConfiguration.CopyExtraPaths, copyExtra)
+
+	for name, function := range services.Functions {
+		if len(function.Handler) > 0 {
+			add(path.Join(cwd, function.Handler), name)
+		}
+
+		if len(function.Language) > 0 && function.Language != "dockerfile" {
+			add(path.Join(cwd, TemplateDirectory, function.Language), name)
+		}
+
+		for _, extra := range extraPaths {
+			add(path.Join(cwd, extra), name)
+		}
+	}
+
+	for p := range targets {
+		sort.Strings(targets[p])
+	}
+
+	return targets
+}
+
+// affected returns the functions affected by a change to changedPath.
+func (w watchTargets) affected(changedPath string) []string {
+	set := map[string]bool{}
+	for root, names := range w {
+		if changedPath == root || strings.HasPrefix(changedPath, root+"/") {
+			for _, name := range names {
+				set[name] = true
+			}
+		}
+	}
+
+	affected := make([]string, 0, len(set))
+	for name := range set {
+		affected = append(affected, name)
+	}
+	sort.Strings(affected)
+	return affected
+}
+
+// watchSelection collects the functions affected by changes within one
+// debounce window.
+type watchSelection struct {
+	mu    sync.Mutex
+	all   bool
+	names map[string]bool
+}
+
+func (s *watchSelection) add(names []string, all bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if all {
+		s.all = true
+	}
+	if s.names == nil {
+		s.names = map[string]bool{}
+	}
+	for _, name := range names {
+		s.names[name] = true
+	}
+}
+
+// take returns the selection and starts a new one.
+func (s *watchSelection) take() ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := s.all
+	names := make([]string, 0, len(s.names))
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.all = false
+	s.names = nil
+	return names, all
+}
+
+// watchChange holds the functions to rebuild after a change, it is given to
+// onChange in its context so that a build which is still being cancelled
+// does not see the functions selected for the next one.
+type watchChange struct {
+	names []string
+	all   bool
+}
+
+type watchChangeKey struct{}
+
+func withWatchChange(ctx context.Context, change watchChange) context.Context {
+	return context.WithValue(ctx, watchChangeKey{}, change)
+}
+
+// watchChangeFrom returns the change being rebuilt, which selects every
+// function outside of --watch.
+func watchChangeFrom(ctx context.Context) watchChange {
+	if ctx != nil {
+		if change, ok := ctx.Value(watchChangeKey{}).(watchChange); ok {
+			return change
+		}
+	}
+	return watchChange{all: true}
+}
+
+// selected is true when the function is being rebuilt after the change.
+func (c watchChange) selected(name string) bool {
+	if c.all || len(c.names) == 0 {
+		return true
+	}
+	for _, selected := range c.names {
+		if selected == name {
+			return true
 		}
+	}
+	return false
+}
+
+// stackFilters returns the --regex and --filter to parse the stack.yaml
+// with, or during --watch a regex for only the functions affected by the
+// change being rebuilt.
+func stackFilters(ctx context.Context) (string, string) {
+	change := watchChangeFrom(ctx)
+	if change.all || len(change.names) == 0 {
+		return regex, filter
+	}
+
+	quoted := make([]string, 0, len(change.names))
+	for _, name := range change.names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	return "^(" + strings.Join(quoted, "|") + ")$", ""
+}
 
-		if parsedServices != nil {
-			services = *parsedServices
+// watchLoop will watch for changes to function handler files, their
+// templates and copy paths, and the stack.yaml, then call onChange with
+// only the affected functions selected when a change is detected. A build
+// which is still running when a newer change arrives is cancelled, and its
+// functions are built again with the newer change. The loop exits when the
+// command's context is done.
+func watchLoop(cmd *cobra.Command, args []string, onChange func(cmd *cobra.Command, args []string, ctx context.Context) error) error {
+	userRegex, userFilter := regex, filter
+
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	loadServices := func() (stack.Services, error) {
+		var services stack.Services
+		if len(yamlFile) > 0 {
+			parsedServices, err := parseYAMLFile(yamlFile, userRegex, userFilter, envsubst)
+			if err != nil {
+				return services, err
+			}
+
+			if parsedServices != nil {
+				services = *parsedServices
+			}
 		}
+		return services, nil
+	}
+
+	services, err := loadServices()
+	if err != nil {
+		return err
 	}
 
 	fnNames := []string{}
 	for name := range services.Functions {
 		fnNames = append(fnNames, name)
 	}
+	sort.Strings(fnNames)
 
 	fmt.Printf("[Watch] monitoring %d functions: %s\n", len(fnNames), strings.Join(fnNames, ", "))
 
@@ -61,49 +261,105 @@ func watchLoop(cmd *cobra.Command, args []string, onChange func(cmd *cobra.Comma
 	if err != nil {
 		return err
 	}
-	yamlPath := path.Join(cwd, yamlFile)
+	stackPaths := watchStackPaths(cwd)
 
 	debug := os.Getenv("FAAS_DEBUG")
 
-	if debug == "1" {
-		fmt.Printf("[Watch] added: %s\n", yamlPath)
-	}
+	for _, stackPath := range stackPaths {
+		if debug == "1" {
+			fmt.Printf("[Watch] added: %s\n", stackPath)
+		}
 
-	watcher.Add(yamlPath)
+		watcher.Add(stackPath)
+	}
 
-	// map to determine which function belongs to changed files
+	// map to determine which functions are affected by changed files
 	// when responding to events
-	handlerMap := make(map[string]string)
+	var targetsMu sync.Mutex
+	targets := buildWatchTargets(&services, cwd)
+	watched := map[string]bool{}
+
+	// watchTargetPaths starts watching any paths which exist now, such as
+	// a template which was pulled by the first build
+	watchTargetPaths := func() error {
+		targetsMu.Lock()
+		defer targetsMu.Unlock()
+
+		for p := range targets {
+			if watched[p] {
+				continue
+			}
 
-	for serviceName, service := range services.Functions {
-		handlerMap[serviceName] = path.Join(cwd, service.Handler)
+			if _, err := os.Stat(p); err != nil {
+				continue
+			}
 
-		handlerFullPath := path.Join(cwd, service.Handler)
+			if err := addPath(watcher, p); err != nil {
+				return err
+			}
+			watched[p] = true
+		}
+		return nil
+	}
 
-		if err := addPath(watcher, handlerFullPath); err != nil {
-			return err
+	for serviceName, service := range services.Functions {
+		handlerFullPath := path.Join(cwd, service.Handler)
+		if _, err := os.Stat(handlerFullPath); err != nil {
+			return fmt.Errorf("unable to watch %s for %s: %s", handlerFullPath, serviceName, err)
 		}
 	}
 
+	if err := watchTargetPaths(); err != nil {
+		return err
+	}
+
 	signalChannel := make(chan os.Signal, 1)
 
 	// Exit on Ctrl+C or kill
 	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signalChannel)
+
+	bounce := debounce.New(watchDebounce)
+	selection := &watchSelection{}
+
+	// Only one onChange runs at a time, the next waits for a cancelled one
+	// to return
+	var runMu sync.Mutex
+	run := func(ctx context.Context) {
+		runMu.Lock()
+		defer runMu.Unlock()
+
+		// A newer change arrived while waiting, it will build these
+		// functions as well
+		if ctx.Err() != nil {
+			return
+		}
 
-	bounce := debounce.New(1500 * time.Millisecond)
-
-	go func() {
-		// An initial build is usually done on first load with
-		// live reloaders
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		canceller.Set(ctx, cancel)
+		names, all := selection.take()
+		ctx = withWatchChange(ctx, watchChange{names: names, all: all})
+		cmd.SetContext(ctx)
 
 		if err := onChange(cmd, args, ctx); err != nil {
+			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+				log.Printf("[Watch] Build cancelled for a newer change")
+				selection.add(names, all)
+				return
+			}
+
 			fmt.Println("Error rebuilding: ", err)
 			os.Exit(1)
 		}
-	}()
+
+		if err := watchTargetPaths(); err != nil {
+			log.Printf("[Watch] %s", err)
+		}
+	}
+
+	// An initial build is usually done on first load with live reloaders
+	selection.add(nil, true)
+	ctx, cancel := context.WithCancel(parent)
+	canceller.Set(ctx, cancel)
+	go run(ctx)
 
 	log.Printf("[Watch] Started")
 	for {
@@ -122,69 +378,71 @@ func watchLoop(cmd *cobra.Command, args []string, onChange func(cmd *cobra.Comma
 
 			if event.Op == fsnotify.Write || event.Op == fsnotify.Create || event.Op == fsnotify.Remove || event.Op == fsnotify.Rename {
 
-				info, err := os.Stat(event.Name)
-				if err != nil {
+				// Removed and renamed files can no longer be found
+				isDir := false
+				if info, err := os.Stat(event.Name); err == nil {
+					isDir = info.IsDir()
+				} else if event.Op == fsnotify.Write || event.Op == fsnotify.Create {
 					continue
 				}
+
 				ignore := false
-				if matcher.Match(strings.Split(event.Name, "/"), info.IsDir()) {
+				if matcher.Match(strings.Split(event.Name, "/"), isDir) {
 					ignore = true
 				}
 
-				// exact match first
-				target := ""
-				for fnName, fnPath := range handlerMap {
-					if event.Name == fnPath {
-						target = fnName
-					}
-				}
-
-				// fuzzy match after, if none matched exactly
-				if target == "" {
-					for fnName, fnPath := range handlerMap {
+				all := slices.Contains(stackPaths, event.Name)
 
-						if strings.HasPrefix(event.Name, fnPath) {
-							target = fnName
-						}
-					}
-				}
+				targetsMu.Lock()
+				affected := targets.affected(event.Name)
+				targetsMu.Unlock()
 
 				// New sub-directory added for a function, start tracking it
-				if event.Op == fsnotify.Create && info.IsDir() && target != "" {
+				if event.Op == fsnotify.Create && isDir && len(affected) > 0 {
 					if err := addPath(watcher, event.Name); err != nil {
 						return err
 					}
 				}
 
-				if !ignore {
-					if target == "" {
-						fmt.Printf("[Watch] Rebuilding %d functions reason: %s to %s\n", len(fnNames), strings.ToLower(event.Op.String()), event.Name)
-					} else {
-						fmt.Printf("[Watch] Reloading %s reason: %s %s\n", target, strings.ToLower(event.Op.String()), event.Name)
+				if ignore || (!all && len(affected) == 0) {
+					continue
+				}
+
+				if all {
+					fmt.Printf("[Watch] Rebuilding %d functions reason: %s to %s\n", len(fnNames), strings.ToLower(event.Op.String()), event.Name)
+
+					// The functions, handlers or copy paths may have changed
+					if reloaded, err := loadServices(); err == nil {
+						targetsMu.Lock()
+						targets = buildWatchTargets(&reloaded, cwd)
+						targetsMu.Unlock()
 					}
+				} else {
+					fmt.Printf("[Watch] Reloading %s reason: %s %s\n", strings.Join(affected, ", "), strings.ToLower(event.Op.String()), event.Name)
+				}
 
-					bounce(func() {
-						log.Printf("[Watch] Cancelling")
+				selection.add(affected, all)
 
-						canceller.Cancel()
+				bounce(func() {
+					log.Printf("[Watch] Cancelling")
 
-						log.Printf("[Watch] Cancelled")
-						ctx, cancel := context.WithCancel(context.Background())
-						canceller.Set(ctx, cancel)
+					// Cancels the docker build of the previous change,
+					// if it is still running
+					canceller.Cancel()
 
-						// Assign --filter to "" for all functions if we can't determine the
-						// changed function to direct the calls to build/push/deploy
-						filter = target
+					log.Printf("[Watch] Cancelled")
+					ctx, cancel := context.WithCancel(parent)
+					canceller.Set(ctx, cancel)
 
-						go func() {
-							if err := onChange(cmd, args, ctx); err != nil {
-								fmt.Println("Error rebuilding: ", err)
-								os.Exit(1)
-							}
-						}()
-					})
-				}
+					if err := watchTargetPaths(); err != nil {
+						log.Printf("[Watch] %s", err)
+					}
 
+					// Only the affected functions are selected for build,
+					// push and deploy, or all of them when the stack.yaml
+					// changed
+					go run(ctx)
+				})
 			}
 
 		case err, ok := <-watcher.Errors:
@@ -196,15 +454,30 @@ func watchLoop(cmd *cobra.Command, args []string, onChange func(cmd *cobra.Comma
 		case <-signalChannel:
 			watcher.Close()
 			return nil
+
+		case <-parent.Done():
+			canceller.Cancel()
+			return nil
 		}
 	}
-
-	return nil
 }
 
 func addPath(watcher *fsnotify.Watcher, rootPath string) error {
 	debug := os.Getenv("FAAS_DEBUG")
 
+	info, err := os.Stat(rootPath)
+	if err != nil {
+		return err
+	}
+
+	// A single file given as a copy path
+	if !info.IsDir() {
+		if err := watcher.Add(rootPath); err != nil {
+			return fmt.Errorf("unable to watch %s: %s", rootPath, err)
+		}
+		return nil
+	}
+
 	return filepath.WalkDir(rootPath, func(subPath string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -228,16 +501,23 @@ func addPath(watcher *fsnotify.Watcher, rootPath string) error {
 // Cancel is a struct to hold a reference to a context and
 // cancellation function between closures
 type Cancel struct {
+	mu     sync.Mutex
 	cancel context.CancelFunc
 	ctx    context.Context
 }
 
 func (c *Cancel) Set(ctx context.Context, cancel context.CancelFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.cancel = cancel
 	c.ctx = ctx
 }
 
 func (c *Cancel) Cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.cancel != nil {
 		c.cancel()
 	}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/go-sdk/stack"
+	"github.com/spf13/cobra"
+)
+
+func Test_buildWatchTargets(t *testing.T) {
+	defer func() { copyExtra = []string{} }()
+	copyExtra = []string{"extra"}
+
+	services := &stack.Services{
+		StackConfiguration: stack.StackConfiguration{
+			CopyExtraPaths: []string{"common"},
+		},
+		Functions: map[string]stack.Function{
+			"api":    {Handler: "./api", Language: "golang-middleware"},
+			"worker": {Handler: "./worker", Language: "golang-middleware"},
+			"web":    {Handler: "./web", Language: "node20"},
+			"custom": {Handler: "./custom", Language: "dockerfile"},
+		},
+	}
+
+	targets := buildWatchTargets(services, "/src")
+
+	cases := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "handler file", path: "/src/api/handler.go", want: []string{"api"}},
+		{name: "handler folder", path: "/src/worker", want: []string{"worker"}},
+		{name: "handler name prefix of another folder", path: "/src/api2/main.go", want: []string{}},
+		{name: "shared template", path: "/src/template/golang-middleware/main.go", want: []string{"api", "worker"}},
+		{name: "template of one function", path: "/src/template/node20/index.js", want: []string{"web"}},
+		{name: "stack copy path", path: "/src/common/util.go", want: []string{"api", "custom", "web", "worker"}},
+		{name: "--copy-extra path", path: "/src/extra/x", want: []string{"api", "custom", "web", "worker"}},
+		{name: "dockerfile has no template", path: "/src/template/dockerfile/Dockerfile", want: []string{}},
+		{name: "unrelated file", path: "/src/README.md", want: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := targets.affected(tc.path)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func Test_watchSelection(t *testing.T) {
+	s := &watchSelection{}
+	s.add([]string{"b", "a"}, false)
+	s.add([]string{"a", "c"}, false)
+
+	names, all := s.take()
+	if all || !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Fatalf("want [a b c] and not all, got %v %t", names, all)
+	}
+
+	s.add(nil, true)
+	if _, all := s.take(); !all {
+		t.Fatalf("want all after a change to the stack file")
+	}
+
+	if names, all := s.take(); all || len(names) != 0 {
+		t.Fatalf("want an empty selection after take, got %v %t", names, all)
+	}
+}
+
+func Test_stackFilters(t *testing.T) {
+	defer func() {
+		regex = ""
+		filter = ""
+	}()
+	filter = "web*"
+
+	ctx := withWatchChange(context.Background(), watchChange{names: []string{"api", "my.fn"}})
+	if gotRegex, gotFilter := stackFilters(ctx); gotRegex != `^(api|my\.fn)$` || gotFilter != "" {
+		t.Fatalf("want regex for the selected functions, got regex=%q filter=%q", gotRegex, gotFilter)
+	}
+	if change := watchChangeFrom(ctx); !change.selected("api") || change.selected("web") {
+		t.Fatalf("want only the selected functions, got %v", change.names)
+	}
+
+	ctx = withWatchChange(context.Background(), watchChange{all: true})
+	if gotRegex, gotFilter := stackFilters(ctx); gotRegex != "" || gotFilter != "web*" {
+		t.Fatalf("want the user's --filter, got regex=%q filter=%q", gotRegex, gotFilter)
+	}
+	if gotRegex, gotFilter := stackFilters(nil); gotRegex != "" || gotFilter != "web*" {
+		t.Fatalf("want the user's --filter outside of --watch, got regex=%q filter=%q", gotRegex, gotFilter)
+	}
+	if !watchChangeFrom(context.Background()).selected("web") {
+		t.Fatalf("want every function selected outside of --watch")
+	}
+}
+
+func Test_watchLoop_cancelledBuild(t *testing.T) {
+	defer func(y string, d time.Duration) {
+		yamlFile = y
+		watchDebounce = d
+	}(yamlFile, watchDebounce)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	files := map[string]string{
+		"stack.yaml":     "version: 1.0\nprovider:\n  name: openfaas\nfunctions:\n  api:\n    lang: dockerfile\n    handler: ./api\n    image: api:latest\n",
+		".gitignore":     "",
+		"api/Dockerfile": "FROM scratch\n",
+	}
+	for name, contents := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	yamlFile = "stack.yaml"
+	watchDebounce = 50 * time.Millisecond
+
+	started := make(chan watchChange, 10)
+	var calls atomic.Int32
+	onChange := func(cmd *cobra.Command, args []string, ctx context.Context) error {
+		started <- watchChangeFrom(ctx)
+
+		// The second build is still running when the next change arrives
+		if calls.Add(1) == 2 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(parent)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- watchLoop(cmd, nil, onChange)
+	}()
+
+	next := func() watchChange {
+		t.Helper()
+		select {
+		case change := <-started:
+			return change
+		case err := <-done:
+			t.Fatalf("want the watch loop to keep running, got: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for a build")
+		}
+		return watchChange{}
+	}
+
+	if change := next(); !change.all {
+		t.Fatalf("want every function built first, got %v", change.names)
+	}
+
+	handler := filepath.Join("api", "handler.go")
+	if err := os.WriteFile(handler, []byte("package function\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if change := next(); !change.selected("api") {
+		t.Fatalf("want api rebuilt, got %v", change.names)
+	}
+
+	if err := os.WriteFile(handler, []byte("package function\n\n// v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if change := next(); change.all || !reflect.DeepEqual(change.names, []string{"api"}) {
+		t.Fatalf("want api rebuilt after the cancelled build, got %v %t", change.names, change.all)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watchLoop: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the watch loop to exit")
+	}
+}
+
+func Test_watchLoop_overlayChange(t *testing.T) {
+	defer func(y string, o []string, d time.Duration) {
+		yamlFile = y
+		yamlOverlays = o
+		watchDebounce = d
+	}(yamlFile, yamlOverlays, watchDebounce)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	files := map[string]string{
+		"stack.yaml":     "version: 1.0\nprovider:\n  name: openfaas\nfunctions:\n  api:\n    lang: dockerfile\n    handler: ./api\n    image: api:latest\n",
+		"staging.yml":    "functions:\n  api:\n    environment:\n      stage: staging\n",
+		".gitignore":     "",
+		"api/Dockerfile": "FROM scratch\n",
+	}
+	for name, contents := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	yamlFile = "stack.yaml"
+	yamlOverlays = []string{"staging.yml"}
+	watchDebounce = 50 * time.Millisecond
+
+	if want, got := []string{filepath.Join(dir, "stack.yaml"), filepath.Join(dir, "staging.yml")}, watchStackPaths(dir); !reflect.DeepEqual(want, got) {
+		t.Fatalf("want %v watched, got %v", want, got)
+	}
+
+	started := make(chan watchChange, 10)
+	onChange := func(cmd *cobra.Command, args []string, ctx context.Context) error {
+		started <- watchChangeFrom(ctx)
+		return nil
+	}
+
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(parent)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- watchLoop(cmd, nil, onChange)
+	}()
+
+	next := func() watchChange {
+		t.Helper()
+		select {
+		case change := <-started:
+			return change
+		case err := <-done:
+			t.Fatalf("want the watch loop to keep running, got: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for a build")
+		}
+		return watchChange{}
+	}
+
+	if change := next(); !change.all {
+		t.Fatalf("want every function built first, got %v", change.names)
+	}
+
+	if err := os.WriteFile("staging.yml", []byte("functions:\n  api:\n    environment:\n      stage: prod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if change := next(); !change.all {
+		t.Fatalf("want every function rebuilt when an overlay changes, got %v", change.names)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watchLoop: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the watch loop to exit")
+	}
+}
@@ -27,6 +27,12 @@ const (
 	BasicAuthType = "basic"
 	//Oauth2AuthType oauth2 authentication type
 	Oauth2AuthType = "oauth2"
+	// ClientCredentialsAuthType exchanges a token from the client
+	// credentials grant of an identity provider for a gateway token
+	ClientCredentialsAuthType = "client_credentials"
+	// ServiceAccountAuthType exchanges a Kubernetes projected service
+	// account token for a gateway token
+	ServiceAccountAuthType = "service_account"
 
 	// ConfigLocationEnv is the name of he env variable used
 	// to configure the location of the faas-cli config folder.
@@ -47,8 +53,10 @@ const (
 
 // ConfigFile for OpenFaaS CLI exclusively.
 type ConfigFile struct {
-	AuthConfigs []AuthConfig `yaml:"auths"`
-	FilePath    string       `yaml:"-"`
+	AuthConfigs    []AuthConfig `yaml:"auths"`
+	CurrentContext string       `yaml:"current-context,omitempty"`
+	Contexts       []Context    `yaml:"contexts,omitempty"`
+	FilePath       string       `yaml:"-"`
 }
 
 type AuthConfig struct {
@@ -56,6 +64,11 @@ type AuthConfig struct {
 	Auth    AuthType `yaml:"auth,omitempty"`
 	Token   string   `yaml:"token,omitempty"`
 	Options []Option `yaml:"options,omitempty"`
+
+	// CredsStore is the name of the credential store which holds the Token,
+	// when set the Token and RefreshTokenOption are not written to the
+	// config file.
+	CredsStore string `yaml:"credsStore,omitempty"`
 }
 
 type Option struct {
@@ -63,6 +76,16 @@ type Option struct {
 	Value string `yaml:"value"`
 }
 
+// RefreshTokenOption is the Option holding a refresh token, it is kept in
+// the credential store along with the Token when one is used.
+const RefreshTokenOption = "refresh_token"
+
+// refreshTokenURL is the server URL the refresh token of a gateway is kept
+// under in a credential store.
+func refreshTokenURL(gateway string) string {
+	return strings.TrimRight(gateway, "/") + "/" + RefreshTokenOption
+}
+
 var ErrConfigNotFound = errors.New("config file not found")
 
 type AuthConfigNotFoundError struct {
@@ -201,6 +224,8 @@ func (configFile *ConfigFile) load() error {
 	if len(conf.AuthConfigs) > 0 {
 		configFile.AuthConfigs = conf.AuthConfigs
 	}
+	configFile.CurrentContext = conf.CurrentContext
+	configFile.Contexts = conf.Contexts
 	return nil
 }
 
@@ -257,6 +282,56 @@ func UpdateAuthConfig(authConfig AuthConfig) error {
 		}
 	}
 
+	if index > -1 {
+		previous := cfg.AuthConfigs[index].CredsStore
+		if len(previous) > 0 && previous != authConfig.CredsStore {
+			if err := eraseCredentials(previous, gateway); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(authConfig.CredsStore) > 0 {
+		store, err := NewCredentialStore(authConfig.CredsStore)
+		if err != nil {
+			return err
+		}
+
+		credentials := Credentials{
+			ServerURL: gateway,
+			Username:  string(authConfig.Auth),
+			Secret:    authConfig.Token,
+		}
+		if err := store.Store(credentials); err != nil {
+			return &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+		}
+		authConfig.Token = ""
+
+		refreshToken := ""
+		options := make([]Option, 0, len(authConfig.Options))
+		for _, option := range authConfig.Options {
+			if option.Name == RefreshTokenOption {
+				refreshToken = option.Value
+				continue
+			}
+			options = append(options, option)
+		}
+		authConfig.Options = options
+
+		if len(refreshToken) > 0 {
+			credentials := Credentials{
+				ServerURL: refreshTokenURL(gateway),
+				Username:  RefreshTokenOption,
+				Secret:    refreshToken,
+			}
+			if err := store.Store(credentials); err != nil {
+				return &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+			}
+		} else if err := store.Erase(refreshTokenURL(gateway)); err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+			return &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+		}
+	}
+
 	if index == -1 {
 		cfg.AuthConfigs = append(cfg.AuthConfigs, authConfig)
 	} else {
@@ -295,6 +370,27 @@ func LookupAuthConfig(gateway string) (AuthConfig, error) {
 	for _, v := range cfg.AuthConfigs {
 		if gateway == v.Gateway {
 			authConfig = v
+			if len(authConfig.CredsStore) == 0 {
+				return authConfig, nil
+			}
+
+			store, err := NewCredentialStore(authConfig.CredsStore)
+			if err != nil {
+				return authConfig, &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+			}
+
+			credentials, err := store.Get(gateway)
+			if err != nil {
+				return authConfig, &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+			}
+			authConfig.Token = credentials.Secret
+
+			refresh, err := store.Get(refreshTokenURL(gateway))
+			if err == nil {
+				authConfig.Options = append(authConfig.Options, Option{Name: RefreshTokenOption, Value: refresh.Secret})
+			} else if !errors.Is(err, ErrCredentialsNotFound) {
+				return authConfig, &CredentialStoreError{Store: authConfig.CredsStore, Err: err}
+			}
 			return authConfig, nil
 		}
 	}
@@ -331,6 +427,12 @@ func RemoveAuthConfig(gateway string) error {
 	}
 
 	if index > -1 {
+		if store := cfg.AuthConfigs[index].CredsStore; len(store) > 0 {
+			if err := eraseCredentials(store, gateway); err != nil {
+				return err
+			}
+		}
+
 		cfg.AuthConfigs = removeAuthByIndex(cfg.AuthConfigs, index)
 		if err := cfg.save(); err != nil {
 			return err
@@ -342,6 +444,24 @@ func RemoveAuthConfig(gateway string) error {
 	return nil
 }
 
+// eraseCredentials removes the token and refresh token for a gateway from a
+// credential store, it is not an error if the store has no token for the
+// gateway.
+func eraseCredentials(name, gateway string) error {
+	store, err := NewCredentialStore(name)
+	if err != nil {
+		return err
+	}
+
+	for _, serverURL := range []string{gateway, refreshTokenURL(gateway)} {
+		if err := store.Erase(serverURL); err != nil && !errors.Is(err, ErrCredentialsNotFound) {
+			return &CredentialStoreError{Store: name, Err: err}
+		}
+	}
+
+	return nil
+}
+
 func removeAuthByIndex(s []AuthConfig, index int) []AuthConfig {
 	return append(s[:index], s[index+1:]...)
 }
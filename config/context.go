@@ -0,0 +1,169 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ContextEnv is the name of the env variable used to select a context
+// for a single command, overriding the current context.
+const ContextEnv string = "OPENFAAS_CONTEXT"
+
+// Context is a named set of defaults for a gateway, credentials for the
+// gateway are stored with "faas-cli login" as for any other gateway.
+type Context struct {
+	Name        string `yaml:"name"`
+	Gateway     string `yaml:"gateway"`
+	Namespace   string `yaml:"namespace,omitempty"`
+	TLSInsecure bool   `yaml:"tls-no-verify,omitempty"`
+}
+
+type ContextNotFoundError struct {
+	Name string
+}
+
+func (e *ContextNotFoundError) Error() string {
+	return fmt.Sprintf("no context found named %s", e.Name)
+}
+
+// SetContext creates or updates a context by name
+func SetContext(context Context) error {
+	if len(context.Name) == 0 {
+		return fmt.Errorf("context name is required")
+	}
+
+	if _, err := url.ParseRequestURI(context.Gateway); err != nil || len(context.Gateway) < 1 {
+		return fmt.Errorf("invalid gateway URL")
+	}
+	context.Gateway = strings.TrimRight(context.Gateway, "/")
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	index := cfg.contextIndex(context.Name)
+	if index == -1 {
+		cfg.Contexts = append(cfg.Contexts, context)
+	} else {
+		cfg.Contexts[index] = context
+	}
+
+	return cfg.save()
+}
+
+// UseContext sets the current context
+func UseContext(name string) error {
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	if cfg.contextIndex(name) == -1 {
+		return &ContextNotFoundError{Name: name}
+	}
+
+	cfg.CurrentContext = name
+	return cfg.save()
+}
+
+// RemoveContext deletes a context, and unsets it when it is the current context
+func RemoveContext(name string) error {
+	if !fileExists() {
+		return ErrConfigNotFound
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	index := cfg.contextIndex(name)
+	if index == -1 {
+		return &ContextNotFoundError{Name: name}
+	}
+
+	cfg.Contexts = append(cfg.Contexts[:index], cfg.Contexts[index+1:]...)
+	if cfg.CurrentContext == name {
+		cfg.CurrentContext = ""
+	}
+
+	return cfg.save()
+}
+
+// ListContexts returns all contexts and the name of the current context
+func ListContexts() ([]Context, string, error) {
+	if !fileExists() {
+		return nil, "", nil
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cfg.Contexts, cfg.CurrentContext, nil
+}
+
+// ActiveContext returns the context named by override, the ContextEnv env
+// variable or the current context, in that order. When no context is
+// selected, nil is returned.
+func ActiveContext(override string) (*Context, error) {
+	name := override
+	if len(name) == 0 {
+		name = os.Getenv(ContextEnv)
+	}
+
+	contexts, current, err := ListContexts()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(name) == 0 {
+		name = current
+	}
+
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	for _, c := range contexts {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+
+	return nil, &ContextNotFoundError{Name: name}
+}
+
+func loadConfigFile() (*ConfigFile, error) {
+	configPath, err := EnsureFile()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := New(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.load(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (configFile *ConfigFile) contextIndex(name string) int {
+	for i, c := range configFile.Contexts {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Contexts(t *testing.T) {
+	t.Setenv(ConfigLocationEnv, t.TempDir())
+	t.Setenv(ContextEnv, "")
+
+	if err := SetContext(Context{Name: "prod", Gateway: "https://prod.example.com/", Namespace: "prod-fn"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetContext(Context{Name: "dev", Gateway: "http://127.0.0.1:8080", TLSInsecure: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := ActiveContext("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != nil {
+		t.Fatalf("want no active context before use, got %v", active)
+	}
+
+	if err := UseContext("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err = ActiveContext("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active == nil || active.Name != "prod" || active.Gateway != "https://prod.example.com" || active.Namespace != "prod-fn" {
+		t.Fatalf("unexpected active context: %v", active)
+	}
+
+	active, err = ActiveContext("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.Name != "dev" || !active.TLSInsecure {
+		t.Fatalf("want override to select dev, got: %v", active)
+	}
+
+	t.Setenv(ContextEnv, "dev")
+	active, err = ActiveContext("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.Name != "dev" {
+		t.Fatalf("want %s to select dev, got: %v", ContextEnv, active)
+	}
+	t.Setenv(ContextEnv, "")
+
+	// Auth configs and contexts are kept when either is updated
+	if err := UpdateAuthConfig(AuthConfig{Gateway: "https://prod.example.com", Token: "token", Auth: Oauth2AuthType}); err != nil {
+		t.Fatal(err)
+	}
+
+	contexts, current, err := ListContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contexts) != 2 || current != "prod" {
+		t.Fatalf("want 2 contexts with prod current, got %v and %q", contexts, current)
+	}
+
+	if err := RemoveContext("prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	contexts, current, err = ListContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contexts) != 1 || current != "" {
+		t.Fatalf("want 1 context and no current context, got %v and %q", contexts, current)
+	}
+
+	if _, err := LookupAuthConfig("https://prod.example.com"); err != nil {
+		t.Fatalf("want auth config to be kept, got: %s", err)
+	}
+}
+
+func Test_Contexts_NotFound(t *testing.T) {
+	t.Setenv(ConfigLocationEnv, t.TempDir())
+	t.Setenv(ContextEnv, "")
+
+	if err := SetContext(Context{Name: "dev", Gateway: "http://127.0.0.1:8080"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var notFound *ContextNotFoundError
+	if err := UseContext("missing"); !errors.As(err, &notFound) {
+		t.Errorf("want ContextNotFoundError, got: %v", err)
+	}
+
+	if _, err := ActiveContext("missing"); !errors.As(err, &notFound) {
+		t.Errorf("want ContextNotFoundError, got: %v", err)
+	}
+
+	if err := SetContext(Context{Name: "bad", Gateway: "not a url"}); err == nil {
+		t.Errorf("want an error for an invalid gateway")
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// CredentialStoreEnv is the name of the env variable used to pick the
+	// credential store for "faas-cli login" when --credential-store is not given.
+	CredentialStoreEnv string = "OPENFAAS_CREDENTIAL_STORE"
+
+	// CredentialsPassphraseEnv is the name of the env variable holding the
+	// passphrase for the encrypted file store, which is required so that the
+	// key is never kept next to the encrypted file.
+	CredentialsPassphraseEnv string = "OPENFAAS_CREDENTIALS_PASSPHRASE"
+
+	// FileCredentialStore is the name of the built-in encrypted file store,
+	// any other name is run as the helper binary faas-credential-<name>.
+	FileCredentialStore = "file"
+
+	credentialHelperPrefix = "faas-credential-"
+	credentialsFile        = "credentials.enc"
+
+	// credentialsNotFoundMessage is printed by docker compatible credential
+	// helpers when there is nothing stored for a server URL.
+	credentialsNotFoundMessage = "credentials not found in native keychain"
+
+	pbkdf2Iterations = 100000
+)
+
+// ErrCredentialsNotFound is returned when a credential store has no
+// credentials for a gateway.
+var ErrCredentialsNotFound = errors.New("credentials not found")
+
+// Credentials are the values exchanged with a credential helper, in the same
+// format as docker credential helpers.
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// CredentialStore keeps the token of an AuthConfig outside of config.yml.
+type CredentialStore interface {
+	Get(serverURL string) (Credentials, error)
+	Store(credentials Credentials) error
+	Erase(serverURL string) error
+}
+
+// CredentialStoreError is returned when a credential store could not be read
+// or written.
+type CredentialStoreError struct {
+	Store string
+	Err   error
+}
+
+func (e *CredentialStoreError) Error() string {
+	return fmt.Sprintf("credential store %s: %s", e.Store, e.Err)
+}
+
+func (e *CredentialStoreError) Unwrap() error {
+	return e.Err
+}
+
+// NewCredentialStore returns the store with the given name, either the
+// encrypted file store or an external helper found in the PATH. The file
+// store needs a passphrase in CredentialsPassphraseEnv.
+func NewCredentialStore(name string) (CredentialStore, error) {
+	if name == FileCredentialStore {
+		if len(os.Getenv(CredentialsPassphraseEnv)) == 0 {
+			return nil, fmt.Errorf("set %s to use the %q credential store", CredentialsPassphraseEnv, FileCredentialStore)
+		}
+
+		dir, err := homedir.Expand(ConfigDir())
+		if err != nil {
+			return nil, err
+		}
+
+		return &fileCredentialStore{
+			path: filepath.Join(dir, credentialsFile),
+		}, nil
+	}
+
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid credential store name: %q", name)
+	}
+
+	return &helperCredentialStore{program: credentialHelperPrefix + name}, nil
+}
+
+// helperCredentialStore runs faas-credential-<name> get|store|erase, with
+// the request on stdin and the response on stdout.
+type helperCredentialStore struct {
+	program string
+}
+
+func (h *helperCredentialStore) Get(serverURL string) (Credentials, error) {
+	out, err := h.run("get", strings.NewReader(serverURL))
+	if err != nil {
+		if strings.Contains(err.Error(), credentialsNotFoundMessage) {
+			return Credentials{}, ErrCredentialsNotFound
+		}
+		return Credentials{}, err
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(out, &credentials); err != nil {
+		return Credentials{}, fmt.Errorf("invalid response from %s: %w", h.program, err)
+	}
+
+	return credentials, nil
+}
+
+func (h *helperCredentialStore) Store(credentials Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.run("store", bytes.NewReader(data))
+	return err
+}
+
+func (h *helperCredentialStore) Erase(serverURL string) error {
+	_, err := h.run("erase", strings.NewReader(serverURL))
+	if err != nil && strings.Contains(err.Error(), credentialsNotFoundMessage) {
+		return ErrCredentialsNotFound
+	}
+	return err
+}
+
+func (h *helperCredentialStore) run(action string, stdin io.Reader) ([]byte, error) {
+	cmd := exec.Command(h.program, action)
+	cmd.Stdin = stdin
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		if len(msg) == 0 {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s %s: %s", h.program, action, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// fileCredentialStore keeps all credentials in a single AES-GCM encrypted
+// file in the config directory, for systems without a keychain. The key is
+// derived from the passphrase in CredentialsPassphraseEnv.
+type fileCredentialStore struct {
+	path string
+}
+
+type encryptedCredentials struct {
+	Salt []byte `json:"salt,omitempty"`
+	Data []byte `json:"data"`
+}
+
+func (f *fileCredentialStore) Get(serverURL string) (Credentials, error) {
+	all, err := f.load()
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	credentials, ok := all[serverURL]
+	if !ok {
+		return Credentials{}, ErrCredentialsNotFound
+	}
+
+	return credentials, nil
+}
+
+func (f *fileCredentialStore) Store(credentials Credentials) error {
+	all, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	all[credentials.ServerURL] = credentials
+	return f.save(all)
+}
+
+func (f *fileCredentialStore) Erase(serverURL string) error {
+	all, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := all[serverURL]; !ok {
+		return ErrCredentialsNotFound
+	}
+
+	delete(all, serverURL)
+	return f.save(all)
+}
+
+func (f *fileCredentialStore) load() (map[string]Credentials, error) {
+	all := make(map[string]Credentials)
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, err
+	}
+
+	var encrypted encryptedCredentials
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", f.path, err)
+	}
+
+	key, err := f.key(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decrypt(key, encrypted.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %s, check %s: %w", f.path, CredentialsPassphraseEnv, err)
+	}
+
+	if err := json.Unmarshal(plaintext, &all); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", f.path, err)
+	}
+
+	return all, nil
+}
+
+func (f *fileCredentialStore) save(all map[string]Credentials) error {
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	key, err := f.key(salt)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := encrypt(key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedCredentials{Salt: salt, Data: ciphertext})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), DefaultPermissions); err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.path, data, 0600)
+}
+
+// key derives the encryption key from the passphrase.
+func (f *fileCredentialStore) key(salt []byte) ([]byte, error) {
+	passphrase := os.Getenv(CredentialsPassphraseEnv)
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("%s is encrypted with a passphrase, set %s", f.path, CredentialsPassphraseEnv)
+	}
+
+	return pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+}
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
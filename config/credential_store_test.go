@@ -0,0 +1,349 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_FileCredentialStore_RoundTrip(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(ConfigLocationEnv, configDir)
+	t.Setenv(CredentialsPassphraseEnv, "correct horse")
+
+	store, err := NewCredentialStore(FileCredentialStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := store.Get("http://openfaas.test"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want ErrCredentialsNotFound, got: %v", err)
+	}
+
+	want := Credentials{ServerURL: "http://openfaas.test", Username: "basic", Secret: "s3cr3t"}
+	if err := store.Store(want); err != nil {
+		t.Fatalf("unexpected error storing credentials: %s", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, credentialsFile))
+	if err != nil {
+		t.Fatalf("unable to read credentials file: %s", err)
+	}
+	if strings.Contains(string(data), want.Secret) {
+		t.Fatalf("credentials file contains the secret in plaintext")
+	}
+
+	got, err := store.Get(want.ServerURL)
+	if err != nil {
+		t.Fatalf("unexpected error getting credentials: %s", err)
+	}
+	if got != want {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if err := store.Erase(want.ServerURL); err != nil {
+		t.Fatalf("unexpected error erasing credentials: %s", err)
+	}
+	if _, err := store.Get(want.ServerURL); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want ErrCredentialsNotFound after erase, got: %v", err)
+	}
+}
+
+func Test_FileCredentialStore_Passphrase(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(ConfigLocationEnv, configDir)
+	t.Setenv(CredentialsPassphraseEnv, "correct horse")
+
+	store, err := NewCredentialStore(FileCredentialStore)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := Credentials{ServerURL: "http://openfaas.test", Username: "oauth2", Secret: "token"}
+	if err := store.Store(want); err != nil {
+		t.Fatalf("unexpected error storing credentials: %s", err)
+	}
+
+	got, err := store.Get(want.ServerURL)
+	if err != nil {
+		t.Fatalf("unexpected error getting credentials: %s", err)
+	}
+	if got != want {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	t.Setenv(CredentialsPassphraseEnv, "wrong")
+	if _, err := store.Get(want.ServerURL); err == nil {
+		t.Fatalf("want error with the wrong passphrase")
+	}
+
+	t.Setenv(CredentialsPassphraseEnv, "")
+	if _, err := store.Get(want.ServerURL); err == nil || !strings.Contains(err.Error(), CredentialsPassphraseEnv) {
+		t.Fatalf("want error asking for %s, got: %v", CredentialsPassphraseEnv, err)
+	}
+}
+
+func Test_NewCredentialStore_FileNeedsPassphrase(t *testing.T) {
+	t.Setenv(ConfigLocationEnv, t.TempDir())
+	t.Setenv(CredentialsPassphraseEnv, "")
+
+	if _, err := NewCredentialStore(FileCredentialStore); err == nil || !strings.Contains(err.Error(), CredentialsPassphraseEnv) {
+		t.Fatalf("want an error asking for %s, got: %v", CredentialsPassphraseEnv, err)
+	}
+}
+
+func Test_NewCredentialStore_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "../bin/sh", `a\b`} {
+		if _, err := NewCredentialStore(name); err == nil {
+			t.Errorf("want error for credential store name %q", name)
+		}
+	}
+}
+
+// fakeCredentialHelper installs faas-credential-test in the PATH, which
+// keeps one secret per server URL in files under dir.
+func fakeCredentialHelper(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+
+	binDir := t.TempDir()
+	dataDir := t.TempDir()
+
+	script := `#!/bin/sh
+set -e
+dir="` + dataDir + `"
+case "$1" in
+store)
+	input=$(cat)
+	key=$(echo "$input" | sed 's/.*"ServerURL":"\([^"]*\)".*/\1/' | tr '/:' '__')
+	echo "$input" > "$dir/$key"
+	;;
+get)
+	key=$(cat | tr '/:' '__')
+	if [ ! -f "$dir/$key" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	cat "$dir/$key"
+	;;
+erase)
+	key=$(cat | tr '/:' '__')
+	if [ ! -f "$dir/$key" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	rm "$dir/$key"
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(binDir, credentialHelperPrefix+"test"), []byte(script), 0755); err != nil {
+		t.Fatalf("unable to write fake credential helper: %s", err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dataDir
+}
+
+func Test_HelperCredentialStore(t *testing.T) {
+	fakeCredentialHelper(t)
+
+	store, err := NewCredentialStore("test")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := store.Get("http://openfaas.test"); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want ErrCredentialsNotFound, got: %v", err)
+	}
+
+	want := Credentials{ServerURL: "http://openfaas.test", Username: "basic", Secret: "s3cr3t"}
+	if err := store.Store(want); err != nil {
+		t.Fatalf("unexpected error storing credentials: %s", err)
+	}
+
+	got, err := store.Get(want.ServerURL)
+	if err != nil {
+		t.Fatalf("unexpected error getting credentials: %s", err)
+	}
+	if got != want {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if err := store.Erase(want.ServerURL); err != nil {
+		t.Fatalf("unexpected error erasing credentials: %s", err)
+	}
+	if err := store.Erase(want.ServerURL); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want ErrCredentialsNotFound on second erase, got: %v", err)
+	}
+}
+
+func Test_HelperCredentialStore_MissingHelper(t *testing.T) {
+	store, err := NewCredentialStore("does-not-exist")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := store.Get("http://openfaas.test"); err == nil || errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want an error running a missing helper, got: %v", err)
+	}
+}
+
+func Test_UpdateAuthConfig_WithCredentialStore(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(ConfigLocationEnv, configDir)
+	t.Setenv(CredentialsPassphraseEnv, "correct horse")
+	fakeCredentialHelper(t)
+
+	gatewayURL := "http://openfaas.test"
+	token := EncodeAuth("admin", "some pass")
+
+	err := UpdateAuthConfig(AuthConfig{
+		Gateway:    gatewayURL,
+		Token:      token,
+		Auth:       BasicAuthType,
+		CredsStore: "test",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when updating auth config: %s", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, DefaultFile))
+	if err != nil {
+		t.Fatalf("unable to read config file: %s", err)
+	}
+	if strings.Contains(string(data), token) {
+		t.Fatalf("config file contains the token:\n%s", data)
+	}
+	if !strings.Contains(string(data), "credsStore: test") {
+		t.Fatalf("config file does not name the credential store:\n%s", data)
+	}
+
+	authConfig, err := LookupAuthConfig(gatewayURL)
+	if err != nil {
+		t.Fatalf("unexpected error looking up auth config: %s", err)
+	}
+	if authConfig.Token != token {
+		t.Fatalf("want token %q from the credential store, got %q", token, authConfig.Token)
+	}
+
+	// Moving to another store erases the token from the previous one
+	err = UpdateAuthConfig(AuthConfig{
+		Gateway:    gatewayURL,
+		Token:      token,
+		Auth:       BasicAuthType,
+		CredsStore: FileCredentialStore,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when updating auth config: %s", err)
+	}
+
+	helper, _ := NewCredentialStore("test")
+	if _, err := helper.Get(gatewayURL); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want token erased from the previous store, got: %v", err)
+	}
+
+	authConfig, err = LookupAuthConfig(gatewayURL)
+	if err != nil {
+		t.Fatalf("unexpected error looking up auth config: %s", err)
+	}
+	if authConfig.Token != token {
+		t.Fatalf("want token %q from the file store, got %q", token, authConfig.Token)
+	}
+
+	if err := RemoveAuthConfig(gatewayURL); err != nil {
+		t.Fatalf("unexpected error removing auth config: %s", err)
+	}
+
+	file, _ := NewCredentialStore(FileCredentialStore)
+	if _, err := file.Get(gatewayURL); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want token erased from the file store, got: %v", err)
+	}
+}
+
+func Test_LookupAuthConfig_CredentialStoreError(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(ConfigLocationEnv, configDir)
+	fakeCredentialHelper(t)
+
+	err := UpdateAuthConfig(AuthConfig{
+		Gateway:    "http://openfaas.test",
+		Token:      "token",
+		Auth:       Oauth2AuthType,
+		CredsStore: "test",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when updating auth config: %s", err)
+	}
+
+	// Drop the helper from the PATH
+	t.Setenv("PATH", "")
+
+	_, err = LookupAuthConfig("http://openfaas.test")
+
+	var storeErr *CredentialStoreError
+	if !errors.As(err, &storeErr) {
+		t.Fatalf("want CredentialStoreError, got: %v", err)
+	}
+}
+
+func Test_UpdateAuthConfig_RefreshTokenInCredentialStore(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(ConfigLocationEnv, configDir)
+	fakeCredentialHelper(t)
+
+	gatewayURL := "http://openfaas.test"
+	err := UpdateAuthConfig(AuthConfig{
+		Gateway: gatewayURL,
+		Token:   "gateway-token",
+		Auth:    Oauth2AuthType,
+		Options: []Option{
+			{Name: "client_id", Value: "faas-cli"},
+			{Name: RefreshTokenOption, Value: "refresh-token"},
+		},
+		CredsStore: "test",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when updating auth config: %s", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, DefaultFile))
+	if err != nil {
+		t.Fatalf("unable to read config file: %s", err)
+	}
+	if strings.Contains(string(data), "refresh-token") || !strings.Contains(string(data), "faas-cli") {
+		t.Fatalf("want only the refresh token kept out of the config file:\n%s", data)
+	}
+
+	authConfig, err := LookupAuthConfig(gatewayURL)
+	if err != nil {
+		t.Fatalf("unexpected error looking up auth config: %s", err)
+	}
+	found := false
+	for _, option := range authConfig.Options {
+		found = found || (option.Name == RefreshTokenOption && option.Value == "refresh-token")
+	}
+	if !found {
+		t.Fatalf("want the refresh token from the credential store, got %+v", authConfig.Options)
+	}
+
+	if err := RemoveAuthConfig(gatewayURL); err != nil {
+		t.Fatalf("unexpected error removing auth config: %s", err)
+	}
+
+	helper, _ := NewCredentialStore("test")
+	if _, err := helper.Get(refreshTokenURL(gatewayURL)); !errors.Is(err, ErrCredentialsNotFound) {
+		t.Fatalf("want the refresh token erased, got: %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package proxy
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/openfaas/faas-cli/config"
 )
@@ -34,15 +36,31 @@ func (c *BearerToken) Set(req *http.Request) error {
 	return nil
 }
 
-// NewCLIAuth returns a new CLI Auth
-func NewCLIAuth(token string, gateway string) (ClientAuth, error) {
-	authConfig, _ := config.LookupAuthConfig(gateway)
+// oidcRefreshTimeout bounds each request made to refresh a token.
+const oidcRefreshTimeout = 30 * time.Second
+
+// NewCLIAuth returns a new CLI Auth, tokens are refreshed or exchanged
+// without TLS validation when tlsInsecure is set.
+func NewCLIAuth(token string, gateway string, tlsInsecure bool) (ClientAuth, error) {
+	authConfig, err := config.LookupAuthConfig(gateway)
+
+	// Other errors mean there are no saved credentials for the gateway
+	var storeErr *config.CredentialStoreError
+	if errors.As(err, &storeErr) {
+		if len(token) == 0 {
+			return nil, err
+		}
+
+		// The saved credentials are not needed when a token is given
+		return &BearerToken{
+			token: token,
+		}, nil
+	}
 
 	var (
 		username    string
 		password    string
 		bearerToken string
-		err         error
 	)
 
 	if authConfig.Auth == config.BasicAuthType {
@@ -58,10 +76,24 @@ func NewCLIAuth(token string, gateway string) (ClientAuth, error) {
 
 	}
 
+	timeout := oidcRefreshTimeout
+	tokenClient := MakeHTTPClient(&timeout, tlsInsecure)
+
 	// User specified token gets priority
 	if len(token) > 0 {
 		bearerToken = token
+	} else if authConfig.Auth == config.ClientCredentialsAuthType || authConfig.Auth == config.ServiceAccountAuthType {
+		tokenAuth, err := NewExchangeTokenAuth(authConfig, &tokenClient)
+		if err != nil {
+			return nil, err
+		}
+		return tokenAuth, nil
 	} else {
+		// Tokens from "faas-cli login --oidc" are refreshed once expired
+		authConfig, err = RefreshOIDCAuthConfig(&tokenClient, authConfig)
+		if err != nil {
+			return nil, err
+		}
 		bearerToken = authConfig.Token
 	}
 
@@ -0,0 +1,221 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+	sdk "github.com/openfaas/go-sdk"
+)
+
+// Options saved in the AuthConfig of a gateway after "faas-cli login --oidc",
+// the gateway token itself is saved as the Token. The refresh token is kept
+// in the credential store with the Token when one is used.
+const (
+	OIDCIssuerOption       = "issuer"
+	OIDCClientIDOption     = "client_id"
+	OIDCTokenURLOption     = "token_url"
+	OIDCRefreshTokenOption = config.RefreshTokenOption
+	OIDCExpiryOption       = "expiry"
+)
+
+// gatewayTokenPath is the token exchange endpoint of the gateway.
+const gatewayTokenPath = "/oauth/token"
+
+// OIDCProvider holds the endpoints read from the discovery document of an
+// OIDC issuer.
+type OIDCProvider struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// OIDCToken is a token response from the token endpoint of an issuer.
+type OIDCToken struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// DiscoverOIDC reads the discovery document of the issuer.
+func DiscoverOIDC(client *http.Client, issuer string) (*OIDCProvider, error) {
+	discoveryURL := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+
+	res, err := client.Get(discoveryURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach the issuer %s: %w", issuer, err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d - %s", discoveryURL, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	provider := &OIDCProvider{}
+	if err := json.Unmarshal(body, provider); err != nil {
+		return nil, fmt.Errorf("unable to parse the discovery document of %s: %w", issuer, err)
+	}
+
+	if len(provider.TokenEndpoint) == 0 {
+		return nil, fmt.Errorf("the issuer %s has no token_endpoint", issuer)
+	}
+
+	return provider, nil
+}
+
+// RequestOIDCToken posts a token request to the token endpoint of an issuer,
+// an OAuth error response is returned as an *sdk.OAuthError.
+func RequestOIDCToken(client *http.Client, tokenURL string, values url.Values) (*OIDCToken, error) {
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token URL: %s", tokenURL)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach the token endpoint: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the token response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		oauthErr := &sdk.OAuthError{}
+		if err := json.Unmarshal(body, oauthErr); err == nil && len(oauthErr.Err) > 0 {
+			return nil, oauthErr
+		}
+		return nil, fmt.Errorf("unexpected status code from the token endpoint: %d - %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	token := &OIDCToken{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("unable to parse the token response: %w", err)
+	}
+
+	return token, nil
+}
+
+// ExchangeGatewayToken exchanges an ID token from the issuer for a token
+// issued by the gateway.
+func ExchangeGatewayToken(client *http.Client, gateway, idToken string) (*sdk.Token, error) {
+	if len(idToken) == 0 {
+		return nil, fmt.Errorf("the issuer did not return an ID token, check the scope includes openid")
+	}
+
+	tokenURL := strings.TrimRight(gateway, "/") + gatewayTokenPath
+
+	token, err := sdk.ExchangeIDToken(tokenURL, idToken, sdk.WithHttpClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("unable to exchange the ID token with the gateway: %w", err)
+	}
+	return token, nil
+}
+
+// NewOIDCAuthConfig returns the AuthConfig for a gateway token, along with
+// what is needed to refresh it once it expires.
+func NewOIDCAuthConfig(gateway, issuer, clientID, tokenURL string, idpToken *OIDCToken, gatewayToken *sdk.Token) config.AuthConfig {
+	options := []config.Option{
+		{Name: OIDCIssuerOption, Value: issuer},
+		{Name: OIDCClientIDOption, Value: clientID},
+		{Name: OIDCTokenURLOption, Value: tokenURL},
+	}
+
+	if len(idpToken.RefreshToken) > 0 {
+		options = append(options, config.Option{Name: OIDCRefreshTokenOption, Value: idpToken.RefreshToken})
+	}
+	if !gatewayToken.Expiry.IsZero() {
+		options = append(options, config.Option{Name: OIDCExpiryOption, Value: gatewayToken.Expiry.UTC().Format(time.RFC3339)})
+	}
+
+	return config.AuthConfig{
+		Gateway: gateway,
+		Auth:    config.Oauth2AuthType,
+		Token:   gatewayToken.IDToken,
+		Options: options,
+	}
+}
+
+// RefreshOIDCAuthConfig refreshes an expired gateway token obtained with
+// "faas-cli login --oidc", by exchanging a new ID token from the issuer with
+// the gateway, then saves it. The AuthConfig is returned unchanged when the
+// token has not expired or cannot be refreshed.
+func RefreshOIDCAuthConfig(client *http.Client, authConfig config.AuthConfig) (config.AuthConfig, error) {
+	if authConfig.Auth != config.Oauth2AuthType {
+		return authConfig, nil
+	}
+
+	refreshToken := authOption(authConfig, OIDCRefreshTokenOption)
+	tokenURL := authOption(authConfig, OIDCTokenURLOption)
+	expiry := authOption(authConfig, OIDCExpiryOption)
+	if len(refreshToken) == 0 || len(tokenURL) == 0 || len(expiry) == 0 {
+		return authConfig, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return authConfig, fmt.Errorf("invalid token expiry %q: %w", expiry, err)
+	}
+	if !(&sdk.Token{Expiry: expiresAt}).Expired() {
+		return authConfig, nil
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "refresh_token")
+	values.Set("refresh_token", refreshToken)
+	values.Set("client_id", authOption(authConfig, OIDCClientIDOption))
+
+	idpToken, err := RequestOIDCToken(client, tokenURL, values)
+	if err != nil {
+		return authConfig, fmt.Errorf("unable to refresh the token for %s, run \"faas-cli login --oidc\" again: %w", authConfig.Gateway, err)
+	}
+
+	// Not every issuer rotates the refresh token
+	if len(idpToken.RefreshToken) == 0 {
+		idpToken.RefreshToken = refreshToken
+	}
+
+	gatewayToken, err := ExchangeGatewayToken(client, authConfig.Gateway, idpToken.IDToken)
+	if err != nil {
+		return authConfig, err
+	}
+
+	refreshed := NewOIDCAuthConfig(authConfig.Gateway,
+		authOption(authConfig, OIDCIssuerOption),
+		authOption(authConfig, OIDCClientIDOption),
+		tokenURL,
+		idpToken,
+		gatewayToken)
+	refreshed.CredsStore = authConfig.CredsStore
+
+	if err := config.UpdateAuthConfig(refreshed); err != nil {
+		return authConfig, err
+	}
+
+	return refreshed, nil
+}
+
+func authOption(authConfig config.AuthConfig, name string) string {
+	for _, option := range authConfig.Options {
+		if option.Name == name {
+			return option.Value
+		}
+	}
+	return ""
+}
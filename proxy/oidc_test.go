@@ -0,0 +1,127 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openfaas/faas-cli/config"
+)
+
+func newOIDCRefreshServer(t *testing.T, refreshes *int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(refreshes, 1)
+		if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "idp-access-2",
+			"id_token":      "id-token-2",
+			"refresh_token": "refresh-2",
+			"expires_in":    300,
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("subject_token") != "id-token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "gateway-token-2",
+			"expires_in":   3600,
+		})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func Test_RefreshOIDCAuthConfig(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+
+	var refreshes int32
+	s := newOIDCRefreshServer(t, &refreshes)
+	defer s.Close()
+
+	authConfig := config.AuthConfig{
+		Gateway: s.URL,
+		Auth:    config.Oauth2AuthType,
+		Token:   "gateway-token-1",
+		Options: []config.Option{
+			{Name: OIDCClientIDOption, Value: "faas-cli"},
+			{Name: OIDCTokenURLOption, Value: s.URL + "/token"},
+			{Name: OIDCRefreshTokenOption, Value: "refresh-1"},
+			{Name: OIDCExpiryOption, Value: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+		},
+	}
+
+	unchanged, err := RefreshOIDCAuthConfig(http.DefaultClient, authConfig)
+	if err != nil {
+		t.Fatalf("RefreshOIDCAuthConfig: %v", err)
+	}
+	if unchanged.Token != "gateway-token-1" || atomic.LoadInt32(&refreshes) != 0 {
+		t.Fatalf("want a token which has not expired to be kept, got %s after %d refreshes", unchanged.Token, refreshes)
+	}
+
+	authConfig.Options[3].Value = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if err := config.UpdateAuthConfig(authConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := NewCLIAuth("", s.URL, false)
+	if err != nil {
+		t.Fatalf("NewCLIAuth: %v", err)
+	}
+	if bearer, ok := auth.(*BearerToken); !ok || bearer.token != "gateway-token-2" {
+		t.Fatalf("want the refreshed gateway token, got %#v", auth)
+	}
+
+	saved, err := config.LookupAuthConfig(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Token != "gateway-token-2" || authOption(saved, OIDCRefreshTokenOption) != "refresh-2" {
+		t.Fatalf("want the refreshed tokens saved, got %+v", saved)
+	}
+
+	expiry, err := time.Parse(time.RFC3339, authOption(saved, OIDCExpiryOption))
+	if err != nil || time.Until(expiry) < 30*time.Minute {
+		t.Fatalf("want the expiry of the gateway token, got %s %v", authOption(saved, OIDCExpiryOption), err)
+	}
+}
+
+func Test_RefreshOIDCAuthConfig_invalidRefreshToken(t *testing.T) {
+	var refreshes int32
+	s := newOIDCRefreshServer(t, &refreshes)
+	defer s.Close()
+
+	authConfig := config.AuthConfig{
+		Gateway: s.URL,
+		Auth:    config.Oauth2AuthType,
+		Token:   "gateway-token-1",
+		Options: []config.Option{
+			{Name: OIDCTokenURLOption, Value: s.URL + "/token"},
+			{Name: OIDCRefreshTokenOption, Value: "revoked"},
+			{Name: OIDCExpiryOption, Value: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)},
+		},
+	}
+
+	_, err := RefreshOIDCAuthConfig(http.DefaultClient, authConfig)
+	if err == nil {
+		t.Fatalf("want an error for a revoked refresh token")
+	}
+	if want := "invalid_grant"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("want an error containing %q, got: %v", want, err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openfaas/faas-cli/config"
+	sdk "github.com/openfaas/go-sdk"
+)
+
+// Options saved in the AuthConfig of a gateway by "faas-cli login
+// --client-id" for the client credentials grant. The client secret is read
+// from a file each time, so that it is not copied into the config file.
+const (
+	ClientSecretFileOption = "client_secret_file"
+	ScopeOption            = "scope"
+	AudienceOption         = "audience"
+)
+
+// defaultClientCredentialsScope is requested when no scope is saved.
+const defaultClientCredentialsScope = "openid"
+
+// gatewayTokens caches gateway tokens for the life of the process, so that
+// a command which creates several clients only exchanges a token once.
+var gatewayTokens = sdk.NewMemoryTokenCache()
+
+// ExchangeTokenAuth sets a gateway token obtained by exchanging a token from
+// a TokenSource, such as the client credentials grant or a Kubernetes
+// service account. Tokens are cached until they expire, then exchanged
+// again.
+type ExchangeTokenAuth struct {
+	gateway  string
+	cacheKey string
+	source   sdk.TokenSource
+	cache    sdk.TokenCache
+	client   *http.Client
+}
+
+// NewExchangeTokenAuth returns an ExchangeTokenAuth for an AuthConfig of
+// type client_credentials or service_account, the client is used to call
+// the gateway's token exchange.
+func NewExchangeTokenAuth(authConfig config.AuthConfig, client *http.Client) (*ExchangeTokenAuth, error) {
+	source, err := newTokenSource(authConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExchangeTokenAuth{
+		gateway:  authConfig.Gateway,
+		cacheKey: strings.Join([]string{authConfig.Gateway, string(authConfig.Auth), authOption(authConfig, OIDCClientIDOption)}, "|"),
+		source:   source,
+		cache:    gatewayTokens,
+		client:   client,
+	}, nil
+}
+
+// Set adds the gateway token to the request.
+func (a *ExchangeTokenAuth) Set(req *http.Request) error {
+	token, err := a.Token()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token returns a cached gateway token, or exchanges a new one when there
+// is none or it has expired.
+func (a *ExchangeTokenAuth) Token() (string, error) {
+	if token, ok := a.cache.Get(a.cacheKey); ok {
+		return token.IDToken, nil
+	}
+
+	idToken, err := a.source.Token()
+	if err != nil {
+		return "", fmt.Errorf("unable to get a token for %s: %w", a.gateway, err)
+	}
+
+	token, err := ExchangeGatewayToken(a.client, a.gateway, idToken)
+	if err != nil {
+		return "", err
+	}
+
+	a.cache.Set(a.cacheKey, token)
+	return token.IDToken, nil
+}
+
+func newTokenSource(authConfig config.AuthConfig) (sdk.TokenSource, error) {
+	switch authConfig.Auth {
+	case config.ClientCredentialsAuthType:
+		clientID := authOption(authConfig, OIDCClientIDOption)
+		tokenURL := authOption(authConfig, OIDCTokenURLOption)
+		secretFile := authOption(authConfig, ClientSecretFileOption)
+		if len(clientID) == 0 || len(tokenURL) == 0 || len(secretFile) == 0 {
+			return nil, fmt.Errorf("the client credentials for %s are incomplete, run \"faas-cli login --client-id\" again", authConfig.Gateway)
+		}
+
+		secret, err := os.ReadFile(secretFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client secret: %w", err)
+		}
+
+		scope := authOption(authConfig, ScopeOption)
+		if len(scope) == 0 {
+			scope = defaultClientCredentialsScope
+		}
+
+		return sdk.NewClientCredentialsTokenSource(clientID,
+			strings.TrimSpace(string(secret)),
+			tokenURL,
+			scope,
+			"client_credentials",
+			authOption(authConfig, AudienceOption)), nil
+
+	case config.ServiceAccountAuthType:
+		return &sdk.ServiceAccountTokenSource{}, nil
+
+	default:
+		return nil, fmt.Errorf("auth type %q does not use a token source", authConfig.Auth)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) OpenFaaS Author(s) 2025. All rights reserved.
+// Licensed under the MIT license. See LICENSE file in the project root for full license information.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openfaas/faas-cli/config"
+	sdk "github.com/openfaas/go-sdk"
+)
+
+// newTokenExchangeServer issues an access token for the client credentials
+// grant and exchanges it, or a service account token, for a gateway token.
+func newTokenExchangeServer(t *testing.T, issued, exchanged *int32) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(tokenExchangeHandler(issued, exchanged))
+}
+
+func tokenExchangeHandler(issued, exchanged *int32) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(issued, 1)
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_id") != "ci" || r.FormValue("client_secret") != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "ci-access-token",
+			"expires_in":   300,
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(exchanged, 1)
+		switch r.FormValue("subject_token") {
+		case "ci-access-token", "sa-token":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "gateway-for-" + r.FormValue("subject_token"),
+				"expires_in":   3600,
+			})
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	return mux
+}
+
+func Test_NewCLIAuth_clientCredentials(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+	defer func(cache *sdk.MemoryTokenCache) { gatewayTokens = cache }(gatewayTokens)
+	gatewayTokens = sdk.NewMemoryTokenCache()
+
+	var issued, exchanged int32
+	s := newTokenExchangeServer(t, &issued, &exchanged)
+	defer s.Close()
+
+	secretFile := filepath.Join(t.TempDir(), "client-secret")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.UpdateAuthConfig(config.AuthConfig{
+		Gateway: s.URL,
+		Auth:    config.ClientCredentialsAuthType,
+		Options: []config.Option{
+			{Name: OIDCClientIDOption, Value: "ci"},
+			{Name: OIDCTokenURLOption, Value: s.URL + "/token"},
+			{Name: ClientSecretFileOption, Value: secretFile},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each command may create more than one client
+	for i := 0; i < 2; i++ {
+		auth, err := NewCLIAuth("", s.URL, false)
+		if err != nil {
+			t.Fatalf("NewCLIAuth: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, s.URL+"/system/functions", nil)
+		if err := auth.Set(req); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer gateway-for-ci-access-token" {
+			t.Fatalf("want the exchanged gateway token, got %q", got)
+		}
+	}
+
+	if issued != 1 || exchanged != 1 {
+		t.Fatalf("want one token issued and exchanged, got %d and %d", issued, exchanged)
+	}
+
+	auth, err := NewCLIAuth("user-token", s.URL, false)
+	if err != nil {
+		t.Fatalf("NewCLIAuth: %v", err)
+	}
+	if bearer, ok := auth.(*BearerToken); !ok || bearer.token != "user-token" {
+		t.Fatalf("want --token to take priority, got %#v", auth)
+	}
+}
+
+func Test_NewExchangeTokenAuth_serviceAccount(t *testing.T) {
+	defer func(cache *sdk.MemoryTokenCache) { gatewayTokens = cache }(gatewayTokens)
+	gatewayTokens = sdk.NewMemoryTokenCache()
+
+	var issued, exchanged int32
+	s := newTokenExchangeServer(t, &issued, &exchanged)
+	defer s.Close()
+
+	tokenDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tokenDir, "openfaas-token"), []byte("sa-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("token_mount_path", tokenDir)
+
+	auth, err := NewExchangeTokenAuth(config.AuthConfig{Gateway: s.URL, Auth: config.ServiceAccountAuthType}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewExchangeTokenAuth: %v", err)
+	}
+
+	token, err := auth.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token != "gateway-for-sa-token" {
+		t.Fatalf("want the exchanged gateway token, got %s", token)
+	}
+}
+
+func Test_NewExchangeTokenAuth_missingSecret(t *testing.T) {
+	_, err := NewExchangeTokenAuth(config.AuthConfig{
+		Gateway: "http://127.0.0.1:8080",
+		Auth:    config.ClientCredentialsAuthType,
+		Options: []config.Option{
+			{Name: OIDCClientIDOption, Value: "ci"},
+			{Name: OIDCTokenURLOption, Value: "http://127.0.0.1:8080/token"},
+			{Name: ClientSecretFileOption, Value: filepath.Join(t.TempDir(), "missing")},
+		},
+	}, http.DefaultClient)
+	if err == nil {
+		t.Fatalf("want an error when the client secret file is missing")
+	}
+}
+
+func Test_NewCLIAuth_tlsInsecure(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+	defer func(cache *sdk.MemoryTokenCache) { gatewayTokens = cache }(gatewayTokens)
+	gatewayTokens = sdk.NewMemoryTokenCache()
+
+	var issued, exchanged int32
+	s := httptest.NewTLSServer(tokenExchangeHandler(&issued, &exchanged))
+	defer s.Close()
+
+	tokenDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tokenDir, "openfaas-token"), []byte("sa-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("token_mount_path", tokenDir)
+
+	if err := config.UpdateAuthConfig(config.AuthConfig{Gateway: s.URL, Auth: config.ServiceAccountAuthType}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tlsInsecure := range []bool{false, true} {
+		auth, err := NewCLIAuth("", s.URL, tlsInsecure)
+		if err != nil {
+			t.Fatalf("NewCLIAuth: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, s.URL+"/system/functions", nil)
+		err = auth.Set(req)
+		if tlsInsecure && err != nil {
+			t.Fatalf("want the token exchanged without TLS validation, got: %v", err)
+		}
+		if !tlsInsecure && err == nil {
+			t.Fatalf("want the self-signed certificate to be rejected without --tls-no-verify")
+		}
+	}
+}
+
+func Test_NewCLIAuth_tokenWithUnavailableCredentialStore(t *testing.T) {
+	t.Setenv(config.ConfigLocationEnv, t.TempDir())
+	t.Setenv(config.CredentialsPassphraseEnv, "correct horse")
+
+	gateway := "http://openfaas.test"
+	err := config.UpdateAuthConfig(config.AuthConfig{
+		Gateway:    gateway,
+		Token:      config.EncodeAuth("admin", "some pass"),
+		Auth:       config.BasicAuthType,
+		CredsStore: config.FileCredentialStore,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The passphrase is not set in CI, where a token is given instead
+	t.Setenv(config.CredentialsPassphraseEnv, "")
+
+	if _, err := NewCLIAuth("", gateway, false); err == nil {
+		t.Fatalf("want an error from the credential store without a token")
+	}
+
+	auth, err := NewCLIAuth("ci-token", gateway, false)
+	if err != nil {
+		t.Fatalf("want the token used in place of the credential store, got: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, gateway+"/system/functions", nil)
+	if err := auth.Set(req); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer ci-token" {
+		t.Fatalf("want Bearer ci-token, got %q", got)
+	}
+}
@@ -1,9 +1,11 @@
 package versioncontrol
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	execute "github.com/alexellis/go-execute/v2"
 	"github.com/openfaas/faas-cli/exec"
 )
 
@@ -135,3 +137,45 @@ func GetGitBranch() string {
 	branch = strings.TrimSuffix(branch, "\n")
 	return branch
 }
+
+// GetRemoteSHA returns the commit SHA of a branch or tag of a remote
+// repository without cloning it, or of HEAD when refName is empty.
+func GetRemoteSHA(repository, refName string) (string, error) {
+	if len(refName) == 0 {
+		refName = "HEAD"
+	}
+
+	task := execute.ExecTask{
+		Command: "git",
+		Args:    []string{"ls-remote", repository, refName},
+	}
+
+	res, err := task.Execute(context.Background())
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("git ls-remote %s: %s", repository, strings.TrimSpace(res.Stderr))
+	}
+
+	sha := ""
+	for _, line := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// An annotated tag is listed again, peeled to its commit
+		if strings.HasSuffix(fields[1], "^{}") {
+			return fields[0], nil
+		}
+		if len(sha) == 0 {
+			sha = fields[0]
+		}
+	}
+
+	if len(sha) == 0 {
+		return "", fmt.Errorf("no ref %s found in %s", refName, repository)
+	}
+	return sha, nil
+}